@@ -0,0 +1,97 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+// BlobStore keeps blobs in an S3 bucket. Any S3 compatible storage works,
+// given its endpoint.
+type BlobStore struct {
+	s3      *s3.Client
+	bucket  string
+	baseURL string
+}
+
+// NewBlobStore returns a store that keeps blobs in bucket. With endpoint set,
+// requests go there instead of AWS, using path style addressing. Blobs are
+// expected to be publicly readable at baseURL.
+func NewBlobStore(cfg aws.Config, endpoint, bucket, baseURL string) *BlobStore {
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+
+	return &BlobStore{
+		s3:      client,
+		bucket:  bucket,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (s *BlobStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	const op yeahapi.Op = "aws/BlobStore.Put"
+	_, err := s.s3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+		ContentType:   aws.String(contentType),
+		CacheControl:  aws.String("public, max-age=31536000, immutable"),
+	})
+
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *BlobStore) Get(ctx context.Context, key string) (*yeahapi.Blob, error) {
+	const op yeahapi.Op = "aws/BlobStore.Get"
+	out, err := s.s3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+
+	if err != nil {
+		var notFound *types.NoSuchKey
+		if errors.As(err, &notFound) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound)
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	return &yeahapi.Blob{
+		ContentType: aws.ToString(out.ContentType),
+		Size:        aws.ToInt64(out.ContentLength),
+		Body:        out.Body,
+	}, nil
+}
+
+func (s *BlobStore) Delete(ctx context.Context, key string) error {
+	const op yeahapi.Op = "aws/BlobStore.Delete"
+	_, err := s.s3.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *BlobStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
@@ -1,12 +1,25 @@
 package yeahapi
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 type Category struct {
 	ID          int    `json:"id"`
 	ParentID    *int   `json:"parent_id"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
+	// LifetimeDays is how long listings of the category stay active before
+	// they expire. Zero means the lifetime of the parent category.
+	LifetimeDays int `json:"lifetime_days,omitempty"`
+}
+
+func (c *Category) Ok() error {
+	if c.LifetimeDays < 0 {
+		return E(EInvalid, "Lifetime can't be negative")
+	}
+	return nil
 }
 
 type CategoryReference struct {
@@ -39,3 +52,76 @@ type CategoryService interface {
 	Attributes(ctx context.Context, categoryID string, lang string) ([]*CategoryAttribute, error)
 	CreateCategory(ctx context.Context, category *Category) (*Category, error)
 }
+
+// ValidateAttrs checks sku attrs against the attributes of the listing's
+// category. Options may be referred to by id or by value and are stored by
+// value. It returns the normalized attrs or an EInvalid error with a message
+// per offending attribute.
+func ValidateAttrs(attrs ListingAttrs, schema []*CategoryAttribute) (ListingAttrs, error) {
+	fields := make(Fields)
+	known := make(map[string]*CategoryAttribute, len(schema))
+	for _, attr := range schema {
+		known[attr.Key] = attr
+		if _, ok := attrs[attr.Key]; attr.Required && !ok {
+			fields[attrField(attr.Key)] = fmt.Sprintf("%s is required", attrName(attr))
+		}
+	}
+
+	normalized := make(ListingAttrs, len(attrs))
+	for key, value := range attrs {
+		attr, ok := known[key]
+		if !ok {
+			fields[attrField(key)] = "Unknown attribute"
+			continue
+		}
+
+		if value, ok := attrValue(attr, value); ok {
+			normalized[key] = value
+		} else {
+			fields[attrField(key)] = fmt.Sprintf("Invalid value for %s", attrName(attr))
+		}
+	}
+
+	if len(fields) > 0 {
+		return nil, E(EInvalid, fields, "Some attributes are invalid")
+	}
+
+	return normalized, nil
+}
+
+func attrValue(attr *CategoryAttribute, value interface{}) (interface{}, bool) {
+	// Attributes without options are free-form but must be scalars.
+	if len(attr.Options) == 0 {
+		switch value.(type) {
+		case string, float64, bool:
+			return value, true
+		}
+		return nil, false
+	}
+
+	for _, opt := range attr.Options {
+		switch v := value.(type) {
+		case float64:
+			if int(v) == opt.ID && float64(opt.ID) == v {
+				return opt.Value, true
+			}
+		case string:
+			if v == opt.Value {
+				return opt.Value, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func attrField(key string) string {
+	return "attrs." + key
+}
+
+func attrName(attr *CategoryAttribute) string {
+	if attr.Name != "" {
+		return attr.Name
+	}
+	return attr.Key
+}
@@ -0,0 +1,100 @@
+package cbu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+const DefaultURL = "https://cbu.uz/uz/arkhiv-kursov-valyut/json/"
+
+// RateProvider imports the official exchange rates of the Central Bank of
+// Uzbekistan. Every rate is quoted in UZS.
+type RateProvider struct {
+	url    string
+	client *http.Client
+}
+
+type rate struct {
+	Ccy     string `json:"Ccy"`
+	Nominal string `json:"Nominal"`
+	Rate    string `json:"Rate"`
+	Date    string `json:"Date"`
+}
+
+func NewRateProvider(url string) *RateProvider {
+	return &RateProvider{
+		url:    url,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *RateProvider) Name() string {
+	return "cbu"
+}
+
+func (p *RateProvider) Rates(ctx context.Context) ([]yeahapi.ExchangeRate, error) {
+	const op yeahapi.Op = "cbu/RateProvider.Rates"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, yeahapi.E(op, fmt.Errorf("unexpected status: %s", resp.Status))
+	}
+
+	var rates []rate
+	if err := json.NewDecoder(resp.Body).Decode(&rates); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	result := make([]yeahapi.ExchangeRate, 0, len(rates))
+	for _, r := range rates {
+		er, err := p.exchangeRate(r)
+		if err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		result = append(result, *er)
+	}
+
+	return result, nil
+}
+
+// exchangeRate turns a CBU rate, which is the price of Nominal units of the
+// currency, into the price of a single unit.
+func (p *RateProvider) exchangeRate(r rate) (*yeahapi.ExchangeRate, error) {
+	value, ok := new(big.Rat).SetString(r.Rate)
+	if !ok {
+		return nil, fmt.Errorf("invalid rate for %s: %q", r.Ccy, r.Rate)
+	}
+
+	nominal, ok := new(big.Rat).SetString(r.Nominal)
+	if !ok || nominal.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid nominal for %s: %q", r.Ccy, r.Nominal)
+	}
+
+	date, err := time.Parse("02.01.2006", r.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date for %s: %w", r.Ccy, err)
+	}
+
+	return &yeahapi.ExchangeRate{
+		Base:   yeahapi.Currency(r.Ccy),
+		Quote:  yeahapi.CurrencyUZS,
+		Rate:   value.Quo(value, nominal).FloatString(10),
+		Date:   date,
+		Source: p.Name(),
+	}, nil
+}
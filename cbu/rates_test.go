@@ -0,0 +1,49 @@
+package cbu_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/cbu"
+)
+
+func TestRateProvider_Rates(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "testdata/rates.json")
+		}))
+		defer srv.Close()
+
+		rates, err := cbu.NewRateProvider(srv.URL).Rates(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+		want := []yeahapi.ExchangeRate{
+			{Base: "USD", Quote: yeahapi.CurrencyUZS, Rate: "12345.6700000000", Date: date, Source: "cbu"},
+			{Base: "EUR", Quote: yeahapi.CurrencyUZS, Rate: "13530.4800000000", Date: date, Source: "cbu"},
+			{Base: "JPY", Quote: yeahapi.CurrencyUZS, Rate: "85.1040000000", Date: date, Source: "cbu"},
+		}
+
+		if !reflect.DeepEqual(rates, want) {
+			t.Fatalf("mismatch: %#v != %#v", rates, want)
+		}
+	})
+
+	t.Run("ErrStatus", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		if _, err := cbu.NewRateProvider(srv.URL).Rates(context.Background()); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
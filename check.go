@@ -0,0 +1,317 @@
+package yeahapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// CheckResult is the outcome of a single automated content check. Score is in
+// [0, 1]: zero means the listing looks fine, one means it certainly breaks the
+// rules.
+type CheckResult struct {
+	Check      string  `json:"check"`
+	Score      float64 `json:"score"`
+	ReasonCode string  `json:"reason_code"`
+	Detail     string  `json:"detail"`
+}
+
+// ListingCheck inspects a listing before it reaches a human moderator. Checks
+// returning a nil result are treated as passed.
+type ListingCheck interface {
+	Name() string
+	Check(ctx context.Context, listing *Listing, skus []ListingSku) (*CheckResult, error)
+}
+
+// ContentChecker runs registered checks on listings submitted for moderation.
+// Listings scoring below ApproveBelow are approved, listings scoring at or
+// above RejectAt are rejected and everything in between is queued for a
+// moderator together with its score.
+type ContentChecker struct {
+	ApproveBelow float64
+	RejectAt     float64
+
+	checks            []ListingCheck
+	textChecks        []ListingCheck
+	photoChecks       []ListingCheck
+	listingService    ListingService
+	moderationService ModerationService
+}
+
+func NewContentChecker(listingService ListingService, moderationService ModerationService) *ContentChecker {
+	return &ContentChecker{
+		ApproveBelow:      0.2,
+		RejectAt:          0.9,
+		listingService:    listingService,
+		moderationService: moderationService,
+	}
+}
+
+func (c *ContentChecker) Register(checks ...ListingCheck) {
+	c.checks = append(c.checks, checks...)
+}
+
+// RegisterText registers checks that only look at the title and description.
+// They run on every translation of the listing as well.
+func (c *ContentChecker) RegisterText(checks ...ListingCheck) {
+	c.textChecks = append(c.textChecks, checks...)
+}
+
+// RegisterPhoto registers checks that look at the photos of the listing.
+// Photos are hashed after they are uploaded, so these run again whenever a
+// photo of a submitted listing is processed.
+func (c *ContentChecker) RegisterPhoto(checks ...ListingCheck) {
+	c.photoChecks = append(c.photoChecks, checks...)
+}
+
+// Run applies every registered check to the listing and returns the results
+// of the checks that found something. Text checks report the worst of the
+// listing and its translations.
+func (c *ContentChecker) Run(ctx context.Context, listing *Listing, skus []ListingSku, translations []ListingTranslation) ([]CheckResult, error) {
+	const op Op = "ContentChecker.Run"
+	results, err := runChecks(ctx, c.checks, listing, skus)
+	if err != nil {
+		return nil, E(op, err)
+	}
+
+	photoResults, err := runChecks(ctx, c.photoChecks, listing, skus)
+	if err != nil {
+		return nil, E(op, err)
+	}
+	results = append(results, photoResults...)
+
+	for _, check := range c.textChecks {
+		worst, err := check.Check(ctx, listing, skus)
+		if err != nil {
+			return nil, E(op, err)
+		}
+
+		for _, tr := range translations {
+			translated := *listing
+			translated.Title, translated.Description, translated.Lang = tr.Title, tr.Description, tr.Lang
+			result, err := check.Check(ctx, &translated, skus)
+			if err != nil {
+				return nil, E(op, err)
+			}
+
+			if result != nil && (worst == nil || result.Score > worst.Score) {
+				result.Detail = fmt.Sprintf("%s (%s translation)", result.Detail, tr.Lang)
+				worst = result
+			}
+		}
+
+		if worst == nil || worst.Score <= 0 {
+			continue
+		}
+
+		worst.Check = check.Name()
+		results = append(results, *worst)
+	}
+
+	return results, nil
+}
+
+func runChecks(ctx context.Context, checks []ListingCheck, listing *Listing, skus []ListingSku) ([]CheckResult, error) {
+	results := make([]CheckResult, 0)
+	for _, check := range checks {
+		result, err := check.Check(ctx, listing, skus)
+		if err != nil {
+			return nil, err
+		}
+
+		if result == nil || result.Score <= 0 {
+			continue
+		}
+
+		result.Check = check.Name()
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+// Verdict turns check results into a decision. It returns nil when the
+// listing has to be reviewed by a moderator, along with the highest score.
+func (c *ContentChecker) Verdict(results []CheckResult) (*ModerationDecision, float64) {
+	var worst *CheckResult
+	for i := range results {
+		if worst == nil || results[i].Score > worst.Score {
+			worst = &results[i]
+		}
+	}
+
+	if worst == nil || worst.Score < c.ApproveBelow {
+		return &ModerationDecision{Automated: true, Kind: ModerationApproved}, 0
+	}
+
+	if worst.Score >= c.RejectAt {
+		return &ModerationDecision{
+			Automated:  true,
+			Kind:       ModerationRejected,
+			ReasonCode: worst.ReasonCode,
+			Comment:    worst.Detail,
+		}, worst.Score
+	}
+
+	return nil, worst.Score
+}
+
+// ListingSubmitted checks a listing submitted for moderation and either
+// decides on it or puts it into the moderation queue. Reported listings are
+// only ever rejected automatically.
+func (c *ContentChecker) ListingSubmitted(m jetstream.Msg) error {
+	const op Op = "ContentChecker.ListingSubmitted"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	var event ListingStatusChangedEvent
+	if err := json.Unmarshal(m.Data(), &event); err != nil {
+		return E(op, err)
+	}
+
+	listing, err := c.listingService.Listing(ctx, event.ListingID)
+	if err != nil {
+		if EIs(ENotFound, err) {
+			return nil
+		}
+		return E(op, err)
+	}
+
+	if listing.Status != ListingStatusModeration {
+		return nil
+	}
+
+	skus, err := c.listingService.Skus(ctx, listing.ID)
+	if err != nil {
+		return E(op, err)
+	}
+
+	translations, err := c.listingService.Translations(ctx, listing.ID)
+	if err != nil {
+		return E(op, err)
+	}
+
+	results, err := c.Run(ctx, listing, skus, translations)
+	if err != nil {
+		return E(op, err)
+	}
+
+	if err := c.moderationService.SaveCheckResults(ctx, listing.ID, results); err != nil {
+		return E(op, err)
+	}
+
+	// Listings that were active before were sent back by user reports or
+	// edited after approval, which checks passing doesn't settle, so a
+	// moderator always reviews them.
+	decision, score := c.Verdict(results)
+	if decision != nil && decision.Kind == ModerationApproved && event.From == ListingStatusActive {
+		decision = nil
+	}
+
+	if decision == nil {
+		if err := c.moderationService.Enqueue(ctx, listing.ID, score); err != nil {
+			return E(op, err)
+		}
+		return nil
+	}
+
+	decision.ListingID = listing.ID
+	if decision, err = c.moderationService.Decide(ctx, decision); err != nil {
+		if EIs(EConflict, err) {
+			return nil
+		}
+		return E(op, err)
+	}
+
+	return nil
+}
+
+// MediaProcessed runs the photo checks again once a photo of a submitted
+// listing is hashed. Approved listings that fail them go back to moderation,
+// listings under review are put in front of a moderator along with the
+// results.
+func (c *ContentChecker) MediaProcessed(m jetstream.Msg) error {
+	const op Op = "ContentChecker.MediaProcessed"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	var event MediaProcessedEvent
+	if err := json.Unmarshal(m.Data(), &event); err != nil {
+		return E(op, err)
+	}
+
+	listing, err := c.listingService.Listing(ctx, event.ListingID)
+	if err != nil {
+		if EIs(ENotFound, err) {
+			return nil
+		}
+		return E(op, err)
+	}
+
+	switch listing.Status {
+	case ListingStatusDraft, ListingStatusDeleted:
+		return nil
+	case ListingStatusIndexing:
+		// Indexing listings can only be published, the message is redelivered
+		// until they are.
+		return E(op, EConflict, "Listing is being indexed")
+	}
+
+	skus, err := c.listingService.Skus(ctx, listing.ID)
+	if err != nil {
+		return E(op, err)
+	}
+
+	results, err := runChecks(ctx, c.photoChecks, listing, skus)
+	if err != nil {
+		return E(op, err)
+	}
+
+	if decision, _ := c.Verdict(results); decision != nil && decision.Kind == ModerationApproved {
+		return nil
+	}
+
+	if listing.Status != ListingStatusModeration {
+		if _, err := c.listingService.UpdateStatus(ctx, listing.ID, listing.Status, ListingStatusModeration); err != nil {
+			if EIs(EConflict, err) {
+				return nil
+			}
+			return E(op, err)
+		}
+		return nil
+	}
+
+	saved, err := c.moderationService.CheckResults(ctx, listing.ID)
+	if err != nil {
+		return E(op, err)
+	}
+
+	for _, result := range saved {
+		if !hasCheck(results, result.Check) {
+			results = append(results, result)
+		}
+	}
+
+	if err := c.moderationService.SaveCheckResults(ctx, listing.ID, results); err != nil {
+		return E(op, err)
+	}
+
+	_, score := c.Verdict(results)
+	if err := c.moderationService.Enqueue(ctx, listing.ID, score); err != nil {
+		return E(op, err)
+	}
+
+	return nil
+}
+
+func hasCheck(results []CheckResult, name string) bool {
+	for _, result := range results {
+		if result.Check == name {
+			return true
+		}
+	}
+	return false
+}
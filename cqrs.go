@@ -2,7 +2,9 @@ package yeahapi
 
 import (
 	"context"
+	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/nats-io/nats.go/jetstream"
 )
 
@@ -13,6 +15,50 @@ const (
 	phoneCodeSent = "auth.phoneCodeSent"
 )
 
+const (
+	ListingDrafted             = "listings.drafted"
+	ListingModerationSubmitted = "listings.moderationSubmitted"
+	ListingIndexingStarted     = "listings.indexingStarted"
+	ListingPublished           = "listings.published"
+	ListingArchived            = "listings.archived"
+	ListingDeleted             = "listings.deleted"
+	ListingPriceDropped        = "listings.priceDropped"
+	ListingStockLow            = "listings.stockLow"
+	ListingSoldOut             = "listings.soldOut"
+	ListingExpiring            = "listings.expiring"
+)
+
+const (
+	ListingRejected = "moderation.listingRejected"
+)
+
+const (
+	MediaUploaded  = "media.uploaded"
+	MediaProcessed = "media.processed"
+)
+
+const (
+	FavoriteAlerted = "favorites.alerted"
+)
+
+const (
+	SavedSearchMatched = "savedSearches.matched"
+)
+
+const (
+	ReportCreated  = "reports.created"
+	ReportResolved = "reports.resolved"
+)
+
+var listingStatusSubjects = map[ListingStatus]string{
+	ListingStatusDraft:      ListingDrafted,
+	ListingStatusModeration: ListingModerationSubmitted,
+	ListingStatusIndexing:   ListingIndexingStarted,
+	ListingStatusActive:     ListingPublished,
+	ListingStatusArchived:   ListingArchived,
+	ListingStatusDeleted:    ListingDeleted,
+}
+
 type CQRSConfig struct {
 	NatsURL       string
 	NatsAuthToken string
@@ -61,6 +107,96 @@ type SendEmailCodeCmd struct {
 	Code  string `json:"code"`
 }
 
+type ListingStatusChangedEvent struct {
+	subject
+	ListingID uuid.UUID     `json:"listing_id"`
+	OwnerID   UserID        `json:"owner_id"`
+	From      ListingStatus `json:"from"`
+	To        ListingStatus `json:"to"`
+}
+
+type ListingExpiringEvent struct {
+	subject
+	ListingID uuid.UUID `json:"listing_id"`
+	OwnerID   UserID    `json:"owner_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ListingRejectedEvent struct {
+	subject
+	ListingID  uuid.UUID `json:"listing_id"`
+	OwnerID    UserID    `json:"owner_id"`
+	ReasonCode string    `json:"reason_code"`
+	Comment    string    `json:"comment"`
+}
+
+type ListingPriceDroppedEvent struct {
+	subject
+	ListingID      uuid.UUID `json:"listing_id"`
+	SkuID          uuid.UUID `json:"sku_id"`
+	Amount         int       `json:"amount"`
+	PreviousAmount int       `json:"previous_amount"`
+	Currency       Currency  `json:"currency"`
+}
+
+type ListingStockLowEvent struct {
+	subject
+	ListingID uuid.UUID `json:"listing_id"`
+	SkuID     uuid.UUID `json:"sku_id"`
+	Quantity  int       `json:"quantity"`
+}
+
+type ListingSoldOutEvent struct {
+	subject
+	ListingID uuid.UUID `json:"listing_id"`
+}
+
+type MediaUploadedEvent struct {
+	subject
+	MediaID   uuid.UUID `json:"media_id"`
+	ListingID uuid.UUID `json:"listing_id"`
+}
+
+// MediaProcessedEvent is published once a photo of a submitted listing is
+// hashed, so that it can be checked for copies.
+type MediaProcessedEvent struct {
+	subject
+	MediaID   uuid.UUID `json:"media_id"`
+	ListingID uuid.UUID `json:"listing_id"`
+}
+
+type FavoriteAlertEvent struct {
+	subject
+	UserID         UserID           `json:"user_id"`
+	ListingID      uuid.UUID        `json:"listing_id"`
+	Kind           NotificationKind `json:"kind"`
+	Amount         int              `json:"amount,omitempty"`
+	PreviousAmount int              `json:"previous_amount,omitempty"`
+	Currency       Currency         `json:"currency,omitempty"`
+}
+
+type SavedSearchMatchedEvent struct {
+	subject
+	UserID  UserID             `json:"user_id"`
+	Matches []SavedSearchMatch `json:"matches"`
+}
+
+type ReportCreatedEvent struct {
+	subject
+	ReportID   uuid.UUID        `json:"report_id"`
+	TargetKind ReportTargetKind `json:"target_kind"`
+	TargetID   uuid.UUID        `json:"target_id"`
+}
+
+type ReportResolvedEvent struct {
+	subject
+	ReportID   uuid.UUID        `json:"report_id"`
+	ReporterID UserID           `json:"reporter_id"`
+	TargetKind ReportTargetKind `json:"target_kind"`
+	TargetID   uuid.UUID        `json:"target_id"`
+	Outcome    ReportOutcome    `json:"outcome"`
+}
+
 func NewSendPhoneCodeCmd(phoneNumber string, code string) SendPhoneCodeCmd {
 	return SendPhoneCodeCmd{
 		subject:     subject{sendPhoneCode},
@@ -90,3 +226,121 @@ func NewPhoneCodeSentEvent(phoneNumber string) PhoneCodeSentEvent {
 		PhoneNumber: phoneNumber,
 	}
 }
+
+// NewListingStatusChangedEvent is published under a subject specific to the
+// status the listing moved to, so consumers can subscribe to the transitions
+// they care about.
+func NewListingStatusChangedEvent(listing *Listing, from ListingStatus) ListingStatusChangedEvent {
+	return ListingStatusChangedEvent{
+		subject:   subject{listingStatusSubjects[listing.Status]},
+		ListingID: listing.ID,
+		OwnerID:   listing.OwnerID,
+		From:      from,
+		To:        listing.Status,
+	}
+}
+
+func NewListingExpiringEvent(listing *Listing) ListingExpiringEvent {
+	event := ListingExpiringEvent{
+		subject:   subject{ListingExpiring},
+		ListingID: listing.ID,
+		OwnerID:   listing.OwnerID,
+	}
+	if listing.ExpiresAt != nil {
+		event.ExpiresAt = *listing.ExpiresAt
+	}
+	return event
+}
+
+func NewListingRejectedEvent(listing *Listing, decision *ModerationDecision) ListingRejectedEvent {
+	return ListingRejectedEvent{
+		subject:    subject{ListingRejected},
+		ListingID:  listing.ID,
+		OwnerID:    listing.OwnerID,
+		ReasonCode: decision.ReasonCode,
+		Comment:    decision.Comment,
+	}
+}
+
+func NewListingPriceDroppedEvent(drop PriceDrop) ListingPriceDroppedEvent {
+	return ListingPriceDroppedEvent{
+		subject:        subject{ListingPriceDropped},
+		ListingID:      drop.ListingID,
+		SkuID:          drop.SkuID,
+		Amount:         drop.Amount,
+		PreviousAmount: drop.PreviousAmount,
+		Currency:       drop.Currency,
+	}
+}
+
+func NewListingStockLowEvent(adjustment *StockAdjustment) ListingStockLowEvent {
+	return ListingStockLowEvent{
+		subject:   subject{ListingStockLow},
+		ListingID: adjustment.ListingID,
+		SkuID:     adjustment.SkuID,
+		Quantity:  adjustment.Quantity,
+	}
+}
+
+func NewListingSoldOutEvent(adjustment *StockAdjustment) ListingSoldOutEvent {
+	return ListingSoldOutEvent{
+		subject:   subject{ListingSoldOut},
+		ListingID: adjustment.ListingID,
+	}
+}
+
+func NewMediaUploadedEvent(media *Media) MediaUploadedEvent {
+	return MediaUploadedEvent{
+		subject:   subject{MediaUploaded},
+		MediaID:   media.ID,
+		ListingID: media.ListingID,
+	}
+}
+
+func NewMediaProcessedEvent(media *Media) MediaProcessedEvent {
+	return MediaProcessedEvent{
+		subject:   subject{MediaProcessed},
+		MediaID:   media.ID,
+		ListingID: media.ListingID,
+	}
+}
+
+func NewFavoriteAlertEvent(alert FavoriteAlert) FavoriteAlertEvent {
+	return FavoriteAlertEvent{
+		subject:        subject{FavoriteAlerted},
+		UserID:         alert.UserID,
+		ListingID:      alert.ListingID,
+		Kind:           alert.Kind,
+		Amount:         alert.Amount,
+		PreviousAmount: alert.PreviousAmount,
+		Currency:       alert.Currency,
+	}
+}
+
+func NewSavedSearchMatchedEvent(digest SavedSearchDigest) SavedSearchMatchedEvent {
+	return SavedSearchMatchedEvent{
+		subject: subject{SavedSearchMatched},
+		UserID:  digest.UserID,
+		Matches: digest.Matches,
+	}
+}
+
+func NewReportCreatedEvent(report *Report) ReportCreatedEvent {
+	return ReportCreatedEvent{
+		subject:    subject{ReportCreated},
+		ReportID:   report.ID,
+		TargetKind: report.TargetKind,
+		TargetID:   report.TargetID,
+	}
+}
+
+func NewReportResolvedEvent(report *Report) ReportResolvedEvent {
+	return ReportResolvedEvent{
+		subject:    subject{ReportResolved},
+		ReportID:   report.ID,
+		ReporterID: report.ReporterID,
+		TargetKind: report.TargetKind,
+		TargetID:   report.TargetID,
+		Outcome:    report.Outcome,
+	}
+}
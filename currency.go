@@ -0,0 +1,132 @@
+package yeahapi
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+type CurrencyInfo struct {
+	Code       Currency `json:"code"`
+	Symbol     string   `json:"symbol"`
+	MinorUnits int      `json:"minor_units"`
+}
+
+// ExchangeRate says one unit of Base costs Rate units of Quote. Rate is a
+// decimal string so that no precision is lost on the way from the provider.
+type ExchangeRate struct {
+	Base   Currency  `json:"base"`
+	Quote  Currency  `json:"quote"`
+	Rate   string    `json:"rate"`
+	Date   time.Time `json:"date"`
+	Source string    `json:"source"`
+}
+
+type CurrencyService interface {
+	Currencies(ctx context.Context) ([]CurrencyInfo, error)
+	// ExchangeRate returns the latest rate between two currencies, derived
+	// from the inverse or a cross rate when there is no direct one.
+	ExchangeRate(ctx context.Context, base, quote Currency) (*ExchangeRate, error)
+	// SaveRates stores rates between known currencies and returns how many
+	// were stored. Rates of unknown currencies are skipped.
+	SaveRates(ctx context.Context, rates []ExchangeRate) (int, error)
+}
+
+// RateProvider fetches current exchange rates from an outside source.
+type RateProvider interface {
+	Name() string
+	Rates(ctx context.Context) ([]ExchangeRate, error)
+}
+
+// ConvertAmount converts an amount in minor units of from into minor units of
+// to. The arithmetic is exact and the result is rounded half away from zero
+// once, at the end, the same way the convert_amount database function does.
+func ConvertAmount(amount int, rate *ExchangeRate, from, to *CurrencyInfo) (int, error) {
+	const op Op = "ConvertAmount"
+	if rate.Base != from.Code || rate.Quote != to.Code {
+		return 0, E(op, EInvalid, fmt.Sprintf("Can't convert %s to %s with a %s/%s rate", from.Code, to.Code, rate.Base, rate.Quote))
+	}
+
+	r, ok := new(big.Rat).SetString(rate.Rate)
+	if !ok {
+		return 0, E(op, EInvalid, fmt.Sprintf("Invalid exchange rate: %s", rate.Rate))
+	}
+
+	x := new(big.Rat).SetInt64(int64(amount))
+	x.Mul(x, r)
+
+	exp := to.MinorUnits - from.MinorUnits
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp))), nil))
+	if exp >= 0 {
+		x.Mul(x, scale)
+	} else {
+		x.Quo(x, scale)
+	}
+
+	q, m := new(big.Int).QuoRem(x.Num(), x.Denom(), new(big.Int))
+	if m.Mul(m.Abs(m), big.NewInt(2)).Cmp(x.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(x.Sign())))
+	}
+
+	if !q.IsInt64() {
+		return 0, E(op, EInvalid, "Converted amount is too large")
+	}
+
+	return int(q.Int64()), nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// RateImporter periodically stores rates fetched from a provider.
+type RateImporter struct {
+	Interval time.Duration
+
+	provider        RateProvider
+	currencyService CurrencyService
+}
+
+func NewRateImporter(provider RateProvider, currencyService CurrencyService) *RateImporter {
+	return &RateImporter{
+		Interval:        6 * time.Hour,
+		provider:        provider,
+		currencyService: currencyService,
+	}
+}
+
+// Run imports rates right away and then every Interval until ctx is done.
+func (i *RateImporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(i.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := i.Import(ctx); err != nil {
+			fmt.Println(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (i *RateImporter) Import(ctx context.Context) error {
+	const op Op = "RateImporter.Import"
+	rates, err := i.provider.Rates(ctx)
+	if err != nil {
+		return E(op, err)
+	}
+
+	if _, err := i.currencyService.SaveRates(ctx, rates); err != nil {
+		return E(op, err)
+	}
+
+	return nil
+}
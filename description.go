@@ -0,0 +1,145 @@
+package yeahapi
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// MaxDescriptionLength is how many characters of text a listing description
+// may have, markup aside.
+const MaxDescriptionLength = 5000
+
+// descriptionTags are the tags a description may be formatted with. They are
+// kept without attributes. Links aren't allowed, sellers are to be contacted
+// through the platform and hrefs would hide contacts from content checks.
+var descriptionTags = map[string]bool{
+	"p": true, "br": true, "b": true, "strong": true, "i": true, "em": true, "u": true, "s": true,
+	"ul": true, "ol": true, "li": true, "h3": true, "h4": true, "blockquote": true,
+}
+
+// droppedTags are removed along with everything inside of them.
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true, "noscript": true,
+	"template": true, "textarea": true, "select": true, "svg": true, "math": true, "head": true, "title": true,
+}
+
+// blockTags separate words when a description is turned into text.
+var blockTags = map[string]bool{
+	"p": true, "br": true, "li": true, "h3": true, "h4": true, "blockquote": true, "div": true,
+}
+
+// SanitizeDescription keeps the basic formatting of a rich text description
+// and drops everything else. Tags that aren't allowed, links among them, are
+// unwrapped. Unclosed tags are closed.
+func SanitizeDescription(s string) string {
+	var b strings.Builder
+	var open []string
+	skip := 0
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		tok := z.Token()
+		switch tt {
+		case html.TextToken:
+			if skip == 0 {
+				b.WriteString(html.EscapeString(tok.Data))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedTags[tok.Data] {
+				if tt == html.StartTagToken {
+					skip++
+				}
+				continue
+			}
+			if skip > 0 || !descriptionTags[tok.Data] {
+				continue
+			}
+			if tok.Data == "br" {
+				b.WriteString("<br>")
+				continue
+			}
+			b.WriteString("<" + tok.Data + ">")
+			if tt == html.StartTagToken {
+				open = append(open, tok.Data)
+			} else {
+				b.WriteString("</" + tok.Data + ">")
+			}
+		case html.EndTagToken:
+			if droppedTags[tok.Data] {
+				if skip > 0 {
+					skip--
+				}
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			// Close tags opened inside of this one first, stray end tags
+			// are dropped.
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == tok.Data {
+					for _, tag := range reverse(open[i:]) {
+						b.WriteString("</" + tag + ">")
+					}
+					open = open[:i]
+					break
+				}
+			}
+		}
+	}
+
+	for _, tag := range reverse(open) {
+		b.WriteString("</" + tag + ">")
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+func reverse(tags []string) []string {
+	reversed := make([]string, len(tags))
+	for i, tag := range tags {
+		reversed[len(tags)-1-i] = tag
+	}
+	return reversed
+}
+
+// DescriptionText returns the text of a description without markup, with
+// whitespace collapsed.
+func DescriptionText(s string) string {
+	var b strings.Builder
+	skip := 0
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		tok := z.Token()
+		switch tt {
+		case html.TextToken:
+			if skip == 0 {
+				b.WriteString(tok.Data)
+			}
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			if droppedTags[tok.Data] && tt != html.SelfClosingTagToken {
+				if tt == html.StartTagToken {
+					skip++
+				} else if skip > 0 {
+					skip--
+				}
+			} else if blockTags[tok.Data] {
+				b.WriteString(" ")
+			}
+		}
+	}
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,124 @@
+package disk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+// BlobStore keeps blobs as files under a root directory.
+type BlobStore struct {
+	root    string
+	baseURL string
+}
+
+// NewBlobStore returns a store that keeps blobs under root. Blobs are served
+// from baseURL, see Server's /blobs/ route.
+func NewBlobStore(root, baseURL string) *BlobStore {
+	return &BlobStore{
+		root:    root,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (s *BlobStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	const op yeahapi.Op = "disk/BlobStore.Put"
+	name, err := s.path(key)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	// Write to a temporary file first so that readers never see a partial
+	// blob.
+	f, err := os.CreateTemp(filepath.Dir(name), ".blob-*")
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		return yeahapi.E(op, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	if err := os.Rename(f.Name(), name); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *BlobStore) Get(ctx context.Context, key string) (*yeahapi.Blob, error) {
+	const op yeahapi.Op = "disk/BlobStore.Get"
+	name, err := s.path(key)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound)
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, yeahapi.E(op, err)
+	}
+
+	return &yeahapi.Blob{
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		Size:        info.Size(),
+		Body:        f,
+	}, nil
+}
+
+func (s *BlobStore) Delete(ctx context.Context, key string) error {
+	const op yeahapi.Op = "disk/BlobStore.Delete"
+	name, err := s.path(key)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *BlobStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// path maps a key to a file under root. Keys can't point outside of it.
+func (s *BlobStore) path(key string) (string, error) {
+	if key == "" || !fs.ValidPath(key) {
+		return "", yeahapi.E(yeahapi.EInvalid, "Invalid blob key")
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
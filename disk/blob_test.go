@@ -0,0 +1,60 @@
+package disk_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/disk"
+)
+
+func TestBlobStore(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		s := disk.NewBlobStore(t.TempDir(), "http://localhost/blobs/")
+		key := "listings/1/photo.jpg"
+
+		if err := s.Put(ctx, key, strings.NewReader("photo"), 5, "image/jpeg"); err != nil {
+			t.Fatal(err)
+		}
+
+		blob, err := s.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer blob.Body.Close()
+		b, err := io.ReadAll(blob.Body)
+		if err != nil {
+			t.Fatal(err)
+		} else if string(b) != "photo" || blob.Size != 5 || blob.ContentType != "image/jpeg" {
+			t.Fatalf("unexpected blob: %q %d %s", b, blob.Size, blob.ContentType)
+		}
+
+		if got, want := s.URL(key), "http://localhost/blobs/listings/1/photo.jpg"; got != want {
+			t.Fatalf("mismatch: %s != %s", got, want)
+		}
+
+		if err := s.Delete(ctx, key); err != nil {
+			t.Fatal(err)
+		} else if _, err := s.Get(ctx, key); !yeahapi.EIs(yeahapi.ENotFound, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		// Deleting twice is fine.
+		if err := s.Delete(ctx, key); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("ErrInvalidKey", func(t *testing.T) {
+		s := disk.NewBlobStore(t.TempDir(), "")
+		for _, key := range []string{"", "../secret", "/etc/passwd", "a/../../b"} {
+			if err := s.Put(context.Background(), key, strings.NewReader(""), 0, ""); !yeahapi.EIs(yeahapi.EInvalid, err) {
+				t.Fatalf("%q: unexpected error: %#v", key, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package yeahapi
+
+import (
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxTitleDistance is how many bits the simhashes of two titles may
+	// differ in for the titles to count as near duplicates.
+	MaxTitleDistance = 6
+	// MaxPhotoDistance is how many bits the perceptual hashes of two photos
+	// may differ in for the photos to count as copies of each other.
+	MaxPhotoDistance = 8
+)
+
+// TitleSimhash hashes a title so that titles differing in case, punctuation
+// or a few characters hash to values a few bits apart. It is a
+// simhash of the character trigrams of the normalized title. Hashes are
+// signed to fit into bigint columns.
+func TitleSimhash(title string) int64 {
+	var weights [64]int
+	for _, shingle := range titleShingles(title) {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		sum := h.Sum64()
+		for i := range weights {
+			if sum&(1<<i) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	var hash uint64
+	for i, w := range weights {
+		if w > 0 {
+			hash |= 1 << i
+		}
+	}
+	return int64(hash)
+}
+
+// titleShingles returns the character trigrams of the title with everything
+// but letters and digits dropped and words separated by single spaces.
+func titleShingles(title string) []string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	runes := []rune(" " + strings.Join(words, " ") + " ")
+	if len(runes) <= 3 {
+		return []string{string(runes)}
+	}
+
+	shingles := make([]string, 0, len(runes)-2)
+	for i := 0; i+3 <= len(runes); i++ {
+		shingles = append(shingles, string(runes[i:i+3]))
+	}
+	return shingles
+}
@@ -15,10 +15,15 @@ type Error struct {
 	Kind     Kind
 	Err      error
 	Message  string
+	Fields   Fields
 	UserID   UserID
 	ClientID ClientID
 }
 
+// Fields maps input names to messages about what's wrong with them, so
+// clients can show errors next to form inputs.
+type Fields map[string]string
+
 const Separator = ":\n\t"
 
 const (
@@ -33,6 +38,8 @@ const (
 	EOtpCodeExpired
 	EOtpHashNotMatched
 	EInternal
+	EConflict
+	ETooLarge
 )
 
 func (k Kind) String() string {
@@ -59,6 +66,10 @@ func (k Kind) String() string {
 		return "otp hash not matched"
 	case EPermission:
 		return "permission denied"
+	case EConflict:
+		return "conflicting update"
+	case ETooLarge:
+		return "too large"
 	}
 	return "unknown error"
 }
@@ -110,6 +121,17 @@ func ErrorMessage(err error) string {
 	return ""
 }
 
+func ErrorFields(err error) Fields {
+	if err == nil {
+		return nil
+	} else if e, ok := err.(*Error); ok && len(e.Fields) > 0 {
+		return e.Fields
+	} else if ok && e.Err != nil {
+		return ErrorFields(e.Err)
+	}
+	return nil
+}
+
 func ErrorKind(err error) Kind {
 	if err == nil {
 		return EOther
@@ -140,6 +162,8 @@ func E(args ...interface{}) error {
 			e.Err = arg
 		case Kind:
 			e.Kind = arg
+		case Fields:
+			e.Fields = arg
 		case string:
 			e.Message = arg
 			// e.Err = Str(arg)
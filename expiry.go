@@ -0,0 +1,73 @@
+package yeahapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// ExpiryWarning is how long before their listings expire owners are warned.
+const ExpiryWarning = 3 * 24 * time.Hour
+
+type ExpiryService interface {
+	// ExpireListings archives the active listings that expired by now. A
+	// ListingStatusChangedEvent of each is published once they commit.
+	ExpireListings(ctx context.Context, now time.Time) ([]Listing, error)
+	// WarnListings returns the active listings expiring by before whose owners
+	// weren't warned yet, and marks them as warned. A ListingExpiringEvent of
+	// each is published once they commit.
+	WarnListings(ctx context.Context, before time.Time) ([]Listing, error)
+	// RenewListing restarts the lifetime of an active listing.
+	RenewListing(ctx context.Context, id uuid.UUID) (*Listing, error)
+}
+
+// ExpiryScheduler periodically archives expired listings and warns owners of
+// listings about to expire. Only one replica does it at a time.
+type ExpiryScheduler struct {
+	Interval time.Duration
+
+	expiryService ExpiryService
+	locker        Locker
+}
+
+func NewExpiryScheduler(expiryService ExpiryService, locker Locker) *ExpiryScheduler {
+	return &ExpiryScheduler{
+		Interval:      5 * time.Minute,
+		expiryService: expiryService,
+		locker:        locker,
+	}
+}
+
+// Run blocks until ctx is done.
+func (s *ExpiryScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.locker.TryLock(ctx, "listings.expiry", s.expire); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+func (s *ExpiryScheduler) expire(ctx context.Context) error {
+	const op Op = "ExpiryScheduler.expire"
+	now := time.Now()
+
+	if _, err := s.expiryService.ExpireListings(ctx, now); err != nil {
+		return E(op, err)
+	}
+
+	if _, err := s.expiryService.WarnListings(ctx, now.Add(ExpiryWarning)); err != nil {
+		return E(op, err)
+	}
+
+	return nil
+}
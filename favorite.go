@@ -0,0 +1,143 @@
+package yeahapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Favorite is a listing a user keeps an eye on.
+type Favorite struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Listing   Listing   `json:"listing"`
+}
+
+// FavoriteFilter pages through the favorites of a user, most recent first.
+// After is the id of the last favorite of the previous page.
+type FavoriteFilter struct {
+	UserID UserID
+	Lang   string
+	After  uuid.UUID
+	Limit  int
+}
+
+type FavoritePage struct {
+	Favorites  []Favorite `json:"favorites"`
+	TotalCount int        `json:"total_count"`
+	NextCursor *uuid.UUID `json:"next_cursor"`
+}
+
+type FavoriteService interface {
+	// AddFavorite is a no-op for listings the user already favorited. Only
+	// active listings of other users can be favorited.
+	AddFavorite(ctx context.Context, userID UserID, listingID uuid.UUID) error
+	RemoveFavorite(ctx context.Context, userID UserID, listingID uuid.UUID) error
+	Favorites(ctx context.Context, filter FavoriteFilter) (*FavoritePage, error)
+	// Favorers returns the users who favorited a listing.
+	Favorers(ctx context.Context, listingID uuid.UUID) ([]UserID, error)
+	// Alert publishes a FavoriteAlertEvent for each user who favorited the
+	// listing once it commits. Users already alerted under key are skipped,
+	// so alerting again about the same event is a no-op.
+	Alert(ctx context.Context, key string, alert FavoriteAlert) error
+}
+
+func (f FavoriteFilter) Ok() error {
+	if f.UserID.IsNil() {
+		return E(EInvalid, "User id is required")
+	} else if f.Limit <= 0 {
+		return E(EInvalid, "Limit must be positive")
+	}
+	return nil
+}
+
+// FavoriteAlerter tells users about changes to listings they favorited by
+// publishing an alert per user. Redelivered events alert nobody twice.
+type FavoriteAlerter struct {
+	favoriteService FavoriteService
+}
+
+func NewFavoriteAlerter(favoriteService FavoriteService) *FavoriteAlerter {
+	return &FavoriteAlerter{
+		favoriteService: favoriteService,
+	}
+}
+
+func (a *FavoriteAlerter) PriceDropped(m jetstream.Msg) error {
+	const op Op = "FavoriteAlerter.PriceDropped"
+	var event ListingPriceDroppedEvent
+	if err := json.Unmarshal(m.Data(), &event); err != nil {
+		return E(op, err)
+	}
+
+	alert := FavoriteAlert{
+		ListingID:      event.ListingID,
+		Kind:           NotificationFavoritePriceDropped,
+		Amount:         event.Amount,
+		PreviousAmount: event.PreviousAmount,
+		Currency:       event.Currency,
+	}
+
+	if err := a.alert(m, alert); err != nil {
+		return E(op, err)
+	}
+
+	return nil
+}
+
+func (a *FavoriteAlerter) ListingArchived(m jetstream.Msg) error {
+	const op Op = "FavoriteAlerter.ListingArchived"
+	var event ListingStatusChangedEvent
+	if err := json.Unmarshal(m.Data(), &event); err != nil {
+		return E(op, err)
+	}
+
+	if err := a.alert(m, FavoriteAlert{ListingID: event.ListingID, Kind: NotificationFavoriteArchived}); err != nil {
+		return E(op, err)
+	}
+
+	return nil
+}
+
+func (a *FavoriteAlerter) SoldOut(m jetstream.Msg) error {
+	const op Op = "FavoriteAlerter.SoldOut"
+	var event ListingSoldOutEvent
+	if err := json.Unmarshal(m.Data(), &event); err != nil {
+		return E(op, err)
+	}
+
+	if err := a.alert(m, FavoriteAlert{ListingID: event.ListingID, Kind: NotificationFavoriteSoldOut}); err != nil {
+		return E(op, err)
+	}
+
+	return nil
+}
+
+// alert keys the alerts by the position of m in its stream, which stays the
+// same when m is redelivered.
+func (a *FavoriteAlerter) alert(m jetstream.Msg, alert FavoriteAlert) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	meta, err := m.Metadata()
+	if err != nil {
+		return err
+	}
+
+	return a.favoriteService.Alert(ctx, fmt.Sprintf("%s:%d", meta.Stream, meta.Sequence.Stream), alert)
+}
+
+// FavoriteAlert is a change to a favorited listing, for one user. Amounts are
+// set on price drops.
+type FavoriteAlert struct {
+	UserID         UserID
+	ListingID      uuid.UUID
+	Kind           NotificationKind
+	Amount         int
+	PreviousAmount int
+	Currency       Currency
+}
@@ -0,0 +1,367 @@
+package yeahapi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// maxImagePixels keeps decompression bombs out. It is well above what phone
+// cameras take.
+const maxImagePixels = 50_000_000
+
+// SanitizeImage sniffs the content type of an uploaded photo and strips the
+// metadata from it, which may tell where and with what the photo was taken.
+// JPEG photos that rely on an EXIF orientation are rotated instead, so they
+// still show upright without it.
+func SanitizeImage(data []byte) ([]byte, string, error) {
+	const op Op = "SanitizeImage"
+	contentType := http.DetectContentType(data)
+
+	var config image.Config
+	var err error
+	switch contentType {
+	case "image/jpeg":
+		config, err = jpeg.DecodeConfig(bytes.NewReader(data))
+	case "image/png":
+		config, err = png.DecodeConfig(bytes.NewReader(data))
+	case "image/webp":
+		config, err = webp.DecodeConfig(bytes.NewReader(data))
+	default:
+		return nil, "", E(op, EInvalid, "Only JPEG, PNG and WebP photos are supported")
+	}
+
+	if err != nil {
+		return nil, "", E(op, EInvalid, "Photo is damaged")
+	} else if config.Width*config.Height > maxImagePixels {
+		return nil, "", E(op, EInvalid, "Photo is too large")
+	}
+
+	var out []byte
+	switch contentType {
+	case "image/jpeg":
+		out, err = stripJPEG(data)
+	case "image/png":
+		out, err = stripPNG(data)
+	case "image/webp":
+		out, err = stripWebP(data)
+	}
+
+	if err != nil {
+		return nil, "", E(op, err)
+	}
+
+	return out, contentType, nil
+}
+
+var errDamaged = E(EInvalid, "Photo is damaged")
+
+// stripJPEG drops application segments other than JFIF, ICC profiles and
+// Adobe color info, and comments. Everything from the start of the scan on is
+// copied as is.
+func stripJPEG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	orientation := 1
+
+	for i := 2; ; {
+		if i+2 > len(data) || data[i] != 0xff {
+			return nil, errDamaged
+		}
+
+		marker := data[i+1]
+		if marker == 0xd8 || marker == 0x01 || marker >= 0xd0 && marker <= 0xd7 {
+			out = append(out, data[i:i+2]...)
+			i += 2
+			continue
+		}
+
+		if marker == 0xda || marker == 0xd9 {
+			out = append(out, data[i:]...)
+			break
+		}
+
+		if i+4 > len(data) {
+			return nil, errDamaged
+		}
+
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) || end < i+4 {
+			return nil, errDamaged
+		}
+
+		segment := data[i:end]
+		if marker == 0xe1 {
+			if o := exifOrientation(segment[4:]); o != 0 {
+				orientation = o
+			}
+		}
+
+		if keepJPEGSegment(marker) {
+			out = append(out, segment...)
+		}
+
+		i = end
+	}
+
+	if orientation == 1 {
+		return out, nil
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		return nil, errDamaged
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, orient(img, orientation), &jpeg.Options{Quality: 92}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// keepJPEGSegment reports whether a segment is needed to show the photo
+// right: JFIF (APP0), ICC profiles (APP2), Adobe color info (APP14) and
+// everything that isn't an application segment or a comment.
+func keepJPEGSegment(marker byte) bool {
+	switch {
+	case marker == 0xe0, marker == 0xe2, marker == 0xee:
+		return true
+	case marker >= 0xe1 && marker <= 0xef, marker == 0xfe:
+		return false
+	}
+	return true
+}
+
+// exifOrientation reads the orientation tag from the first IFD of an APP1
+// segment payload. It returns 0 when there is none.
+func exifOrientation(b []byte) int {
+	if len(b) < 14 || string(b[:6]) != "Exif\x00\x00" {
+		return 0
+	}
+
+	tiff := b[6:]
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+
+	n := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < n; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+
+	return 0
+}
+
+// orient applies an EXIF orientation to img.
+func orient(img image.Image, orientation int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if orientation >= 5 {
+		w, h = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = w-1-y, x
+			case 7:
+				dx, dy = w-1-y, h-1-x
+			case 8:
+				dx, dy = y, h-1-x
+			default:
+				dx, dy = x, y
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+
+	return dst
+}
+
+// stripPNG drops text, time and EXIF chunks.
+func stripPNG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:8]...)
+
+	for i := 8; i < len(data); {
+		if i+12 > len(data) {
+			return nil, errDamaged
+		}
+
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end > len(data) || end < i {
+			return nil, errDamaged
+		}
+
+		switch string(data[i+4 : i+8]) {
+		case "tEXt", "zTXt", "iTXt", "tIME", "eXIf":
+		default:
+			out = append(out, data[i:end]...)
+		}
+
+		i = end
+	}
+
+	return out, nil
+}
+
+// stripWebP drops EXIF and XMP chunks and clears their flags in the extended
+// header.
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 {
+		return nil, errDamaged
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, errDamaged
+		}
+
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if end > len(data) || end < i {
+			return nil, errDamaged
+		}
+
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+
+		i = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// DecodeImage decodes a photo in one of the supported content types.
+func DecodeImage(r io.Reader, contentType string) (image.Image, error) {
+	const op Op = "DecodeImage"
+	var img image.Image
+	var err error
+	switch contentType {
+	case "image/jpeg":
+		img, err = jpeg.Decode(r)
+	case "image/png":
+		img, err = png.Decode(r)
+	case "image/webp":
+		img, err = webp.Decode(r)
+	default:
+		return nil, E(op, EInvalid, fmt.Sprintf("Unsupported content type: %s", contentType))
+	}
+
+	if err != nil {
+		return nil, E(op, err)
+	}
+
+	return img, nil
+}
+
+// FitImage scales img down to fit in a size by size box, keeping its aspect
+// ratio. Transparent parts are painted white, since variants are JPEGs.
+// Images are never scaled up.
+func FitImage(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(1, h*size/w)
+		} else {
+			w, h = max(1, w*size/h), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Over, nil)
+	return dst
+}
+
+// DominantColor returns the average color of img as a CSS hex color. It is
+// shown in place of a photo until the photo loads.
+func DominantColor(img image.Image) string {
+	small := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	draw.Draw(small, small.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), img, img.Bounds(), draw.Over, nil)
+
+	var r, g, b int
+	for i := 0; i < len(small.Pix); i += 4 {
+		r, g, b = r+int(small.Pix[i]), g+int(small.Pix[i+1]), b+int(small.Pix[i+2])
+	}
+
+	n := len(small.Pix) / 4
+	return fmt.Sprintf("#%02x%02x%02x", r/n, g/n, b/n)
+}
+
+// PerceptualHash returns a difference hash of img: whether each pixel of a 9x8
+// grayscale thumbnail is brighter than its left neighbour. Resized,
+// recompressed or slightly edited copies of a photo hash to values a few bits
+// apart, see MaxPhotoDistance.
+func PerceptualHash(img image.Image) int64 {
+	small := image.NewGray(image.Rect(0, 0, 9, 8))
+	draw.Draw(small, small.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), img, img.Bounds(), draw.Over, nil)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if small.GrayAt(x+1, y).Y > small.GrayAt(x, y).Y {
+				hash |= 1
+			}
+		}
+	}
+	return int64(hash)
+}
@@ -0,0 +1,72 @@
+package inmem
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type attributesKey struct {
+	categoryID string
+	lang       string
+}
+
+type cachedAttributes struct {
+	attributes []*yeahapi.CategoryAttribute
+	expiresAt  time.Time
+}
+
+// CategoryCache keeps category attributes in memory in front of another
+// CategoryService. Writes made through it drop the cache; TTL bounds how long
+// changes made elsewhere, e.g. by other instances or migrations, go unnoticed.
+type CategoryCache struct {
+	yeahapi.CategoryService
+	TTL time.Duration
+
+	mu         sync.RWMutex
+	attributes map[attributesKey]cachedAttributes
+}
+
+func NewCategoryCache(categoryService yeahapi.CategoryService) *CategoryCache {
+	return &CategoryCache{
+		CategoryService: categoryService,
+		TTL:             10 * time.Minute,
+		attributes:      make(map[attributesKey]cachedAttributes),
+	}
+}
+
+func (c *CategoryCache) Attributes(ctx context.Context, categoryID string, lang string) ([]*yeahapi.CategoryAttribute, error) {
+	key := attributesKey{categoryID, lang}
+	c.mu.RLock()
+	cached, ok := c.attributes[key]
+	c.mu.RUnlock()
+
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.attributes, nil
+	}
+
+	attributes, err := c.CategoryService.Attributes(ctx, categoryID, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.attributes[key] = cachedAttributes{attributes, time.Now().Add(c.TTL)}
+	c.mu.Unlock()
+
+	return attributes, nil
+}
+
+func (c *CategoryCache) CreateCategory(ctx context.Context, category *yeahapi.Category) (*yeahapi.Category, error) {
+	defer c.Invalidate()
+	return c.CategoryService.CreateCategory(ctx, category)
+}
+
+// Invalidate drops all cached attributes.
+func (c *CategoryCache) Invalidate() {
+	c.mu.Lock()
+	c.attributes = make(map[attributesKey]cachedAttributes)
+	c.mu.Unlock()
+}
@@ -0,0 +1,86 @@
+package inmem
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+var (
+	// phoneRegex finds Uzbek phone numbers: +998 or 998 followed by the
+	// operator code and number, or the 9 digits without the country code
+	// starting with an operator code 9x. Separators may appear between digits.
+	phoneRegex = regexp.MustCompile(`(?:\+\s*998|\b998|\b9\d)(?:[\s\-().]{0,2}\d){7,10}\b`)
+	// priceRegex and yearsRegex tell prices grouped by thousands and year
+	// ranges apart from phone numbers.
+	priceRegex = regexp.MustCompile(`^\d{1,3}(?:[\s.,]\d{3})+$`)
+	yearsRegex = regexp.MustCompile(`\d{4}\s*-\s*\d{4}`)
+	urlRegex   = regexp.MustCompile(`(?i)(?:https?://|www\.|t\.me/|@[a-z0-9_]{4,}|\b[a-z0-9\-]+\.(?:uz|com|ru|net|org|me)\b)`)
+)
+
+// findPhone returns the first phone number in text. Numbers with 9 to 12
+// digits count, which covers local numbers and ones with the country code.
+func findPhone(text string) string {
+	for _, m := range phoneRegex.FindAllString(text, -1) {
+		if priceRegex.MatchString(m) || yearsRegex.MatchString(m) {
+			continue
+		}
+
+		digits := 0
+		for _, r := range m {
+			if r >= '0' && r <= '9' {
+				digits++
+			}
+		}
+
+		if digits >= 9 && digits <= 12 {
+			return m
+		}
+	}
+
+	return ""
+}
+
+// ContactsCheck flags phone numbers, links and messenger handles in listing
+// titles and descriptions. Sellers are expected to be contacted through the
+// platform.
+type ContactsCheck struct{}
+
+func NewContactsCheck() *ContactsCheck {
+	return &ContactsCheck{}
+}
+
+func (c *ContactsCheck) Name() string {
+	return "contacts"
+}
+
+func (c *ContactsCheck) Check(ctx context.Context, listing *yeahapi.Listing, skus []yeahapi.ListingSku) (*yeahapi.CheckResult, error) {
+	fields := []struct{ name, text string }{
+		{"title", listing.Title},
+		{"description", yeahapi.DescriptionText(listing.Description)},
+	}
+
+	for _, field := range fields {
+		// Digits in titles are often models, years or prices, so phone numbers
+		// are left for a moderator to confirm.
+		if m := findPhone(field.text); m != "" {
+			return &yeahapi.CheckResult{
+				Score:      0.8,
+				ReasonCode: "CONTACTS_IN_TEXT",
+				Detail:     fmt.Sprintf("Phone number in %s: %s", field.name, m),
+			}, nil
+		}
+
+		if m := urlRegex.FindString(field.text); m != "" {
+			return &yeahapi.CheckResult{
+				Score:      0.95,
+				ReasonCode: "CONTACTS_IN_TEXT",
+				Detail:     fmt.Sprintf("Link in %s: %s", field.name, m),
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,67 @@
+package inmem_test
+
+import (
+	"context"
+	"testing"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/inmem"
+)
+
+func TestContactsCheck_Check(t *testing.T) {
+	tests := []struct {
+		title string
+		found bool
+	}{
+		{"Chevrolet Cobalt 2015-2020", false},
+		{"Nexia 3, 2019 yil, 1998-2008 kuzov", false},
+		{"Цена 1 200 000 сум", false},
+		{"Цена 950 000 000 сум", false},
+		{"iPhone 13 Pro 256GB", false},
+		{"Samsung Galaxy S9 990 000 so'm", false},
+		{"Продаю диван, звоните +998 90 123 45 67", true},
+		{"Продаю диван +998901234567", true},
+		{"Sotiladi 998 (93) 123-45-67", true},
+		{"Sotiladi, tel: 90 123 45 67", true},
+		{"Sotiladi, tel: (97) 123-45-67", true},
+		{"Sotiladi, tel: 991234567", true},
+	}
+
+	c := inmem.NewContactsCheck()
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			result, err := c.Check(context.Background(), &yeahapi.Listing{Title: tt.title}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if found := result != nil; found != tt.found {
+				t.Fatalf("expected found to be %v, got %#v", tt.found, result)
+			}
+
+			if result != nil && result.Score >= yeahapi.NewContentChecker(nil, nil).RejectAt {
+				t.Fatalf("phone numbers should be flagged, got score %v", result.Score)
+			}
+		})
+	}
+}
+
+func TestContentChecker_Run(t *testing.T) {
+	t.Run("Translations", func(t *testing.T) {
+		c := yeahapi.NewContentChecker(nil, nil)
+		c.RegisterText(inmem.NewContactsCheck())
+
+		listing := &yeahapi.Listing{Title: "Sotiladi divan", Lang: "uz"}
+		results, err := c.Run(context.Background(), listing, nil, []yeahapi.ListingTranslation{
+			{Lang: "en", Title: "Sofa for sale"},
+			{Lang: "ru", Title: "Продаю диван +998901234567"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(results) != 1 || results[0].Check != "contacts" || results[0].Detail != "Phone number in title: +998901234567 (ru translation)" {
+			t.Fatalf("unexpected results: %#v", results)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package inmem
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type similarKey struct {
+	listingID uuid.UUID
+	lang      string
+	limit     int
+}
+
+type cachedSimilar struct {
+	listings  []yeahapi.SimilarListing
+	expiresAt time.Time
+}
+
+// SimilarCache keeps similar listings in memory in front of another
+// RecommendationService, since they are costly to find and seen by every
+// visitor of a listing. Listings that change show up in recommendations once
+// TTL passes. At most MaxEntries results are kept, expired ones are dropped
+// first.
+type SimilarCache struct {
+	yeahapi.RecommendationService
+	TTL        time.Duration
+	MaxEntries int
+
+	mu      sync.RWMutex
+	similar map[similarKey]cachedSimilar
+}
+
+func NewSimilarCache(recommendationService yeahapi.RecommendationService) *SimilarCache {
+	return &SimilarCache{
+		RecommendationService: recommendationService,
+		TTL:                   30 * time.Minute,
+		MaxEntries:            10000,
+		similar:               make(map[similarKey]cachedSimilar),
+	}
+}
+
+func (c *SimilarCache) SimilarListings(ctx context.Context, listingID uuid.UUID, lang string, limit int) ([]yeahapi.SimilarListing, error) {
+	key := similarKey{listingID, lang, limit}
+	c.mu.RLock()
+	cached, ok := c.similar[key]
+	c.mu.RUnlock()
+
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.listings, nil
+	}
+
+	listings, err := c.RecommendationService.SimilarListings(ctx, listingID, lang, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	if len(c.similar) >= c.MaxEntries {
+		for k, v := range c.similar {
+			if !now.Before(v.expiresAt) {
+				delete(c.similar, k)
+			}
+		}
+		// Everything is fresh, start over rather than pick what to drop.
+		if len(c.similar) >= c.MaxEntries {
+			c.similar = make(map[similarKey]cachedSimilar)
+		}
+	}
+	c.similar[key] = cachedSimilar{listings, now.Add(c.TTL)}
+	c.mu.Unlock()
+
+	return listings, nil
+}
@@ -0,0 +1,87 @@
+package inmem
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+// HitAggregator collects listing hits in memory and hands them to a
+// StatsService in batches, so that popular listings don't cost a write per
+// hit. Repeated hits are merged before they are stored. Hits that come in
+// while MaxPending hits are waiting are dropped.
+type HitAggregator struct {
+	Interval   time.Duration
+	MaxPending int
+
+	statsService yeahapi.StatsService
+
+	mu      sync.Mutex
+	pending map[yeahapi.ListingHit]struct{}
+}
+
+func NewHitAggregator(statsService yeahapi.StatsService) *HitAggregator {
+	return &HitAggregator{
+		Interval:     30 * time.Second,
+		MaxPending:   100000,
+		statsService: statsService,
+		pending:      make(map[yeahapi.ListingHit]struct{}),
+	}
+}
+
+func (a *HitAggregator) RecordHit(hit yeahapi.ListingHit) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.pending) < a.MaxPending {
+		a.pending[hit] = struct{}{}
+	}
+}
+
+// Run blocks until ctx is done, then flushes what is left.
+func (a *HitAggregator) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := a.Flush(ctx); err != nil {
+				fmt.Println(err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := a.Flush(ctx); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+// Flush stores the pending hits. They are dropped when that fails.
+func (a *HitAggregator) Flush(ctx context.Context) error {
+	const op yeahapi.Op = "inmem/HitAggregator.Flush"
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = make(map[yeahapi.ListingHit]struct{})
+	a.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	hits := make([]yeahapi.ListingHit, 0, len(pending))
+	for hit := range pending {
+		hits = append(hits, hit)
+	}
+
+	if err := a.statsService.RecordHits(ctx, hits); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package yeahapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// LowStockThreshold is the sku quantity at or below which sellers are told
+// that stock runs low.
+const LowStockThreshold = 3
+
+// ReservationTTL is how long reserved stock is held before it goes back into
+// stock.
+const ReservationTTL = 30 * time.Minute
+
+// MaxReservedQuantity is how many items of a listing a buyer may hold at once.
+const MaxReservedQuantity = 5
+
+type StockReason string
+
+const (
+	StockReasonRestock     StockReason = "RESTOCK"
+	StockReasonCorrection  StockReason = "CORRECTION"
+	StockReasonReservation StockReason = "RESERVATION"
+	StockReasonRelease     StockReason = "RELEASE"
+)
+
+// StockReservation holds stock of a sku for a buyer until it is released, or
+// until it expires.
+type StockReservation struct {
+	ID         uuid.UUID  `json:"id"`
+	SkuID      uuid.UUID  `json:"sku_id"`
+	UserID     UserID     `json:"user_id"`
+	Quantity   int        `json:"quantity"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ReleasedAt *time.Time `json:"released_at"`
+}
+
+// StockAdjustment is an entry of the inventory log. Quantity is the stock of
+// the sku and ListingQuantity the stock of the whole listing right after the
+// adjustment.
+type StockAdjustment struct {
+	ID              int64       `json:"id"`
+	SkuID           uuid.UUID   `json:"sku_id"`
+	ListingID       uuid.UUID   `json:"listing_id"`
+	ReservationID   *uuid.UUID  `json:"reservation_id"`
+	Delta           int         `json:"delta"`
+	Quantity        int         `json:"quantity"`
+	ListingQuantity int         `json:"listing_quantity"`
+	Reason          StockReason `json:"reason"`
+	Note            string      `json:"note"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+// LowStock reports whether the adjustment brought the sku down to or below
+// LowStockThreshold, or out of stock.
+func (a *StockAdjustment) LowStock() bool {
+	previous := a.Quantity - a.Delta
+	return a.Delta < 0 && (previous > LowStockThreshold && a.Quantity <= LowStockThreshold || a.Quantity == 0)
+}
+
+// SoldOut reports whether the adjustment took the last item of the listing.
+func (a *StockAdjustment) SoldOut() bool {
+	return a.Delta < 0 && a.ListingQuantity == 0
+}
+
+// InventoryService keeps track of sku stock. Every change of stock is logged
+// as an adjustment and stock never goes below zero, even with concurrent
+// buyers. The StockEvents of an adjustment are published once it commits.
+type InventoryService interface {
+	// AdjustStock changes stock of a sku by delta. Only restocks and
+	// corrections may be made this way.
+	AdjustStock(ctx context.Context, skuID uuid.UUID, delta int, reason StockReason, note string) (*StockAdjustment, error)
+	// Reserve takes quantity items of an active listing's sku out of stock for
+	// the buyer for ReservationTTL. It fails with EConflict when there is not
+	// enough stock, with EPermission for the owner of the listing and with
+	// EInvalid when the buyer would hold more than MaxReservedQuantity items
+	// of the listing.
+	Reserve(ctx context.Context, skuID uuid.UUID, userID UserID, quantity int) (*StockReservation, *StockAdjustment, error)
+	// Release puts reserved items back into stock. A reservation is released
+	// once, releasing it again fails with EConflict.
+	Release(ctx context.Context, reservationID uuid.UUID) (*StockReservation, *StockAdjustment, error)
+	Reservation(ctx context.Context, id uuid.UUID) (*StockReservation, error)
+	// ExpiredReservations returns up to limit reservations that expired
+	// without being released.
+	ExpiredReservations(ctx context.Context, limit int) ([]uuid.UUID, error)
+	StockAdjustments(ctx context.Context, skuID uuid.UUID) ([]StockAdjustment, error)
+}
+
+// StockEvents returns the events an adjustment calls for.
+func StockEvents(adjustment *StockAdjustment) []CQRSMessage {
+	events := make([]CQRSMessage, 0)
+	if adjustment.LowStock() {
+		events = append(events, NewListingStockLowEvent(adjustment))
+	}
+	if adjustment.SoldOut() {
+		events = append(events, NewListingSoldOutEvent(adjustment))
+	}
+	return events
+}
+
+// ReservationSweeper periodically puts the stock of expired reservations back.
+// Only one replica does it at a time.
+type ReservationSweeper struct {
+	Interval  time.Duration
+	BatchSize int
+
+	inventoryService InventoryService
+	locker           Locker
+}
+
+func NewReservationSweeper(inventoryService InventoryService, locker Locker) *ReservationSweeper {
+	return &ReservationSweeper{
+		Interval:         time.Minute,
+		BatchSize:        100,
+		inventoryService: inventoryService,
+		locker:           locker,
+	}
+}
+
+// Run blocks until ctx is done.
+func (s *ReservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.locker.TryLock(ctx, "listings.reservations", s.sweep); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+func (s *ReservationSweeper) sweep(ctx context.Context) error {
+	const op Op = "ReservationSweeper.sweep"
+	for {
+		expired, err := s.inventoryService.ExpiredReservations(ctx, s.BatchSize)
+		if err != nil {
+			return E(op, err)
+		}
+
+		for _, id := range expired {
+			// The buyer or the seller may have just released it.
+			if _, _, err := s.inventoryService.Release(ctx, id); err != nil && !EIs(EConflict, err) {
+				return E(op, err)
+			}
+		}
+
+		if len(expired) < s.BatchSize {
+			return nil
+		}
+	}
+}
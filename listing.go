@@ -2,7 +2,9 @@ package yeahapi
 
 import (
 	"context"
+	"fmt"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gofrs/uuid"
 )
@@ -18,6 +20,49 @@ const (
 	ListingStatusDeleted    ListingStatus = "DELETED"
 )
 
+// listingTransitions lists the statuses a listing may be moved to. Active
+// listings go back to moderation when they are reported too often, active and
+// archived ones when they are edited.
+var listingTransitions = map[ListingStatus][]ListingStatus{
+	ListingStatusDraft:      {ListingStatusModeration},
+	ListingStatusModeration: {ListingStatusIndexing, ListingStatusDraft},
+	ListingStatusIndexing:   {ListingStatusActive},
+	ListingStatusActive:     {ListingStatusArchived, ListingStatusModeration},
+	ListingStatusArchived:   {ListingStatusActive, ListingStatusModeration},
+}
+
+// CanTransition reports whether a listing in status s may be moved to status
+// to. Any listing that is not deleted yet may be deleted.
+func (s ListingStatus) CanTransition(to ListingStatus) bool {
+	if to == ListingStatusDeleted {
+		return s != ListingStatusDeleted
+	}
+
+	for _, next := range listingTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+type ListingCondition string
+
+const (
+	ListingConditionNew         ListingCondition = "NEW"
+	ListingConditionUsed        ListingCondition = "USED"
+	ListingConditionRefurbished ListingCondition = "REFURBISHED"
+)
+
+// Ok accepts the empty condition, not every item has one.
+func (c ListingCondition) Ok() error {
+	switch c {
+	case "", ListingConditionNew, ListingConditionUsed, ListingConditionRefurbished:
+		return nil
+	}
+	return E(EInvalid, "Unknown condition")
+}
+
 type Currency string
 
 const (
@@ -41,24 +86,128 @@ type ListingSku struct {
 	Price         int          `json:"price"`
 	PriceCurrency Currency     `json:"price_currency"`
 	Attrs         ListingAttrs `json:"attrs"`
+	Quantity      int          `json:"quantity"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+	// DisplayPrice is the price converted into the currency the client asked
+	// for, if any.
+	DisplayPrice *ListingPrice `json:"display_price,omitempty"`
 }
 
+type ListingPrice struct {
+	Amount   int      `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// Listing is written by its seller in Lang, if they told which. Title and
+// Description may come from a translation instead, Lang is then the language
+// of the translation.
 type Listing struct {
-	ID         uuid.UUID     `json:"id"`
-	Title      string        `json:"title"`
-	CategoryID int           `json:"category_id"`
-	OwnerID    UserID        `json:"owner_id"`
-	Status     ListingStatus `json:"status"`
+	ID          uuid.UUID        `json:"id"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Condition   ListingCondition `json:"condition"`
+	Brand       string           `json:"brand"`
+	Lang        string           `json:"lang"`
+	LocationID  int              `json:"location_id"`
+	Point       *Point           `json:"point"`
+	CategoryID  int              `json:"category_id"`
+	OwnerID     UserID           `json:"owner_id"`
+	Status      ListingStatus    `json:"status"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	MinPrice    *ListingPrice    `json:"min_price,omitempty"`
+	// SoldOut is set when the listing has skus and none of them is in stock.
+	SoldOut       bool `json:"sold_out"`
+	FavoriteCount int  `json:"favorite_count"`
+	// ExpiresAt is set once the listing was published. Active listings are
+	// archived when they expire.
+	ExpiresAt *time.Time `json:"expires_at"`
+	// Promotions are the ones in effect right now.
+	Promotions []ListingPromotion `json:"promotions"`
+}
+
+// ListingTranslation is the title and description of a listing in another
+// language, provided by the seller.
+type ListingTranslation struct {
+	ListingID   uuid.UUID `json:"listing_id"`
+	Lang        string    `json:"lang"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+}
+
+// ListingFilter narrows down listing queries. Listings are returned newest
+// first and After is the id of the last listing of the previous page. With
+// Lang set, listings are translated into it where the seller provided a
+// translation.
+type ListingFilter struct {
+	OwnerID    UserID
+	Statuses   []ListingStatus
+	CategoryID int
+	Lang       string
+	After      uuid.UUID
+	Limit      int
+}
+
+type ListingPage struct {
+	Listings   []Listing  `json:"listings"`
+	TotalCount int        `json:"total_count"`
+	NextCursor *uuid.UUID `json:"next_cursor"`
+}
+
+// ListingUpdate describes a partial update of a listing. Nil fields are left
+// untouched. UpdatedAt must match the stored value, otherwise the update is
+// rejected with EConflict.
+type ListingUpdate struct {
+	Title       *string
+	Description *string
+	Condition   *ListingCondition
+	Brand       *string
+	Lang        *string
+	Location    *ListingLocation
+	CategoryID  *int
+	UpdatedAt   time.Time
+	// Resubmit sends active and archived listings back to moderation along
+	// with the update, so that they can't be changed into something else
+	// once approved. Listings under review can't be updated then.
+	Resubmit bool
+}
+
+// ListingSkuUpdate describes a partial update of a listing sku. Price and
+// PriceCurrency are updated together.
+type ListingSkuUpdate struct {
+	CustomSku     *string
+	Attrs         *ListingAttrs
+	Price         *int
+	PriceCurrency *Currency
+	UpdatedAt     time.Time
 }
 
 type ListingService interface {
 	CreateListing(ctx context.Context, listing *Listing) (*Listing, error)
 	Listing(ctx context.Context, id uuid.UUID) (*Listing, error)
+	// LocalizedListing returns the listing translated into lang, or as
+	// written when there is no translation for lang.
+	LocalizedListing(ctx context.Context, id uuid.UUID, lang string) (*Listing, error)
+	Listings(ctx context.Context, filter ListingFilter) (*ListingPage, error)
 	DeleteListing(ctx context.Context, id uuid.UUID) error
+	UpdateListing(ctx context.Context, id uuid.UUID, upd ListingUpdate) (*Listing, error)
+	// UpdateStatus moves a listing from one status to another along with a
+	// ListingStatusChangedEvent, which is published once the move commits.
+	UpdateStatus(ctx context.Context, id uuid.UUID, from, to ListingStatus) (*Listing, error)
 	CreateSku(ctx context.Context, sku *ListingSku) (*ListingSku, error)
+	CreateVariations(ctx context.Context, listingID uuid.UUID, keys []string, skus []ListingSku) ([]ListingSku, error)
+	UpdateSku(ctx context.Context, id uuid.UUID, upd ListingSkuUpdate) (*ListingSku, error)
 	Sku(ctx context.Context, skuID uuid.UUID) (*ListingSku, error)
 	DeleteSku(ctx context.Context, id uuid.UUID) error
 	Skus(ctx context.Context, listingID uuid.UUID) ([]ListingSku, error)
+	// SetTranslation adds or replaces the translation of a listing into a
+	// language other than the one it is written in. Like edits, changes of
+	// translations send approved listings back to moderation and aren't
+	// allowed while listings are under review.
+	SetTranslation(ctx context.Context, tr *ListingTranslation) (*ListingTranslation, error)
+	DeleteTranslation(ctx context.Context, listingID uuid.UUID, lang string) error
+	Translations(ctx context.Context, listingID uuid.UUID) ([]ListingTranslation, error)
 }
 
 func (l *Listing) Ok() error {
@@ -70,6 +219,79 @@ func (l *Listing) Ok() error {
 		return E(EInvalid, "Title is required")
 	} else if l.Status == "" {
 		return E(EInvalid, "Listing status is required")
+	} else if err := l.Condition.Ok(); err != nil {
+		return err
+	} else if err := descriptionOk(l.Description); err != nil {
+		return err
+	} else if err := (ListingLocation{l.LocationID, l.Point}).Ok(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ListingTranslation) Ok() error {
+	if t.ListingID.IsNil() {
+		return E(EInvalid, "Listing id is required")
+	} else if t.Lang == "" {
+		return E(EInvalid, "Language is required")
+	} else if t.Title == "" {
+		return E(EInvalid, "Title is required")
+	} else if err := descriptionOk(t.Description); err != nil {
+		return err
+	}
+	return nil
+}
+
+func descriptionOk(description string) error {
+	if utf8.RuneCountInString(DescriptionText(description)) > MaxDescriptionLength {
+		return E(EInvalid, fmt.Sprintf("Description can be at most %d characters long", MaxDescriptionLength))
+	}
+	return nil
+}
+
+func (u ListingUpdate) Ok() error {
+	if u.Title == nil && u.Description == nil && u.Condition == nil && u.Brand == nil && u.Lang == nil && u.Location == nil && u.CategoryID == nil {
+		return E(EInvalid, "Nothing to update")
+	} else if u.Title != nil && *u.Title == "" {
+		return E(EInvalid, "Title is required")
+	} else if u.CategoryID != nil && *u.CategoryID == 0 {
+		return E(EInvalid, "Category id is required")
+	} else if u.UpdatedAt.IsZero() {
+		return E(EInvalid, "Updated at is required")
+	}
+
+	if u.Condition != nil {
+		if err := u.Condition.Ok(); err != nil {
+			return err
+		}
+	}
+	if u.Location != nil {
+		if err := u.Location.Ok(); err != nil {
+			return err
+		}
+	}
+	if u.Description != nil {
+		return descriptionOk(*u.Description)
+	}
+	return nil
+}
+
+func (u ListingSkuUpdate) Ok() error {
+	if u.CustomSku == nil && u.Attrs == nil && u.Price == nil {
+		return E(EInvalid, "Nothing to update")
+	} else if (u.Price == nil) != (u.PriceCurrency == nil) {
+		return E(EInvalid, "Price and currency must be updated together")
+	} else if u.PriceCurrency != nil && *u.PriceCurrency == "" {
+		return E(EInvalid, "Currency is required")
+	} else if u.UpdatedAt.IsZero() {
+		return E(EInvalid, "Updated at is required")
+	}
+	return nil
+}
+
+func (f ListingFilter) Ok() error {
+	if f.Limit <= 0 {
+		return E(EInvalid, "Limit must be positive")
 	}
 	return nil
 }
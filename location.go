@@ -0,0 +1,81 @@
+package yeahapi
+
+import (
+	"context"
+	"math"
+)
+
+// MaxSearchRadius is the largest radius listings may be searched within, in
+// kilometers.
+const MaxSearchRadius = 500
+
+// earthRadius is the mean radius of the Earth in kilometers.
+const earthRadius = 6371.0
+
+type LocationKind string
+
+const (
+	LocationKindRegion   LocationKind = "REGION"
+	LocationKindDistrict LocationKind = "DISTRICT"
+	LocationKindCity     LocationKind = "CITY"
+)
+
+// Location is a region, a district of a region or a city. Cities belong to
+// a district or, when they are subordinate to the region, to the region
+// itself. Lat and Lng point at the center of the location.
+type Location struct {
+	ID       int          `json:"id"`
+	ParentID int          `json:"parent_id"`
+	Kind     LocationKind `json:"kind"`
+	Name     string       `json:"name"`
+	Lat      float64      `json:"lat"`
+	Lng      float64      `json:"lng"`
+}
+
+type LocationService interface {
+	Locations(ctx context.Context, lang string) ([]Location, error)
+	Location(ctx context.Context, id int, lang string) (*Location, error)
+}
+
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+func (p Point) Ok() error {
+	if math.IsNaN(p.Lat) || p.Lat < -90 || p.Lat > 90 {
+		return E(EInvalid, "Latitude must be between -90 and 90")
+	} else if math.IsNaN(p.Lng) || p.Lng < -180 || p.Lng > 180 {
+		return E(EInvalid, "Longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// Bounds returns the corners of a box around p that holds every point
+// within radius kilometers of it. The box is cheap to check with indexes
+// before the exact distance is. It isn't wrapped around the antimeridian.
+func (p Point) Bounds(radius float64) (min, max Point) {
+	dLat := radius / earthRadius * 180 / math.Pi
+	dLng := 180.0
+	if cos := math.Cos(p.Lat * math.Pi / 180); cos > 1e-6 {
+		dLng = math.Min(dLng, dLat/cos)
+	}
+
+	min = Point{Lat: math.Max(p.Lat-dLat, -90), Lng: p.Lng - dLng}
+	max = Point{Lat: math.Min(p.Lat+dLat, 90), Lng: p.Lng + dLng}
+	return min, max
+}
+
+// ListingLocation is where a listing is. Point is optional and more precise
+// than the location.
+type ListingLocation struct {
+	LocationID int
+	Point      *Point
+}
+
+func (l ListingLocation) Ok() error {
+	if l.Point != nil {
+		return l.Point.Ok()
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package yeahapi
+
+import "context"
+
+// Locker runs work that only one process may be doing at a time.
+type Locker interface {
+	// TryLock runs fn while holding the named lock. When another process
+	// holds it, fn isn't run and TryLock reports false.
+	TryLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error)
+}
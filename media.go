@@ -0,0 +1,218 @@
+package yeahapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image/jpeg"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// MaxMediaSize is the largest file that may be uploaded, in bytes.
+	MaxMediaSize = 10 << 20
+	// MaxListingMedia is how many photos a listing may have.
+	MaxListingMedia = 20
+)
+
+// mediaExtensions are the content types photos may be uploaded in.
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// MediaVariantSizes are the variants made of every photo, by the size of the
+// box they fit in.
+var MediaVariantSizes = []struct {
+	Name string
+	Size int
+}{
+	{"thumbnail", 160},
+	{"card", 480},
+	{"full", 1280},
+}
+
+// MediaVariant is a resized copy of a photo. Variants are JPEGs.
+type MediaVariant struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url,omitempty"`
+}
+
+// Media is a photo of a listing. Photos are shown in the order of Position
+// and exactly one photo of a listing with photos is primary. Dimensions,
+// placeholder and variants are filled in by MediaProcessor after upload.
+type Media struct {
+	ID          uuid.UUID `json:"id"`
+	ListingID   uuid.UUID `json:"listing_id"`
+	OwnerID     UserID    `json:"owner_id"`
+	Key         string    `json:"-"`
+	URL         string    `json:"url"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Position    int       `json:"position"`
+	Primary     bool      `json:"primary"`
+	CreatedAt   time.Time `json:"created_at"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	// Placeholder is a CSS color shown until the photo loads.
+	Placeholder string         `json:"placeholder"`
+	Variants    []MediaVariant `json:"variants"`
+	// PerceptualHash finds copies of the photo on other listings.
+	PerceptualHash int64 `json:"-"`
+}
+
+type Blob struct {
+	ContentType string
+	Size        int64
+	Body        io.ReadCloser
+}
+
+// BlobStore stores uploaded files under keys that look like relative paths.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get returns the blob stored under key. The caller closes its body.
+	Get(ctx context.Context, key string) (*Blob, error)
+	Delete(ctx context.Context, key string) error
+	// URL returns where clients can download the blob from.
+	URL(key string) string
+}
+
+type MediaService interface {
+	// CreateMedia adds a photo after the last photo of the listing. The first
+	// photo of a listing becomes its primary photo. A MediaUploadedEvent is
+	// published once it commits.
+	CreateMedia(ctx context.Context, media *Media) (*Media, error)
+	Media(ctx context.Context, id uuid.UUID) (*Media, error)
+	ListingMedia(ctx context.Context, listingID uuid.UUID) ([]Media, error)
+	// ReorderMedia puts the photos of a listing in the order of ids, which
+	// must list every photo of the listing.
+	ReorderMedia(ctx context.Context, listingID uuid.UUID, ids []uuid.UUID) ([]Media, error)
+	SetPrimaryMedia(ctx context.Context, id uuid.UUID) error
+	// DeleteMedia deletes a photo and returns it so its blob can be deleted
+	// too. When the primary photo is deleted, the first photo left becomes
+	// primary.
+	DeleteMedia(ctx context.Context, id uuid.UUID) (*Media, error)
+	// SaveVariants records the dimensions, placeholder, variants and hash of a
+	// processed photo. A MediaProcessedEvent is published once it commits,
+	// unless the listing is a draft, which is checked when it is submitted.
+	SaveVariants(ctx context.Context, media *Media) error
+}
+
+// MediaKey is the blob key of a listing photo.
+func MediaKey(media *Media) string {
+	return fmt.Sprintf("listings/%s/%s%s", media.ListingID, media.ID, mediaExtensions[media.ContentType])
+}
+
+// VariantKey is the blob key of a variant of a photo, next to the photo.
+func VariantKey(media *Media, name string) string {
+	return strings.TrimSuffix(media.Key, mediaExtensions[media.ContentType]) + "_" + name + ".jpg"
+}
+
+func (m *Media) Ok() error {
+	if m.ListingID.IsNil() {
+		return E(EInvalid, "Listing id is required")
+	} else if m.OwnerID.IsNil() {
+		return E(EInvalid, "Owner id is required")
+	} else if m.Key == "" {
+		return E(EInvalid, "Key is required")
+	} else if _, ok := mediaExtensions[m.ContentType]; !ok {
+		return E(EInvalid, "Only JPEG, PNG and WebP photos are supported")
+	}
+	return nil
+}
+
+// MediaProcessor makes variants of uploaded photos.
+type MediaProcessor struct {
+	mediaService MediaService
+	blobStore    BlobStore
+}
+
+func NewMediaProcessor(mediaService MediaService, blobStore BlobStore) *MediaProcessor {
+	return &MediaProcessor{
+		mediaService: mediaService,
+		blobStore:    blobStore,
+	}
+}
+
+// MediaUploaded makes and stores the variants of an uploaded photo. Photos
+// deleted in the meantime are skipped.
+func (p *MediaProcessor) MediaUploaded(m jetstream.Msg) error {
+	const op Op = "MediaProcessor.MediaUploaded"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var event MediaUploadedEvent
+	if err := json.Unmarshal(m.Data(), &event); err != nil {
+		return E(op, err)
+	}
+
+	media, err := p.mediaService.Media(ctx, event.MediaID)
+	if err != nil {
+		if EIs(ENotFound, err) {
+			return nil
+		}
+		return E(op, err)
+	}
+
+	if err := p.Process(ctx, media); err != nil {
+		if EIs(ENotFound, err) {
+			return nil
+		}
+		return E(op, err)
+	}
+
+	return nil
+}
+
+func (p *MediaProcessor) Process(ctx context.Context, media *Media) error {
+	const op Op = "MediaProcessor.Process"
+	blob, err := p.blobStore.Get(ctx, media.Key)
+	if err != nil {
+		return E(op, err)
+	}
+
+	img, err := DecodeImage(blob.Body, media.ContentType)
+	blob.Body.Close()
+	if err != nil {
+		return E(op, err)
+	}
+
+	media.Width, media.Height = img.Bounds().Dx(), img.Bounds().Dy()
+	media.Placeholder = DominantColor(img)
+	media.PerceptualHash = PerceptualHash(img)
+	media.Variants = make([]MediaVariant, 0, len(MediaVariantSizes))
+
+	var buf bytes.Buffer
+	for _, size := range MediaVariantSizes {
+		variant := FitImage(img, size.Size)
+		buf.Reset()
+		if err := jpeg.Encode(&buf, variant, &jpeg.Options{Quality: 82}); err != nil {
+			return E(op, err)
+		}
+
+		if err := p.blobStore.Put(ctx, VariantKey(media, size.Name), bytes.NewReader(buf.Bytes()), int64(buf.Len()), "image/jpeg"); err != nil {
+			return E(op, err)
+		}
+
+		media.Variants = append(media.Variants, MediaVariant{
+			Name:   size.Name,
+			Width:  variant.Bounds().Dx(),
+			Height: variant.Bounds().Dy(),
+		})
+	}
+
+	if err := p.mediaService.SaveVariants(ctx, media); err != nil {
+		return E(op, err)
+	}
+
+	return nil
+}
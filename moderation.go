@@ -0,0 +1,81 @@
+package yeahapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type ModerationDecisionKind string
+
+const (
+	ModerationApproved ModerationDecisionKind = "APPROVED"
+	ModerationRejected ModerationDecisionKind = "REJECTED"
+)
+
+// ModerationLease is how long a moderator holds a claimed queue item before
+// it becomes available to other moderators again.
+const ModerationLease = 10 * time.Minute
+
+type ModerationReason struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type ModerationItem struct {
+	ListingID      uuid.UUID  `json:"listing_id"`
+	SubmittedAt    time.Time  `json:"submitted_at"`
+	Score          float64    `json:"score"`
+	ModeratorID    *UserID    `json:"moderator_id"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at"`
+}
+
+// ModerationDecision is made either by a moderator holding a lease on the
+// listing or automatically by content checks, in which case ModeratorID is
+// empty.
+type ModerationDecision struct {
+	ID          uuid.UUID              `json:"id"`
+	ListingID   uuid.UUID              `json:"listing_id"`
+	ModeratorID UserID                 `json:"moderator_id"`
+	Automated   bool                   `json:"automated"`
+	Kind        ModerationDecisionKind `json:"kind"`
+	ReasonCode  string                 `json:"reason_code"`
+	Comment     string                 `json:"comment"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+type ModerationService interface {
+	IsModerator(ctx context.Context, userID UserID) (bool, error)
+	Enqueue(ctx context.Context, listingID uuid.UUID, score float64) error
+	Queue(ctx context.Context, limit int) ([]ModerationItem, error)
+	Claim(ctx context.Context, moderatorID UserID, lease time.Duration) (*ModerationItem, error)
+	Release(ctx context.Context, listingID uuid.UUID, moderatorID UserID) error
+	Decide(ctx context.Context, decision *ModerationDecision) (*ModerationDecision, error)
+	Decisions(ctx context.Context, listingID uuid.UUID) ([]ModerationDecision, error)
+	Reasons(ctx context.Context, lang string) ([]ModerationReason, error)
+	SaveCheckResults(ctx context.Context, listingID uuid.UUID, results []CheckResult) error
+	CheckResults(ctx context.Context, listingID uuid.UUID) ([]CheckResult, error)
+}
+
+// ListingStatus returns the status a listing moves to once the decision is
+// applied.
+func (k ModerationDecisionKind) ListingStatus() ListingStatus {
+	if k == ModerationApproved {
+		return ListingStatusIndexing
+	}
+	return ListingStatusDraft
+}
+
+func (d *ModerationDecision) Ok() error {
+	if d.ListingID.IsNil() {
+		return E(EInvalid, "Listing id is required")
+	} else if d.ModeratorID.IsNil() && !d.Automated {
+		return E(EInvalid, "Moderator id is required")
+	} else if d.Kind != ModerationApproved && d.Kind != ModerationRejected {
+		return E(EInvalid, "Unsupported moderation decision")
+	} else if d.Kind == ModerationRejected && d.ReasonCode == "" {
+		return E(EInvalid, "Reason is required to reject a listing")
+	}
+	return nil
+}
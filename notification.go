@@ -0,0 +1,46 @@
+package yeahapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type NotificationKind string
+
+const (
+	NotificationListingRejected      NotificationKind = "listing_rejected"
+	NotificationFavoritePriceDropped NotificationKind = "favorite_price_dropped"
+	NotificationFavoriteArchived     NotificationKind = "favorite_archived"
+	NotificationFavoriteSoldOut      NotificationKind = "favorite_sold_out"
+	NotificationSavedSearchMatches   NotificationKind = "saved_search_matches"
+	NotificationListingExpiring      NotificationKind = "listing_expiring"
+	NotificationReportResolved       NotificationKind = "report_resolved"
+)
+
+type NotificationPayload map[string]interface{}
+
+type Notification struct {
+	ID        uuid.UUID           `json:"id"`
+	UserID    UserID              `json:"-"`
+	Kind      NotificationKind    `json:"kind"`
+	Payload   NotificationPayload `json:"payload"`
+	Read      bool                `json:"read"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+type NotificationService interface {
+	CreateNotification(ctx context.Context, notification *Notification) (*Notification, error)
+	Notifications(ctx context.Context, userID UserID, limit int) ([]Notification, error)
+	MarkRead(ctx context.Context, userID UserID, ids []uuid.UUID) error
+}
+
+func (n *Notification) Ok() error {
+	if n.UserID.IsNil() {
+		return E(EInvalid, "User id is required")
+	} else if n.Kind == "" {
+		return E(EInvalid, "Notification kind is required")
+	}
+	return nil
+}
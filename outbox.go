@@ -0,0 +1,107 @@
+package yeahapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OutboxMessage is an event stored along with the change it announces, waiting
+// to be published.
+type OutboxMessage struct {
+	ID    int64
+	Topic string
+	Data  json.RawMessage
+}
+
+func (m OutboxMessage) Subject() string {
+	return m.Topic
+}
+
+// MarshalJSON returns the event as it was stored.
+func (m OutboxMessage) MarshalJSON() ([]byte, error) {
+	return m.Data, nil
+}
+
+type OutboxService interface {
+	// PendingMessages returns up to limit messages that weren't published
+	// yet, oldest first.
+	PendingMessages(ctx context.Context, limit int) ([]OutboxMessage, error)
+	// DeleteMessages removes published messages.
+	DeleteMessages(ctx context.Context, ids []int64) error
+}
+
+// OutboxRelay publishes the events stored in the outbox. Only one replica
+// relays at a time, so events are published in the order they were stored.
+// An event may be published more than once when deleting it fails.
+type OutboxRelay struct {
+	Interval  time.Duration
+	BatchSize int
+
+	outboxService OutboxService
+	locker        Locker
+	cqrsService   CQRSService
+}
+
+func NewOutboxRelay(outboxService OutboxService, locker Locker, cqrsService CQRSService) *OutboxRelay {
+	return &OutboxRelay{
+		Interval:      time.Second,
+		BatchSize:     100,
+		outboxService: outboxService,
+		locker:        locker,
+		cqrsService:   cqrsService,
+	}
+}
+
+// Run blocks until ctx is done.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.locker.TryLock(ctx, "outbox.relay", r.relay); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+func (r *OutboxRelay) relay(ctx context.Context) error {
+	const op Op = "OutboxRelay.relay"
+	for {
+		messages, err := r.outboxService.PendingMessages(ctx, r.BatchSize)
+		if err != nil {
+			return E(op, err)
+		}
+
+		// Publishing stops at the first failure so that later events don't
+		// overtake it.
+		var publishErr error
+		published := make([]int64, 0, len(messages))
+		for _, m := range messages {
+			if publishErr = r.cqrsService.Publish(ctx, m); publishErr != nil {
+				break
+			}
+			published = append(published, m.ID)
+		}
+
+		if len(published) > 0 {
+			if err := r.outboxService.DeleteMessages(ctx, published); err != nil {
+				return E(op, err)
+			}
+		}
+
+		if publishErr != nil {
+			return E(op, publishErr)
+		}
+
+		if len(messages) < r.BatchSize {
+			return nil
+		}
+	}
+}
@@ -20,7 +20,11 @@ func NewCategoryService(pool *pgxpool.Pool) *CategoryService {
 func (s *CategoryService) CreateCategory(ctx context.Context, category *yeahapi.Category) (*yeahapi.Category, error) {
 	const op yeahapi.Op = "postgres/CategoryService.CreateCategory"
 
-	if err := s.pool.QueryRow(ctx, "insert into categories (parent_id) values ($1) returning id", category.ParentID).Scan(&category.ID); err != nil {
+	if err := category.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := s.pool.QueryRow(ctx, "insert into categories (parent_id, lifetime_days) values ($1, nullif($2, 0)) returning id", category.ParentID, category.LifetimeDays).Scan(&category.ID); err != nil {
 		return nil, yeahapi.E(op, err)
 	}
 	return category, nil
@@ -30,7 +34,7 @@ func (s *CategoryService) Categories(ctx context.Context, lang string) ([]yeahap
 	const op yeahapi.Op = "postgres/CategoryService.Categories"
 	categories := make([]yeahapi.Category, 0)
 
-	rows, err := s.pool.Query(ctx, "select c.id, coalesce(c.parent_id, 0), ct.title, ct.description from categories c left join categories_tr ct on ct.category_id = c.id and ct.lang_code = $1", lang)
+	rows, err := s.pool.Query(ctx, "select c.id, coalesce(c.parent_id, 0), ct.title, ct.description, coalesce(c.lifetime_days, 0) from categories c left join categories_tr ct on ct.category_id = c.id and ct.lang_code = $1", lang)
 
 	defer rows.Close()
 	if err != nil {
@@ -39,7 +43,7 @@ func (s *CategoryService) Categories(ctx context.Context, lang string) ([]yeahap
 
 	for rows.Next() {
 		var c yeahapi.Category
-		if err := rows.Scan(&c.ID, &c.ParentID, &c.Title, &c.Description); err != nil {
+		if err := rows.Scan(&c.ID, &c.ParentID, &c.Title, &c.Description, &c.LifetimeDays); err != nil {
 			return nil, yeahapi.E(op, err)
 		}
 		categories = append(categories, c)
@@ -83,13 +87,15 @@ func (s *CategoryService) Attributes(ctx context.Context, categoryID string, lan
 	const op yeahapi.Op = "postgres/CategoryService.Attributes"
 
 	rows, err := s.pool.Query(ctx,
-		`select a.id, a.required, a.enabled_for_variations, a.key, a.category_id, at.name,
-		ao.id as option_id, coalesce(aot.name, ao.value) as option_name, ao.value as option_value, ao.unit as option_unit, ao.attribute_id as option_attribute_id
+		`select a.id, a.required, a.enabled_for_variations, a.key, a.category_id, coalesce(at.name, a.key),
+		coalesce(ao.id, 0) as option_id, coalesce(aot.name, ao.value, '') as option_name, coalesce(ao.value, '') as option_value,
+		coalesce(ao.unit, '') as option_unit, coalesce(ao.attribute_id, 0) as option_attribute_id
 		from attributes a
 		left join attributes_tr at on at.attribute_id = a.id and at.lang_code = $1
 		left join attribute_options ao on ao.attribute_id = a.id
 		left join attribute_options_tr aot on aot.attribute_option_id = ao.id and aot.lang_code = $1
 		where a.category_id = $2
+		order by a.id, ao.id
 		`,
 		lang, categoryID)
 
@@ -125,7 +131,9 @@ func (s *CategoryService) Attributes(ctx context.Context, categoryID string, lan
 			attributes = append(attributes, currentAtr)
 		}
 
-		currentAtr.Options = append(currentAtr.Options, opt)
+		if opt.ID != 0 {
+			currentAtr.Options = append(currentAtr.Options, opt)
+		}
 	}
 
 	return attributes, nil
@@ -2,6 +2,7 @@ package postgres_test
 
 import (
 	"context"
+	"strconv"
 	"testing"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -19,7 +20,49 @@ func TestCategoryService_Categories(t *testing.T) {
 }
 
 func TestCategoryService_Attributes(t *testing.T) {
-	t.Run("OK", func(t *testing.T) {})
+	s := postgres.NewCategoryService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		category := MustCreateCategory(t, ctx, pool, &yeahapi.Category{})
+
+		var optionID int
+		if err := pool.QueryRow(ctx,
+			`with a as (insert into attributes (key, required, category_id) values ('ram', true, $1) returning id)
+			insert into attribute_options (value, unit, attribute_id) select '8', 'GB', id from a returning id`,
+			category.ID).Scan(&optionID); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := pool.Exec(ctx, "insert into attributes (key, category_id) values ('model', $1)", category.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		attributes, err := s.Attributes(ctx, strconv.Itoa(category.ID), "en")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(attributes) != 2 {
+			t.Fatalf("unexpected attributes: %#v", attributes)
+		} else if len(attributes[0].Options) != 1 || attributes[0].Options[0].ID != optionID {
+			t.Fatalf("unexpected options: %#v", attributes[0].Options)
+		} else if attributes[1].Key != "model" || len(attributes[1].Options) != 0 {
+			t.Fatalf("unexpected attribute: %#v", attributes[1])
+		}
+
+		attrs, err := yeahapi.ValidateAttrs(yeahapi.ListingAttrs{"ram": float64(optionID), "model": "X1"}, attributes)
+		if err != nil {
+			t.Fatal(err)
+		} else if attrs["ram"] != "8" {
+			t.Fatalf("mismatch: %v != %v", attrs["ram"], "8")
+		}
+
+		_, err = yeahapi.ValidateAttrs(yeahapi.ListingAttrs{"color": "red"}, attributes)
+		if fields := yeahapi.ErrorFields(err); len(fields) != 2 || fields["attrs.ram"] == "" || fields["attrs.color"] == "" {
+			t.Fatalf("unexpected fields: %#v", fields)
+		}
+	})
 }
 
 func MustCreateCategory(tb testing.TB, ctx context.Context, pool *pgxpool.Pool, category *yeahapi.Category) *yeahapi.Category {
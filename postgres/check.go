@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+// BannedWordsCheck rejects listings whose titles or descriptions contain
+// words from the banned_words lists. Texts are matched against every language
+// since sellers freely mix them.
+type BannedWordsCheck struct {
+	pool *pgxpool.Pool
+}
+
+func NewBannedWordsCheck(pool *pgxpool.Pool) *BannedWordsCheck {
+	return &BannedWordsCheck{
+		pool: pool,
+	}
+}
+
+func (c *BannedWordsCheck) Name() string {
+	return "banned_words"
+}
+
+func (c *BannedWordsCheck) Check(ctx context.Context, listing *yeahapi.Listing, skus []yeahapi.ListingSku) (*yeahapi.CheckResult, error) {
+	const op yeahapi.Op = "postgres/BannedWordsCheck.Check"
+	fields := []struct{ name, text string }{
+		{"title", listing.Title},
+		{"description", yeahapi.DescriptionText(listing.Description)},
+	}
+
+	for _, field := range fields {
+		if field.text == "" {
+			continue
+		}
+
+		var lang, word string
+		err := c.pool.QueryRow(ctx,
+			`select lang_code, word from banned_words
+			where $1 ~* ('\m' || regexp_replace(word, '([.^$*+?()\[\]{}|\\])', '\\\1', 'g') || '\M') limit 1`,
+			field.text).Scan(&lang, &word)
+
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return nil, yeahapi.E(op, err)
+		}
+
+		return &yeahapi.CheckResult{
+			Score:      1,
+			ReasonCode: "PROHIBITED_ITEM",
+			Detail:     fmt.Sprintf("Banned word %q (%s) in %s", word, lang, field.name),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// PriceOutlierCheck flags skus priced far outside of what active listings in
+// the same category and currency cost. Categories with too few listings to
+// tell are skipped.
+type PriceOutlierCheck struct {
+	pool *pgxpool.Pool
+
+	// MinSamples is the number of priced skus a category needs before prices
+	// are compared.
+	MinSamples int
+	// Factor is how far below the 5th or above the 95th percentile a price has
+	// to be to count as an outlier.
+	Factor float64
+}
+
+func NewPriceOutlierCheck(pool *pgxpool.Pool) *PriceOutlierCheck {
+	return &PriceOutlierCheck{
+		pool:       pool,
+		MinSamples: 20,
+		Factor:     3,
+	}
+}
+
+func (c *PriceOutlierCheck) Name() string {
+	return "price_outlier"
+}
+
+func (c *PriceOutlierCheck) Check(ctx context.Context, listing *yeahapi.Listing, skus []yeahapi.ListingSku) (*yeahapi.CheckResult, error) {
+	const op yeahapi.Op = "postgres/PriceOutlierCheck.Check"
+	for _, sku := range skus {
+		var samples int
+		var low, high float64
+		err := c.pool.QueryRow(ctx,
+			`select count(*), coalesce(percentile_cont(0.05) within group (order by s.price), 0),
+			coalesce(percentile_cont(0.95) within group (order by s.price), 0)
+			from effective_sku_prices s join listings l on l.id = s.listing_id
+			where l.category_id = $1 and l.status = $2 and s.price_currency = $3 and s.price > 0`,
+			listing.CategoryID, yeahapi.ListingStatusActive, sku.PriceCurrency,
+		).Scan(&samples, &low, &high)
+
+		if err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+
+		if samples < c.MinSamples {
+			continue
+		}
+
+		price := float64(sku.Price)
+		if price*c.Factor < low || price > high*c.Factor {
+			return &yeahapi.CheckResult{
+				Score:      0.5,
+				ReasonCode: "INVALID_PRICE",
+				Detail:     fmt.Sprintf("Price %d %s is outside of the usual %.0f-%.0f range", sku.Price, sku.PriceCurrency, low, high),
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// DuplicateCheck flags listings that look like reposts: the owner has another
+// listing with a near-identical title or a copy of one of its photos. Photos
+// copied from listings of other owners are flagged as suspected fraud, since
+// scammers reuse photos of real items. Titles alone aren't compared across
+// owners, many people sell the same things under the same titles.
+//
+// Photos are only compared with the ones sharing a band of their hash, so
+// copies differing in more bits than a recompressed photo does may be missed.
+type DuplicateCheck struct {
+	pool *pgxpool.Pool
+}
+
+func NewDuplicateCheck(pool *pgxpool.Pool) *DuplicateCheck {
+	return &DuplicateCheck{
+		pool: pool,
+	}
+}
+
+func (c *DuplicateCheck) Name() string {
+	return "duplicate"
+}
+
+func (c *DuplicateCheck) Check(ctx context.Context, listing *yeahapi.Listing, skus []yeahapi.ListingSku) (*yeahapi.CheckResult, error) {
+	const op yeahapi.Op = "postgres/DuplicateCheck.Check"
+	var id string
+	err := c.pool.QueryRow(ctx,
+		`select l.id from listing_media lm
+		join listing_media m on m.phash_bands && lm.phash_bands and m.listing_id <> lm.listing_id
+			and bit_count((m.phash # lm.phash)::bit(64)) <= $3
+		join listings l on l.id = m.listing_id and l.owner_id <> $2 and l.status <> $4
+		where lm.listing_id = $1 limit 1`,
+		listing.ID, listing.OwnerID, yeahapi.MaxPhotoDistance, yeahapi.ListingStatusDeleted,
+	).Scan(&id)
+
+	if err == nil {
+		return &yeahapi.CheckResult{
+			Score:      0.8,
+			ReasonCode: "SUSPECTED_FRAUD",
+			Detail:     fmt.Sprintf("Same photos as listing %s of another owner", id),
+		}, nil
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, yeahapi.E(op, err)
+	}
+
+	err = c.pool.QueryRow(ctx,
+		`select l.id from listings l where l.owner_id = $2 and l.id <> $1 and l.status <> $3 and (
+			bit_count((l.title_simhash # $4)::bit(64)) <= $5
+			or exists (
+				select 1 from listing_media m join listing_media lm on lm.listing_id = $1
+				where m.listing_id = l.id and m.phash_bands && lm.phash_bands and bit_count((m.phash # lm.phash)::bit(64)) <= $6
+			)
+		) order by l.id desc limit 1`,
+		listing.ID, listing.OwnerID, yeahapi.ListingStatusDeleted, yeahapi.TitleSimhash(listing.Title),
+		yeahapi.MaxTitleDistance, yeahapi.MaxPhotoDistance,
+	).Scan(&id)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	return &yeahapi.CheckResult{
+		Score:      0.7,
+		ReasonCode: "DUPLICATE",
+		Detail:     fmt.Sprintf("Looks like listing %s of the same owner", id),
+	}, nil
+}
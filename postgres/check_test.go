@@ -0,0 +1,159 @@
+package postgres_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestBannedWordsCheck_Check(t *testing.T) {
+	c := postgres.NewBannedWordsCheck(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		result, err := c.Check(context.Background(), &yeahapi.Listing{Title: "iPhone 14 Pro Max"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if result != nil {
+			t.Fatalf("unexpected result: %#v", result)
+		}
+	})
+
+	t.Run("Banned", func(t *testing.T) {
+		result, err := c.Check(context.Background(), &yeahapi.Listing{Title: "Продам оружие недорого"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if result == nil || result.ReasonCode != "PROHIBITED_ITEM" {
+			t.Fatalf("unexpected result: %#v", result)
+		}
+	})
+
+	t.Run("Description", func(t *testing.T) {
+		result, err := c.Check(context.Background(), &yeahapi.Listing{
+			Title:       "Hunting gear",
+			Description: "<p>Comes with a <b>firearm</b></p>",
+		}, nil)
+
+		if err != nil {
+			t.Fatal(err)
+		} else if result == nil || result.ReasonCode != "PROHIBITED_ITEM" {
+			t.Fatalf("unexpected result: %#v", result)
+		}
+	})
+
+	t.Run("Escaped", func(t *testing.T) {
+		ctx := context.Background()
+		if _, err := pool.Exec(ctx, "insert into banned_words (lang_code, word) values ('en', 'x.ray') on conflict do nothing"); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := c.Check(ctx, &yeahapi.Listing{Title: "Xeray glasses"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if result != nil {
+			t.Fatalf("unexpected result: %#v", result)
+		}
+
+		result, err = c.Check(ctx, &yeahapi.Listing{Title: "X.ray glasses"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if result == nil {
+			t.Fatal("expected banned word to be found")
+		}
+	})
+}
+
+func TestDuplicateCheck_Check(t *testing.T) {
+	c := postgres.NewDuplicateCheck(pool)
+	s := postgres.NewListingService(pool)
+
+	t.Run("SameOwnerTitle", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Chevrolet Cobalt 2020")
+
+		other, err := s.CreateListing(ctx, &yeahapi.Listing{
+			Title:      "chevrolet cobalt, 2021!",
+			OwnerID:    listing.OwnerID,
+			CategoryID: listing.CategoryID,
+			Status:     yeahapi.ListingStatusDraft,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := c.Check(ctx, other, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if result == nil || result.ReasonCode != "DUPLICATE" {
+			t.Fatalf("unexpected result: %#v", result)
+		}
+	})
+
+	t.Run("OtherOwnerTitle", func(t *testing.T) {
+		ctx := context.Background()
+		MustCreateActiveListing(t, ctx, pool, "Chevrolet Spark 2019")
+		other := MustCreateActiveListing(t, ctx, pool, "Chevrolet Spark 2019")
+
+		// Everyone sells the same cars, titles alone don't tell anything.
+		result, err := c.Check(ctx, other, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if result != nil {
+			t.Fatalf("unexpected result: %#v", result)
+		}
+	})
+
+	t.Run("OtherOwnerPhoto", func(t *testing.T) {
+		ctx := context.Background()
+		ms := postgres.NewMediaService(pool)
+		listing := MustCreateActiveListing(t, ctx, pool, "Sofa")
+		other := MustCreateActiveListing(t, ctx, pool, "Corner sofa")
+
+		// The copy differs from the original in a couple of bits, like a
+		// recompressed photo does.
+		hash := rand.Int63()
+		MustSetPhotoHash(t, ctx, MustCreateMedia(t, ctx, ms, listing), hash)
+		MustSetPhotoHash(t, ctx, MustCreateMedia(t, ctx, ms, other), hash^0b101)
+
+		result, err := c.Check(ctx, other, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if result == nil || result.ReasonCode != "SUSPECTED_FRAUD" {
+			t.Fatalf("unexpected result: %#v", result)
+		}
+	})
+}
+
+func MustSetPhotoHash(tb testing.TB, ctx context.Context, media *yeahapi.Media, hash int64) {
+	tb.Helper()
+	if _, err := pool.Exec(ctx, "update listing_media set phash = $1 where id = $2", hash, media.ID); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func TestModerationService_SaveCheckResults(t *testing.T) {
+	s := postgres.NewModerationService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		results := []yeahapi.CheckResult{
+			{Check: "duplicate_title", Score: 0.7, ReasonCode: "DUPLICATE"},
+			{Check: "price_outlier", Score: 0.5, ReasonCode: "INVALID_PRICE"},
+		}
+
+		if err := s.SaveCheckResults(ctx, listing.ID, results); err != nil {
+			t.Fatal(err)
+		}
+
+		other, err := s.CheckResults(ctx, listing.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(other) != 2 || other[0].Check != "duplicate_title" {
+			t.Fatalf("unexpected results: %#v", other)
+		}
+	})
+}
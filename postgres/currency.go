@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type CurrencyService struct {
+	pool *pgxpool.Pool
+}
+
+func NewCurrencyService(pool *pgxpool.Pool) *CurrencyService {
+	return &CurrencyService{
+		pool: pool,
+	}
+}
+
+func (s *CurrencyService) Currencies(ctx context.Context) ([]yeahapi.CurrencyInfo, error) {
+	const op yeahapi.Op = "postgres/CurrencyService.Currencies"
+	rows, err := s.pool.Query(ctx, "select code, coalesce(symbol, ''), minor_units from currencies order by code")
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	currencies := make([]yeahapi.CurrencyInfo, 0)
+	for rows.Next() {
+		var c yeahapi.CurrencyInfo
+		if err := rows.Scan(&c.Code, &c.Symbol, &c.MinorUnits); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		currencies = append(currencies, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return currencies, nil
+}
+
+func (s *CurrencyService) ExchangeRate(ctx context.Context, base, quote yeahapi.Currency) (*yeahapi.ExchangeRate, error) {
+	const op yeahapi.Op = "postgres/CurrencyService.ExchangeRate"
+	rate := &yeahapi.ExchangeRate{Base: base, Quote: quote}
+
+	// Derived rates are dated by the oldest rate they are made of.
+	err := s.pool.QueryRow(ctx,
+		`select exchange_rate($1, $2)::text, coalesce(min(date), current_date),
+		coalesce(string_agg(distinct source, ','), '')
+		from latest_exchange_rates
+		where ($1 <> $2) and (base in ($1, $2) or quote in ($1, $2))
+		having exchange_rate($1, $2) is not null`,
+		base, quote).Scan(&rate.Rate, &rate.Date, &rate.Source)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound)
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	return rate, nil
+}
+
+func (s *CurrencyService) SaveRates(ctx context.Context, rates []yeahapi.ExchangeRate) (int, error) {
+	const op yeahapi.Op = "postgres/CurrencyService.SaveRates"
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	saved := 0
+	for _, rate := range rates {
+		tag, err := tx.Exec(ctx,
+			`insert into exchange_rates (base, quote, rate, date, source)
+			select $1, $2, $3::numeric, $4, $5
+			where exists (select 1 from currencies where code = $1) and exists (select 1 from currencies where code = $2)
+			on conflict (base, quote, date) do update set rate = excluded.rate, source = excluded.source`,
+			rate.Base, rate.Quote, rate.Rate, rate.Date, rate.Source)
+
+		if err != nil {
+			return 0, yeahapi.E(op, err)
+		}
+
+		saved += int(tag.RowsAffected())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, yeahapi.E(op, err)
+	}
+
+	return saved, nil
+}
+
+// unknownCurrency reports whether err is a violation of a foreign key to the
+// currencies table, i.e. a price was given in a currency we don't know.
+func unknownCurrency(err error) bool {
+	var pgerr *pgconn.PgError
+	return errors.As(err, &pgerr) && pgerr.Code == pgerrcode.ForeignKeyViolation && strings.HasSuffix(pgerr.ConstraintName, "currency_fkey")
+}
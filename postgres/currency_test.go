@@ -0,0 +1,76 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestCurrencyService_ExchangeRate(t *testing.T) {
+	s := postgres.NewCurrencyService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+		saved, err := s.SaveRates(ctx, []yeahapi.ExchangeRate{
+			{Base: yeahapi.CurrencyUSD, Quote: yeahapi.CurrencyUZS, Rate: "12345.67", Date: date, Source: "test"},
+			{Base: "EUR", Quote: yeahapi.CurrencyUZS, Rate: "13530.48", Date: date, Source: "test"},
+			{Base: "XXX", Quote: yeahapi.CurrencyUZS, Rate: "1", Date: date, Source: "test"},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		} else if saved != 2 {
+			t.Fatalf("mismatch: %d != %d", saved, 2)
+		}
+
+		currencies, err := s.Currencies(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		known := make(map[yeahapi.Currency]*yeahapi.CurrencyInfo)
+		for i := range currencies {
+			known[currencies[i].Code] = &currencies[i]
+		}
+
+		for _, tc := range []struct {
+			from, to yeahapi.Currency
+			amount   int
+			want     int
+		}{
+			{yeahapi.CurrencyUSD, yeahapi.CurrencyUZS, 100, 1234567},
+			{yeahapi.CurrencyUZS, yeahapi.CurrencyUSD, 1234567, 100},
+			{"EUR", yeahapi.CurrencyUSD, 1000, 1096},
+			{"EUR", yeahapi.CurrencyUSD, -1000, -1096},
+		} {
+			rate, err := s.ExchangeRate(ctx, tc.from, tc.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := yeahapi.ConvertAmount(tc.amount, rate, known[tc.from], known[tc.to])
+			if err != nil {
+				t.Fatal(err)
+			} else if got != tc.want {
+				t.Fatalf("%d %s to %s: %d != %d", tc.amount, tc.from, tc.to, got, tc.want)
+			}
+
+			var converted int
+			if err := pool.QueryRow(ctx, "select convert_amount($1, $2, $3)", tc.amount, tc.from, tc.to).Scan(&converted); err != nil {
+				t.Fatal(err)
+			} else if converted != got {
+				t.Fatalf("%d %s to %s: database and Go disagree: %d != %d", tc.amount, tc.from, tc.to, converted, got)
+			}
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		if _, err := s.ExchangeRate(context.Background(), "KRW", "KGS"); !yeahapi.EIs(yeahapi.ENotFound, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type ExpiryService struct {
+	pool *pgxpool.Pool
+}
+
+func NewExpiryService(pool *pgxpool.Pool) *ExpiryService {
+	return &ExpiryService{
+		pool: pool,
+	}
+}
+
+func (s *ExpiryService) ExpireListings(ctx context.Context, now time.Time) ([]yeahapi.Listing, error) {
+	const op yeahapi.Op = "postgres/ExpiryService.ExpireListings"
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx,
+		`update listings set status = $2 from listing_expiry e
+		where e.listing_id = listings.id and listings.status = $3 and e.expires_at <= $1
+		returning `+listingReturning, now, yeahapi.ListingStatusArchived, yeahapi.ListingStatusActive)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	listings, err := scanListings(rows)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for i := range listings {
+		if err := enqueue(ctx, tx, yeahapi.NewListingStatusChangedEvent(&listings[i], yeahapi.ListingStatusActive)); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return listings, nil
+}
+
+func (s *ExpiryService) WarnListings(ctx context.Context, before time.Time) ([]yeahapi.Listing, error) {
+	const op yeahapi.Op = "postgres/ExpiryService.WarnListings"
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx,
+		`with warned as (
+			update listing_expiry e set warned_at = now() from listings l
+			where l.id = e.listing_id and l.status = $2 and e.warned_at is null and e.expires_at <= $1
+			returning e.listing_id
+		)
+		select `+listingColumns+`, coalesce(st.quantity = 0, false)
+		from warned w
+		join listings l on l.id = w.listing_id
+		left join listings_tr tr on false
+		left join listing_stock st on st.listing_id = l.id`, before, yeahapi.ListingStatusActive)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	listings, err := scanListings(rows)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for i := range listings {
+		if err := enqueue(ctx, tx, yeahapi.NewListingExpiringEvent(&listings[i])); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return listings, nil
+}
+
+// RenewListing gives an active listing the full lifetime of its category
+// from now on.
+func (s *ExpiryService) RenewListing(ctx context.Context, id uuid.UUID) (*yeahapi.Listing, error) {
+	const op yeahapi.Op = "postgres/ExpiryService.RenewListing"
+	var expiresAt time.Time
+	err := s.pool.QueryRow(ctx,
+		`update listing_expiry e set expires_at = now() + listing_lifetime(l.category_id), warned_at = null
+		from listings l where l.id = e.listing_id and e.listing_id = $1 and l.status = $2
+		returning e.expires_at`, id, yeahapi.ListingStatusActive).Scan(&expiresAt)
+
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, err)
+		}
+		if _, err := NewListingService(s.pool).Listing(ctx, id); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		return nil, yeahapi.E(op, yeahapi.EInvalid, "Only active listings can be renewed")
+	}
+
+	listing, err := NewListingService(s.pool).Listing(ctx, id)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return listing, nil
+}
+
+// scanListings scans rows of listingColumns or listingReturning followed by
+// whether the listing is sold out.
+func scanListings(rows pgx.Rows) ([]yeahapi.Listing, error) {
+	listings := make([]yeahapi.Listing, 0)
+	for rows.Next() {
+		var l yeahapi.Listing
+		if err := rows.Scan(append(listingFields(&l), &l.SoldOut)...); err != nil {
+			return nil, err
+		}
+		listings = append(listings, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return listings, nil
+}
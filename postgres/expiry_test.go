@@ -0,0 +1,134 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestExpiryService_ExpireListings(t *testing.T) {
+	s := postgres.NewExpiryService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		if got, err := postgres.NewListingService(pool).Listing(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if got.ExpiresAt == nil || got.ExpiresAt.Sub(time.Now()) < 29*24*time.Hour {
+			t.Fatalf("unexpected expiry: %v", got.ExpiresAt)
+		}
+
+		// Owners are warned once.
+		soon := time.Now().Add(30*24*time.Hour + time.Minute)
+		if warned, err := s.WarnListings(ctx, soon); err != nil {
+			t.Fatal(err)
+		} else if !containsListing(warned, listing.ID) {
+			t.Fatalf("listing wasn't warned about: %#v", warned)
+		}
+		if warned, err := s.WarnListings(ctx, soon); err != nil {
+			t.Fatal(err)
+		} else if containsListing(warned, listing.ID) {
+			t.Fatalf("listing was warned about twice: %#v", warned)
+		}
+		MustFindOutboxMessage(t, ctx, yeahapi.ListingExpiring, listing.ID)
+
+		expired, err := s.ExpireListings(ctx, soon)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !containsListing(expired, listing.ID) {
+			t.Fatalf("listing didn't expire: %#v", expired)
+		}
+
+		if got, err := postgres.NewListingService(pool).Listing(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if got.Status != yeahapi.ListingStatusArchived {
+			t.Fatalf("unexpected status: %s", got.Status)
+		}
+		MustFindOutboxMessage(t, ctx, yeahapi.ListingArchived, listing.ID)
+	})
+
+	t.Run("CategoryLifetime", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		parent := MustCreateCategory(t, ctx, pool, &yeahapi.Category{LifetimeDays: 7})
+		category := MustCreateCategory(t, ctx, pool, &yeahapi.Category{ParentID: &parent.ID})
+		if _, err := pool.Exec(ctx, "update listings set category_id = $1, status = $2 where id = $3", category.ID, yeahapi.ListingStatusActive, listing.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if expired, err := s.ExpireListings(ctx, time.Now().Add(8*24*time.Hour)); err != nil {
+			t.Fatal(err)
+		} else if !containsListing(expired, listing.ID) {
+			t.Fatalf("listing didn't expire: %#v", expired)
+		}
+	})
+}
+
+func TestExpiryService_RenewListing(t *testing.T) {
+	s := postgres.NewExpiryService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		if _, err := pool.Exec(ctx, "update listing_expiry set expires_at = now() + interval '1 day' where listing_id = $1", listing.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		renewed, err := s.RenewListing(ctx, listing.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if renewed.ExpiresAt == nil || renewed.ExpiresAt.Sub(time.Now()) < 29*24*time.Hour {
+			t.Fatalf("unexpected expiry: %v", renewed.ExpiresAt)
+		}
+	})
+
+	t.Run("ErrNotActive", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		if _, err := s.RenewListing(ctx, listing.ID); !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+}
+
+func TestLocker_TryLock(t *testing.T) {
+	l := postgres.NewLocker(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		locked, err := l.TryLock(ctx, "test", func(ctx context.Context) error {
+			// Others can't take the lock while it is held.
+			locked, err := l.TryLock(ctx, "test", func(ctx context.Context) error {
+				t.Fatal("ran while locked")
+				return nil
+			})
+			if err != nil {
+				return err
+			} else if locked {
+				t.Fatal("locked twice")
+			}
+			return nil
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		} else if !locked {
+			t.Fatal("not locked")
+		}
+	})
+}
+
+func containsListing(listings []yeahapi.Listing, id uuid.UUID) bool {
+	for _, l := range listings {
+		if l.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type FavoriteService struct {
+	pool *pgxpool.Pool
+}
+
+func NewFavoriteService(pool *pgxpool.Pool) *FavoriteService {
+	return &FavoriteService{
+		pool: pool,
+	}
+}
+
+func (s *FavoriteService) AddFavorite(ctx context.Context, userID yeahapi.UserID, listingID uuid.UUID) error {
+	const op yeahapi.Op = "postgres/FavoriteService.AddFavorite"
+
+	var ownerID yeahapi.UserID
+	var status yeahapi.ListingStatus
+	err := s.pool.QueryRow(ctx, "select owner_id, status from listings where id = $1", listingID).Scan(&ownerID, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return yeahapi.E(op, yeahapi.ENotFound, "Listing not found")
+		}
+		return yeahapi.E(op, err)
+	}
+
+	if ownerID == userID {
+		return yeahapi.E(op, yeahapi.EInvalid, "You can't favorite your own listing")
+	} else if status != yeahapi.ListingStatusActive {
+		return yeahapi.E(op, yeahapi.ENotFound, "Listing not found")
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	_, err = s.pool.Exec(ctx,
+		"insert into favorites (id, user_id, listing_id) values ($1, $2, $3) on conflict (user_id, listing_id) do nothing",
+		id, userID, listingID)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *FavoriteService) RemoveFavorite(ctx context.Context, userID yeahapi.UserID, listingID uuid.UUID) error {
+	const op yeahapi.Op = "postgres/FavoriteService.RemoveFavorite"
+	_, err := s.pool.Exec(ctx, "delete from favorites where user_id = $1 and listing_id = $2", userID, listingID)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+	return nil
+}
+
+// Favorites leaves out deleted listings. Listings that were archived or sold
+// out since are kept so that users can see what happened to them.
+func (s *FavoriteService) Favorites(ctx context.Context, filter yeahapi.FavoriteFilter) (*yeahapi.FavoritePage, error) {
+	const op yeahapi.Op = "postgres/FavoriteService.Favorites"
+
+	if err := filter.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	page := &yeahapi.FavoritePage{Favorites: make([]yeahapi.Favorite, 0)}
+	err := s.pool.QueryRow(ctx,
+		`select count(*) from favorites f join listings l on l.id = f.listing_id
+		where f.user_id = $1 and l.status <> $2`, filter.UserID, yeahapi.ListingStatusDeleted).Scan(&page.TotalCount)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	var after *uuid.UUID
+	if !filter.After.IsNil() {
+		after = &filter.After
+	}
+
+	// Fetch one extra row to know whether there is a next page.
+	rows, err := s.pool.Query(ctx,
+		`select f.id, f.created_at, `+listingColumns+`, p.price, p.price_currency, coalesce(st.quantity = 0, false)
+		from favorites f
+		join listings l on l.id = f.listing_id
+		left join lateral (`+minPrice+`) p on true
+		left join listing_stock st on st.listing_id = l.id
+		left join listings_tr tr on tr.listing_id = l.id and tr.lang_code = $3
+		where f.user_id = $1 and l.status <> $2 and ($4::uuid is null or f.id < $4)
+		order by f.id desc limit $5`,
+		filter.UserID, yeahapi.ListingStatusDeleted, filter.Lang, after, filter.Limit+1)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var f yeahapi.Favorite
+		var price *int
+		var currency *yeahapi.Currency
+		fields := append([]interface{}{&f.ID, &f.CreatedAt}, listingFields(&f.Listing)...)
+		if err := rows.Scan(append(fields, &price, &currency, &f.Listing.SoldOut)...); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+
+		if price != nil {
+			f.Listing.MinPrice = &yeahapi.ListingPrice{Amount: *price, Currency: *currency}
+		}
+
+		page.Favorites = append(page.Favorites, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if len(page.Favorites) > filter.Limit {
+		page.Favorites = page.Favorites[:filter.Limit]
+		page.NextCursor = &page.Favorites[filter.Limit-1].ID
+	}
+
+	return page, nil
+}
+
+func (s *FavoriteService) Favorers(ctx context.Context, listingID uuid.UUID) ([]yeahapi.UserID, error) {
+	const op yeahapi.Op = "postgres/FavoriteService.Favorers"
+	users := make([]yeahapi.UserID, 0)
+
+	rows, err := s.pool.Query(ctx, "select user_id from favorites where listing_id = $1 order by id", listingID)
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var userID yeahapi.UserID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		users = append(users, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return users, nil
+}
+
+func (s *FavoriteService) Alert(ctx context.Context, key string, alert yeahapi.FavoriteAlert) error {
+	const op yeahapi.Op = "postgres/FavoriteService.Alert"
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx,
+		`insert into favorite_alerts (listing_id, event_key, user_id)
+		select listing_id, $2, user_id from favorites where listing_id = $1 order by id
+		on conflict do nothing returning user_id`, alert.ListingID, key)
+	defer rows.Close()
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	events := make([]yeahapi.CQRSMessage, 0)
+	for rows.Next() {
+		if err := rows.Scan(&alert.UserID); err != nil {
+			return yeahapi.E(op, err)
+		}
+		events = append(events, yeahapi.NewFavoriteAlertEvent(alert))
+	}
+
+	if err := rows.Err(); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	if err := enqueue(ctx, tx, events...); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
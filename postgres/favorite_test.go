@@ -0,0 +1,149 @@
+package postgres_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestFavoriteService_AddFavorite(t *testing.T) {
+	s := postgres.NewFavoriteService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		user := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+
+		// Favoriting twice keeps a single favorite.
+		for i := 0; i < 2; i++ {
+			if err := s.AddFavorite(ctx, user.ID, listing.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if got, err := postgres.NewListingService(pool).Listing(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if got.FavoriteCount != 1 {
+			t.Fatalf("unexpected favorite count: %d", got.FavoriteCount)
+		}
+
+		if users, err := s.Favorers(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if len(users) != 1 || users[0] != user.ID {
+			t.Fatalf("unexpected favorers: %#v", users)
+		}
+
+		if err := s.RemoveFavorite(ctx, user.ID, listing.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := postgres.NewListingService(pool).Listing(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if got.FavoriteCount != 0 {
+			t.Fatalf("unexpected favorite count: %d", got.FavoriteCount)
+		}
+	})
+
+	t.Run("ErrOwnListing", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+
+		if err := s.AddFavorite(ctx, listing.OwnerID, listing.ID); !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+
+	t.Run("ErrNotActive", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		user := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+
+		if err := s.AddFavorite(ctx, user.ID, listing.ID); !yeahapi.EIs(yeahapi.ENotFound, err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestFavoriteService_Favorites(t *testing.T) {
+	s := postgres.NewFavoriteService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		user := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+
+		listings := make([]*yeahapi.Listing, 3)
+		for i := range listings {
+			listings[i] = MustCreateActiveListing(t, ctx, pool, "Bicycle")
+			if err := s.AddFavorite(ctx, user.ID, listings[i].ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Deleted listings are left out, archived ones are kept.
+		if _, err := pool.Exec(ctx, "update listings set status = $1 where id = $2", yeahapi.ListingStatusDeleted, listings[0].ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := pool.Exec(ctx, "update listings set status = $1 where id = $2", yeahapi.ListingStatusArchived, listings[1].ID); err != nil {
+			t.Fatal(err)
+		}
+
+		page, err := s.Favorites(ctx, yeahapi.FavoriteFilter{UserID: user.ID, Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		} else if page.TotalCount != 2 || len(page.Favorites) != 1 || page.NextCursor == nil {
+			t.Fatalf("unexpected page: %#v", page)
+		} else if page.Favorites[0].Listing.ID != listings[2].ID || page.Favorites[0].Listing.FavoriteCount != 1 {
+			t.Fatalf("unexpected favorite: %#v", page.Favorites[0])
+		}
+
+		page, err = s.Favorites(ctx, yeahapi.FavoriteFilter{UserID: user.ID, After: *page.NextCursor, Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		} else if len(page.Favorites) != 1 || page.NextCursor != nil {
+			t.Fatalf("unexpected page: %#v", page)
+		} else if page.Favorites[0].Listing.Status != yeahapi.ListingStatusArchived {
+			t.Fatalf("unexpected favorite: %#v", page.Favorites[0])
+		}
+	})
+}
+
+func TestFavoriteService_Alert(t *testing.T) {
+	s := postgres.NewFavoriteService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		for i := 0; i < 2; i++ {
+			user := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+			if err := s.AddFavorite(ctx, user.ID, listing.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Alerting again about the same event alerts nobody twice.
+		alert := yeahapi.FavoriteAlert{ListingID: listing.ID, Kind: yeahapi.NotificationFavoriteSoldOut}
+		for i := 0; i < 2; i++ {
+			if err := s.Alert(ctx, "listings:1", alert); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		messages, err := postgres.NewOutboxService(pool).PendingMessages(ctx, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var alerts int
+		for _, m := range messages {
+			if m.Subject() == yeahapi.FavoriteAlerted && strings.Contains(string(m.Data), listing.ID.String()) {
+				alerts++
+			}
+		}
+		if alerts != 2 {
+			t.Fatalf("alerts=%d, want 2", alerts)
+		}
+	})
+}
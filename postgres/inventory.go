@@ -0,0 +1,323 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type InventoryService struct {
+	pool *pgxpool.Pool
+}
+
+func NewInventoryService(pool *pgxpool.Pool) *InventoryService {
+	return &InventoryService{
+		pool: pool,
+	}
+}
+
+func (s *InventoryService) AdjustStock(ctx context.Context, skuID uuid.UUID, delta int, reason yeahapi.StockReason, note string) (*yeahapi.StockAdjustment, error) {
+	const op yeahapi.Op = "postgres/InventoryService.AdjustStock"
+
+	switch {
+	case delta == 0:
+		return nil, yeahapi.E(op, yeahapi.EInvalid, "Stock change is required")
+	case reason == yeahapi.StockReasonRestock && delta < 0:
+		return nil, yeahapi.E(op, yeahapi.EInvalid, "Restock must add stock")
+	case reason != yeahapi.StockReasonRestock && reason != yeahapi.StockReasonCorrection:
+		return nil, yeahapi.E(op, yeahapi.EInvalid, "Stock can only be restocked or corrected")
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	listingID, _, err := lockSkuListing(ctx, tx, skuID)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	adjustment := &yeahapi.StockAdjustment{
+		SkuID:     skuID,
+		ListingID: listingID,
+		Delta:     delta,
+		Reason:    reason,
+		Note:      note,
+	}
+
+	if err := adjustStock(ctx, tx, adjustment); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return adjustment, nil
+}
+
+func (s *InventoryService) Reserve(ctx context.Context, skuID uuid.UUID, userID yeahapi.UserID, quantity int) (*yeahapi.StockReservation, *yeahapi.StockAdjustment, error) {
+	const op yeahapi.Op = "postgres/InventoryService.Reserve"
+
+	if quantity <= 0 {
+		return nil, nil, yeahapi.E(op, yeahapi.EInvalid, "Quantity must be positive")
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, nil, yeahapi.E(op, err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	listingID, status, err := lockSkuListing(ctx, tx, skuID)
+	if err != nil {
+		return nil, nil, yeahapi.E(op, err)
+	}
+
+	if status != yeahapi.ListingStatusActive {
+		return nil, nil, yeahapi.E(op, yeahapi.EInvalid, "Listing is not available")
+	}
+
+	// The listing is locked, so the buyer's other reservations can't change
+	// until this one is made.
+	var ownerID yeahapi.UserID
+	var held int
+	err = tx.QueryRow(ctx,
+		`select l.owner_id, coalesce((
+			select sum(r.quantity) from listing_sku_reservations r join listing_skus s on s.id = r.sku_id
+			where s.listing_id = l.id and r.user_id = $2 and r.released_at is null and r.expires_at > now()
+		), 0) from listings l where l.id = $1`, listingID, userID).Scan(&ownerID, &held)
+
+	if err != nil {
+		return nil, nil, yeahapi.E(op, err)
+	}
+
+	if ownerID == userID {
+		return nil, nil, yeahapi.E(op, yeahapi.EPermission, "You can't reserve your own listing")
+	}
+
+	if held+quantity > yeahapi.MaxReservedQuantity {
+		return nil, nil, yeahapi.E(op, yeahapi.EInvalid, fmt.Sprintf("At most %d items of a listing can be reserved at once", yeahapi.MaxReservedQuantity))
+	}
+
+	reservation := &yeahapi.StockReservation{ID: id, SkuID: skuID, UserID: userID, Quantity: quantity}
+	err = tx.QueryRow(ctx,
+		`insert into listing_sku_reservations (id, sku_id, user_id, quantity, expires_at) values ($1, $2, $3, $4, $5)
+		returning created_at, expires_at`,
+		reservation.ID, reservation.SkuID, reservation.UserID, reservation.Quantity, time.Now().Add(yeahapi.ReservationTTL),
+	).Scan(&reservation.CreatedAt, &reservation.ExpiresAt)
+
+	if err != nil {
+		return nil, nil, yeahapi.E(op, err)
+	}
+
+	adjustment := &yeahapi.StockAdjustment{
+		SkuID:         skuID,
+		ListingID:     listingID,
+		ReservationID: &reservation.ID,
+		Delta:         -quantity,
+		Reason:        yeahapi.StockReasonReservation,
+	}
+
+	if err := adjustStock(ctx, tx, adjustment); err != nil {
+		return nil, nil, yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, yeahapi.E(op, err)
+	}
+
+	return reservation, adjustment, nil
+}
+
+func (s *InventoryService) Release(ctx context.Context, reservationID uuid.UUID) (*yeahapi.StockReservation, *yeahapi.StockAdjustment, error) {
+	const op yeahapi.Op = "postgres/InventoryService.Release"
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// The listing is locked before the reservation, in the same order
+	// reservations are made in.
+	var skuID uuid.UUID
+	err = tx.QueryRow(ctx, "select sku_id from listing_sku_reservations where id = $1", reservationID).Scan(&skuID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, yeahapi.E(op, yeahapi.ENotFound)
+		}
+		return nil, nil, yeahapi.E(op, err)
+	}
+
+	listingID, _, err := lockSkuListing(ctx, tx, skuID)
+	if err != nil {
+		return nil, nil, yeahapi.E(op, err)
+	}
+
+	var reservation yeahapi.StockReservation
+	err = tx.QueryRow(ctx,
+		`update listing_sku_reservations set released_at = now() where id = $1 and released_at is null
+		returning id, sku_id, user_id, quantity, created_at, expires_at, released_at`, reservationID).Scan(
+		&reservation.ID, &reservation.SkuID, &reservation.UserID, &reservation.Quantity, &reservation.CreatedAt,
+		&reservation.ExpiresAt, &reservation.ReleasedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, yeahapi.E(op, yeahapi.EConflict, "Reservation is already released")
+		}
+		return nil, nil, yeahapi.E(op, err)
+	}
+
+	adjustment := &yeahapi.StockAdjustment{
+		SkuID:         skuID,
+		ListingID:     listingID,
+		ReservationID: &reservation.ID,
+		Delta:         reservation.Quantity,
+		Reason:        yeahapi.StockReasonRelease,
+	}
+
+	if err := adjustStock(ctx, tx, adjustment); err != nil {
+		return nil, nil, yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, yeahapi.E(op, err)
+	}
+
+	return &reservation, adjustment, nil
+}
+
+func (s *InventoryService) Reservation(ctx context.Context, id uuid.UUID) (*yeahapi.StockReservation, error) {
+	const op yeahapi.Op = "postgres/InventoryService.Reservation"
+	var reservation yeahapi.StockReservation
+	err := s.pool.QueryRow(ctx,
+		"select id, sku_id, user_id, quantity, created_at, expires_at, released_at from listing_sku_reservations where id = $1", id).Scan(
+		&reservation.ID, &reservation.SkuID, &reservation.UserID, &reservation.Quantity, &reservation.CreatedAt,
+		&reservation.ExpiresAt, &reservation.ReleasedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound)
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	return &reservation, nil
+}
+
+func (s *InventoryService) ExpiredReservations(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	const op yeahapi.Op = "postgres/InventoryService.ExpiredReservations"
+	rows, err := s.pool.Query(ctx,
+		"select id from listing_sku_reservations where released_at is null and expires_at <= now() order by expires_at limit $1", limit)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return ids, nil
+}
+
+func (s *InventoryService) StockAdjustments(ctx context.Context, skuID uuid.UUID) ([]yeahapi.StockAdjustment, error) {
+	const op yeahapi.Op = "postgres/InventoryService.StockAdjustments"
+	rows, err := s.pool.Query(ctx,
+		`select a.id, a.sku_id, s.listing_id, a.reservation_id, a.delta, a.quantity, a.listing_quantity, a.reason, a.note, a.created_at
+		from listing_sku_stock_adjustments a join listing_skus s on s.id = a.sku_id
+		where a.sku_id = $1 order by a.id`, skuID)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	adjustments := make([]yeahapi.StockAdjustment, 0)
+	for rows.Next() {
+		var a yeahapi.StockAdjustment
+		if err := rows.Scan(&a.ID, &a.SkuID, &a.ListingID, &a.ReservationID, &a.Delta, &a.Quantity, &a.ListingQuantity, &a.Reason, &a.Note, &a.CreatedAt); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		adjustments = append(adjustments, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return adjustments, nil
+}
+
+// lockSkuListing locks the listing of a sku for the rest of tx. Stock changes
+// of a listing are serialized this way, so that the listing's total stock seen
+// after a change is exact and a sell out is noticed once.
+func lockSkuListing(ctx context.Context, tx pgx.Tx, skuID uuid.UUID) (uuid.UUID, yeahapi.ListingStatus, error) {
+	var listingID uuid.UUID
+	var status yeahapi.ListingStatus
+	err := tx.QueryRow(ctx,
+		`select l.id, l.status from listings l join listing_skus s on s.listing_id = l.id
+		where s.id = $1 for no key update of l`, skuID).Scan(&listingID, &status)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, "", yeahapi.E(yeahapi.ENotFound)
+		}
+		return uuid.Nil, "", err
+	}
+
+	return listingID, status, nil
+}
+
+// adjustStock applies and logs an adjustment, and stores the events it calls
+// for in the outbox. The conditional update keeps stock from going below zero.
+func adjustStock(ctx context.Context, tx pgx.Tx, a *yeahapi.StockAdjustment) error {
+	err := tx.QueryRow(ctx,
+		"update listing_sku_stock set quantity = quantity + $2 where sku_id = $1 and quantity + $2 >= 0 returning quantity",
+		a.SkuID, a.Delta).Scan(&a.Quantity)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return yeahapi.E(yeahapi.EConflict, "Not enough stock")
+		}
+		return err
+	}
+
+	err = tx.QueryRow(ctx,
+		`insert into listing_sku_stock_adjustments (sku_id, reservation_id, delta, quantity, listing_quantity, reason, note)
+		values ($1, $2, $3, $4, (select coalesce(sum(quantity), 0) from listing_stock where listing_id = $5), $6, $7)
+		returning id, listing_quantity, created_at`,
+		a.SkuID, a.ReservationID, a.Delta, a.Quantity, a.ListingID, a.Reason, a.Note).Scan(&a.ID, &a.ListingQuantity, &a.CreatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	return enqueue(ctx, tx, yeahapi.StockEvents(a)...)
+}
@@ -0,0 +1,245 @@
+package postgres_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestInventoryService_Reserve(t *testing.T) {
+	s := postgres.NewInventoryService(pool)
+	listingService := postgres.NewListingService(pool)
+
+	t.Run("Concurrent", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Nokia 3310")
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+
+		if _, err := s.AdjustStock(ctx, sku.ID, 5, yeahapi.StockReasonRestock, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		reserved, conflicts, soldOut := 0, 0, 0
+		for i := 0; i < 10; i++ {
+			buyer := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "Jane", LastName: "Doe"})
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, adjustment, err := s.Reserve(ctx, sku.ID, buyer.ID, 1)
+				mu.Lock()
+				defer mu.Unlock()
+				if yeahapi.EIs(yeahapi.EConflict, err) {
+					conflicts++
+					return
+				} else if err != nil {
+					t.Error(err)
+					return
+				}
+				reserved++
+				if adjustment.SoldOut() {
+					soldOut++
+				}
+			}()
+		}
+		wg.Wait()
+
+		if reserved != 5 || conflicts != 5 {
+			t.Fatalf("mismatch: %d reserved, %d conflicts", reserved, conflicts)
+		} else if soldOut != 1 {
+			t.Fatalf("sold out %d times", soldOut)
+		}
+
+		got, err := listingService.Listing(ctx, listing.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if !got.SoldOut {
+			t.Fatal("expected listing to be sold out")
+		}
+		MustFindOutboxMessage(t, ctx, yeahapi.ListingSoldOut, listing.ID)
+	})
+
+	t.Run("ErrInactive", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+		buyer := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "Jane", LastName: "Doe"})
+		if _, _, err := s.Reserve(ctx, sku.ID, buyer.ID, 1); !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrOwner", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Nokia 3310")
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+		if _, _, err := s.Reserve(ctx, sku.ID, listing.OwnerID, 1); !yeahapi.EIs(yeahapi.EPermission, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrTooMany", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Nokia 3310")
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+		buyer := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "Jane", LastName: "Doe"})
+		if _, err := s.AdjustStock(ctx, sku.ID, 10, yeahapi.StockReasonRestock, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := s.Reserve(ctx, sku.ID, buyer.ID, yeahapi.MaxReservedQuantity); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := s.Reserve(ctx, sku.ID, buyer.ID, 1); !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestInventoryService_ExpiredReservations(t *testing.T) {
+	s := postgres.NewInventoryService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Nokia 3310")
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+		buyer := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "Jane", LastName: "Doe"})
+		if _, err := s.AdjustStock(ctx, sku.ID, 1, yeahapi.StockReasonRestock, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		reservation, _, err := s.Reserve(ctx, sku.ID, buyer.ID, 1)
+		if err != nil {
+			t.Fatal(err)
+		} else if !reservation.ExpiresAt.After(reservation.CreatedAt) {
+			t.Fatalf("unexpected expiry: %#v", reservation)
+		}
+
+		find := func() bool {
+			expired, err := s.ExpiredReservations(ctx, 1000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range expired {
+				if id == reservation.ID {
+					return true
+				}
+			}
+			return false
+		}
+
+		if find() {
+			t.Fatal("reservation expired early")
+		}
+
+		if _, err := pool.Exec(ctx, "update listing_sku_reservations set expires_at = now() where id = $1", reservation.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if !find() {
+			t.Fatal("expected reservation to expire")
+		}
+
+		if _, _, err := s.Release(ctx, reservation.ID); err != nil {
+			t.Fatal(err)
+		} else if find() {
+			t.Fatal("released reservation still expired")
+		}
+	})
+}
+
+func TestInventoryService_Release(t *testing.T) {
+	s := postgres.NewInventoryService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Nokia 3310")
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+		if _, err := s.AdjustStock(ctx, sku.ID, 1, yeahapi.StockReasonRestock, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		buyer := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "Jane", LastName: "Doe"})
+		reservation, _, err := s.Reserve(ctx, sku.ID, buyer.ID, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		released, adjustment, err := s.Release(ctx, reservation.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if released.ReleasedAt == nil {
+			t.Fatal("expected reservation to be released")
+		} else if adjustment.Quantity != 1 || adjustment.ListingQuantity != 1 {
+			t.Fatalf("unexpected adjustment: %#v", adjustment)
+		}
+
+		if _, _, err := s.Release(ctx, reservation.ID); !yeahapi.EIs(yeahapi.EConflict, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		adjustments, err := s.StockAdjustments(ctx, sku.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reasons := make([]yeahapi.StockReason, len(adjustments))
+		for i, a := range adjustments {
+			reasons[i] = a.Reason
+		}
+
+		want := []yeahapi.StockReason{yeahapi.StockReasonRestock, yeahapi.StockReasonReservation, yeahapi.StockReasonRelease}
+		if len(reasons) != len(want) {
+			t.Fatalf("mismatch: %v != %v", reasons, want)
+		}
+		for i := range want {
+			if reasons[i] != want[i] {
+				t.Fatalf("mismatch: %v != %v", reasons, want)
+			}
+		}
+	})
+}
+
+func TestInventoryService_AdjustStock(t *testing.T) {
+	s := postgres.NewInventoryService(pool)
+
+	t.Run("LowStock", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+		if _, err := s.AdjustStock(ctx, sku.ID, 10, yeahapi.StockReasonRestock, "new shipment"); err != nil {
+			t.Fatal(err)
+		}
+
+		adjustment, err := s.AdjustStock(ctx, sku.ID, -8, yeahapi.StockReasonCorrection, "damaged")
+		if err != nil {
+			t.Fatal(err)
+		} else if adjustment.Quantity != 2 || !adjustment.LowStock() || adjustment.SoldOut() {
+			t.Fatalf("unexpected adjustment: %#v", adjustment)
+		}
+		MustFindOutboxMessage(t, ctx, yeahapi.ListingStockLow, listing.ID)
+	})
+
+	t.Run("ErrNotEnoughStock", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+		if _, err := s.AdjustStock(ctx, sku.ID, -1, yeahapi.StockReasonCorrection, ""); !yeahapi.EIs(yeahapi.EConflict, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrInvalidReason", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+		if _, err := s.AdjustStock(ctx, sku.ID, 1, yeahapi.StockReasonRelease, ""); !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
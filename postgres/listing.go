@@ -3,9 +3,13 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/gofrs/uuid"
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	yeahapi "github.com/yeahuz/yeah-api"
 )
@@ -20,11 +24,56 @@ func NewListingService(pool *pgxpool.Pool) *ListingService {
 	}
 }
 
+// listingColumns selects a listing l, translated by the listings_tr row tr
+// when one is joined. Scan them with listingFields.
+const listingColumns = `l.id, coalesce(tr.title, l.title), coalesce(tr.description, l.description), coalesce(l.condition, ''), l.brand,
+	coalesce(tr.lang_code, l.lang_code, ''), coalesce(l.location_id, 0), case when l.lat is not null then json_build_object('lat', l.lat, 'lng', l.lng) end,
+	l.owner_id, l.category_id, l.status, l.created_at, coalesce(l.updated_at, l.created_at),
+	(select count(*) from favorites where listing_id = l.id), (select expires_at from listing_expiry where listing_id = l.id),
+	(select coalesce(json_agg(json_build_object('kind', kind, 'starts_at', starts_at, 'ends_at', ends_at) order by kind), '[]')
+		from promotion_purchases where listing_id = l.id and status = 'PAID' and starts_at <= now() and ends_at > now())`
+
+// listingReturning is listingColumns for returning clauses of listings
+// updates, which are never translated.
+const listingReturning = `id, title, description, coalesce(condition, ''), brand, coalesce(lang_code, ''),
+	coalesce(location_id, 0), case when lat is not null then json_build_object('lat', lat, 'lng', lng) end,
+	owner_id, category_id, status, created_at, updated_at, (select count(*) from favorites where listing_id = listings.id),
+	(select expires_at from listing_expiry where listing_id = listings.id),
+	(select coalesce(json_agg(json_build_object('kind', kind, 'starts_at', starts_at, 'ends_at', ends_at) order by kind), '[]')
+		from promotion_purchases where listing_id = listings.id and status = 'PAID' and starts_at <= now() and ends_at > now()),
+	coalesce((select quantity = 0 from listing_stock where listing_id = listings.id), false)`
+
+// minPrice selects the cheapest effective price of listing l. Skus priced in
+// different currencies are compared in the currency of the first sku of the
+// listing, prices that can't be converted come last.
+const minPrice = `select e.price, e.price_currency from effective_sku_prices e where e.listing_id = l.id
+	order by convert_amount(e.price, e.price_currency,
+		(select price_currency from listing_skus where listing_id = l.id order by id limit 1)) nulls last, e.price
+	limit 1`
+
+func listingFields(l *yeahapi.Listing) []interface{} {
+	return []interface{}{&l.ID, &l.Title, &l.Description, &l.Condition, &l.Brand, &l.Lang, &l.LocationID, &l.Point,
+		&l.OwnerID, &l.CategoryID, &l.Status, &l.CreatedAt, &l.UpdatedAt, &l.FavoriteCount, &l.ExpiresAt, &l.Promotions}
+}
+
 func (s *ListingService) Listing(ctx context.Context, id uuid.UUID) (*yeahapi.Listing, error) {
 	const op yeahapi.Op = "postgres/ListingService.Listing"
+	listing, err := s.LocalizedListing(ctx, id, "")
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+	return listing, nil
+}
+
+func (s *ListingService) LocalizedListing(ctx context.Context, id uuid.UUID, lang string) (*yeahapi.Listing, error) {
+	const op yeahapi.Op = "postgres/ListingService.LocalizedListing"
 	var listing yeahapi.Listing
 	err := s.pool.QueryRow(ctx,
-		"select id, title, owner_id, category_id, status from listings where id = $1", id).Scan(&listing.ID, &listing.Title, &listing.OwnerID, &listing.CategoryID, &listing.Status)
+		`select `+listingColumns+`, coalesce(st.quantity = 0, false)
+		from listings l
+		left join listings_tr tr on tr.listing_id = l.id and tr.lang_code = $2
+		left join listing_stock st on st.listing_id = l.id where l.id = $1`, id, lang).Scan(
+		append(listingFields(&listing), &listing.SoldOut)...)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -36,6 +85,87 @@ func (s *ListingService) Listing(ctx context.Context, id uuid.UUID) (*yeahapi.Li
 	return &listing, nil
 }
 
+func (s *ListingService) Listings(ctx context.Context, filter yeahapi.ListingFilter) (*yeahapi.ListingPage, error) {
+	const op yeahapi.Op = "postgres/ListingService.Listings"
+
+	if err := filter.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	where, args := []string{"true"}, []interface{}{}
+	if !filter.OwnerID.IsNil() {
+		args = append(args, filter.OwnerID)
+		where = append(where, fmt.Sprintf("l.owner_id = $%d", len(args)))
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		args = append(args, statuses)
+		where = append(where, fmt.Sprintf("l.status = any($%d)", len(args)))
+	}
+	if filter.CategoryID != 0 {
+		args = append(args, filter.CategoryID)
+		where = append(where, fmt.Sprintf("l.category_id = $%d", len(args)))
+	}
+
+	page := &yeahapi.ListingPage{Listings: make([]yeahapi.Listing, 0)}
+	err := s.pool.QueryRow(ctx, "select count(*) from listings l where "+strings.Join(where, " and "), args...).Scan(&page.TotalCount)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if !filter.After.IsNil() {
+		args = append(args, filter.After)
+		where = append(where, fmt.Sprintf("l.id < $%d", len(args)))
+	}
+
+	args = append(args, filter.Lang)
+	lang := len(args)
+
+	// Fetch one extra row to know whether there is a next page.
+	args = append(args, filter.Limit+1)
+	rows, err := s.pool.Query(ctx,
+		`select `+listingColumns+`, p.price, p.price_currency, coalesce(st.quantity = 0, false)
+		from listings l
+		left join lateral (`+minPrice+`) p on true
+		left join listing_stock st on st.listing_id = l.id
+		`+fmt.Sprintf("left join listings_tr tr on tr.listing_id = l.id and tr.lang_code = $%d", lang)+`
+		where `+strings.Join(where, " and ")+fmt.Sprintf(" order by l.id desc limit $%d", len(args)), args...)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var l yeahapi.Listing
+		var price *int
+		var currency *yeahapi.Currency
+		if err := rows.Scan(append(listingFields(&l), &price, &currency, &l.SoldOut)...); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+
+		if price != nil {
+			l.MinPrice = &yeahapi.ListingPrice{Amount: *price, Currency: *currency}
+		}
+
+		page.Listings = append(page.Listings, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if len(page.Listings) > filter.Limit {
+		page.Listings = page.Listings[:filter.Limit]
+		page.NextCursor = &page.Listings[filter.Limit-1].ID
+	}
+
+	return page, nil
+}
+
 func (s *ListingService) CreateListing(ctx context.Context, listing *yeahapi.Listing) (*yeahapi.Listing, error) {
 	const op yeahapi.Op = "postgres/ListingService.CreateListing"
 
@@ -49,17 +179,202 @@ func (s *ListingService) CreateListing(ctx context.Context, listing *yeahapi.Lis
 	}
 
 	listing.ID = id
-	_, err = s.pool.Exec(ctx,
-		"insert into listings (id, title, owner_id, category_id, status) values ($1, $2, $3, $4, $5)",
-		listing.ID, listing.Title, listing.OwnerID, listing.CategoryID, listing.Status)
+	listing.Description = yeahapi.SanitizeDescription(listing.Description)
+	lat, lng := pointArgs(listing.Point)
+	err = s.pool.QueryRow(ctx,
+		`insert into listings (id, title, description, condition, brand, lang_code, location_id, lat, lng, owner_id, category_id, status, title_simhash)
+		values ($1, $2, $3, nullif($4, ''), $5, nullif($6, ''), nullif($7, 0), $8, $9, $10, $11, $12, $13) returning created_at`,
+		listing.ID, listing.Title, listing.Description, listing.Condition, listing.Brand, listing.Lang, listing.LocationID, lat, lng,
+		listing.OwnerID, listing.CategoryID, listing.Status, yeahapi.TitleSimhash(listing.Title)).Scan(&listing.CreatedAt)
 
 	if err != nil {
+		if unknownLanguage(err) {
+			return nil, yeahapi.E(op, yeahapi.EInvalid, "Unknown language")
+		}
+		if unknownLocation(err) {
+			return nil, yeahapi.E(op, yeahapi.EInvalid, "Unknown location")
+		}
 		return nil, yeahapi.E(op, err)
 	}
 
+	listing.UpdatedAt = listing.CreatedAt
 	return listing, nil
 }
 
+func (s *ListingService) UpdateListing(ctx context.Context, id uuid.UUID, upd yeahapi.ListingUpdate) (*yeahapi.Listing, error) {
+	const op yeahapi.Op = "postgres/ListingService.UpdateListing"
+
+	if err := upd.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	set, args := make([]string, 0), []interface{}{id, upd.UpdatedAt}
+	if v := upd.Title; v != nil {
+		args = append(args, *v, yeahapi.TitleSimhash(*v))
+		set = append(set, fmt.Sprintf("title = $%d, title_simhash = $%d", len(args)-1, len(args)))
+	}
+	if v := upd.Description; v != nil {
+		args = append(args, yeahapi.SanitizeDescription(*v))
+		set = append(set, fmt.Sprintf("description = $%d", len(args)))
+	}
+	if v := upd.Condition; v != nil {
+		args = append(args, *v)
+		set = append(set, fmt.Sprintf("condition = nullif($%d, '')", len(args)))
+	}
+	if v := upd.Brand; v != nil {
+		args = append(args, *v)
+		set = append(set, fmt.Sprintf("brand = $%d", len(args)))
+	}
+	if v := upd.Lang; v != nil {
+		args = append(args, *v)
+		set = append(set, fmt.Sprintf("lang_code = nullif($%d, '')", len(args)))
+	}
+	if v := upd.Location; v != nil {
+		lat, lng := pointArgs(v.Point)
+		args = append(args, v.LocationID, lat, lng)
+		set = append(set, fmt.Sprintf("location_id = nullif($%d, 0), lat = $%d, lng = $%d", len(args)-2, len(args)-1, len(args)))
+	}
+	if v := upd.CategoryID; v != nil {
+		args = append(args, *v)
+		set = append(set, fmt.Sprintf("category_id = $%d", len(args)))
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	var from yeahapi.ListingStatus
+	if upd.Resubmit {
+		if from, err = lockEditable(ctx, tx, id); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		if from != yeahapi.ListingStatusDraft {
+			args = append(args, yeahapi.ListingStatusModeration)
+			set = append(set, fmt.Sprintf("status = $%d", len(args)))
+		}
+	}
+
+	var listing yeahapi.Listing
+	err = tx.QueryRow(ctx,
+		`update listings set `+strings.Join(set, ", ")+` where id = $1 and coalesce(updated_at, created_at) = $2
+		returning `+listingReturning, args...).Scan(append(listingFields(&listing), &listing.SoldOut)...)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err := s.Listing(ctx, id); err != nil {
+				return nil, yeahapi.E(op, err)
+			}
+			return nil, yeahapi.E(op, yeahapi.EConflict)
+		}
+		if unknownLanguage(err) {
+			return nil, yeahapi.E(op, yeahapi.EInvalid, "Unknown language")
+		}
+		if unknownLocation(err) {
+			return nil, yeahapi.E(op, yeahapi.EInvalid, "Unknown location")
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	if upd.Resubmit && listing.Status != from {
+		if err := enqueue(ctx, tx, yeahapi.NewListingStatusChangedEvent(&listing, from)); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return &listing, nil
+}
+
+// lockEditable locks the listing for the rest of tx and returns its status.
+// Listings under review or deleted can't be edited.
+func lockEditable(ctx context.Context, tx pgx.Tx, id uuid.UUID) (yeahapi.ListingStatus, error) {
+	var status yeahapi.ListingStatus
+	if err := tx.QueryRow(ctx, "select status from listings where id = $1 for update", id).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", yeahapi.E(yeahapi.ENotFound)
+		}
+		return "", err
+	}
+
+	switch status {
+	case yeahapi.ListingStatusDraft, yeahapi.ListingStatusActive, yeahapi.ListingStatusArchived:
+		return status, nil
+	}
+	return "", yeahapi.E(yeahapi.EInvalid, "Listing can't be edited while it's being reviewed")
+}
+
+// resubmitListing sends an active or archived listing that is being edited
+// back to moderation as part of tx. Drafts are left as they are.
+func resubmitListing(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	from, err := lockEditable(ctx, tx, id)
+	if err != nil || from == yeahapi.ListingStatusDraft {
+		return err
+	}
+
+	var listing yeahapi.Listing
+	err = tx.QueryRow(ctx, "update listings set status = $2 where id = $1 returning "+listingReturning,
+		id, yeahapi.ListingStatusModeration).Scan(append(listingFields(&listing), &listing.SoldOut)...)
+	if err != nil {
+		return err
+	}
+
+	return enqueue(ctx, tx, yeahapi.NewListingStatusChangedEvent(&listing, from))
+}
+
+// pointArgs splits an optional point into nullable coordinates.
+func pointArgs(p *yeahapi.Point) (lat, lng *float64) {
+	if p == nil {
+		return nil, nil
+	}
+	return &p.Lat, &p.Lng
+}
+
+func (s *ListingService) UpdateStatus(ctx context.Context, id uuid.UUID, from, to yeahapi.ListingStatus) (*yeahapi.Listing, error) {
+	const op yeahapi.Op = "postgres/ListingService.UpdateStatus"
+
+	if !from.CanTransition(to) {
+		return nil, yeahapi.E(op, yeahapi.EInvalid, fmt.Sprintf("Listing can't be moved from %s to %s", from, to))
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	var listing yeahapi.Listing
+	err = tx.QueryRow(ctx,
+		`update listings set status = $3 where id = $1 and status = $2
+		returning `+listingReturning, id, from, to).Scan(append(listingFields(&listing), &listing.SoldOut)...)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err := s.Listing(ctx, id); err != nil {
+				return nil, yeahapi.E(op, err)
+			}
+			return nil, yeahapi.E(op, yeahapi.EConflict)
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := enqueue(ctx, tx, yeahapi.NewListingStatusChangedEvent(&listing, from)); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return &listing, nil
+}
+
 func (s *ListingService) DeleteListing(ctx context.Context, id uuid.UUID) error {
 	const op yeahapi.Op = "postgres/ListingService.DeleteListing"
 	_, err := s.pool.Exec(ctx, "delete from listings where id = $1", id)
@@ -69,8 +384,27 @@ func (s *ListingService) DeleteListing(ctx context.Context, id uuid.UUID) error
 	return nil
 }
 
+// insertSkuQuery creates a sku along with its stock. Initial stock is logged
+// as a restock.
+const insertSkuQuery = `with sku as (
+		insert into listing_skus (id, custom_sku, listing_id, attrs, price, price_currency) values ($1, $2, $3, $4, $5, $6)
+		returning id, created_at
+	), stock as (
+		insert into listing_sku_stock (sku_id, quantity) select id, $7 from sku
+	), adjustment as (
+		insert into listing_sku_stock_adjustments (sku_id, delta, quantity, listing_quantity, reason)
+		select id, $7, $7, coalesce((select quantity from listing_stock where listing_id = $3), 0) + $7, 'RESTOCK'
+		from sku where $7::int > 0
+	)
+	select created_at from sku`
+
 func (s *ListingService) CreateSku(ctx context.Context, sku *yeahapi.ListingSku) (*yeahapi.ListingSku, error) {
 	const op yeahapi.Op = "postgres/ListingService.CreateSku"
+
+	if sku.Quantity < 0 {
+		return nil, yeahapi.E(op, yeahapi.EInvalid, "Quantity can't be negative")
+	}
+
 	id, err := uuid.NewV7()
 	if err != nil {
 		return nil, yeahapi.E(op, err)
@@ -78,17 +412,147 @@ func (s *ListingService) CreateSku(ctx context.Context, sku *yeahapi.ListingSku)
 
 	sku.ID = id
 
-	_, err = s.pool.Exec(ctx, "insert into listing_skus (id, custom_sku, listing_id, attrs, price, price_currency) values ($1, $2, $3, $4, $5, $6)",
-		sku.ID, sku.CustomSku, sku.ListingID, sku.Attrs, sku.Price, sku.PriceCurrency,
-	)
+	err = s.pool.QueryRow(ctx, insertSkuQuery,
+		sku.ID, sku.CustomSku, sku.ListingID, sku.Attrs, sku.Price, sku.PriceCurrency, sku.Quantity,
+	).Scan(&sku.CreatedAt)
 
 	if err != nil {
+		if unknownCurrency(err) {
+			return nil, yeahapi.E(op, yeahapi.EInvalid, "Unknown currency")
+		}
 		return nil, yeahapi.E(op, err)
 	}
 
+	sku.UpdatedAt = sku.CreatedAt
 	return sku, nil
 }
 
+// CreateVariations creates the skus whose variation isn't taken by an
+// existing sku of the listing yet and returns the created ones. The listing
+// is locked so concurrent runs don't create the same variation twice.
+func (s *ListingService) CreateVariations(ctx context.Context, listingID uuid.UUID, keys []string, skus []yeahapi.ListingSku) ([]yeahapi.ListingSku, error) {
+	const op yeahapi.Op = "postgres/ListingService.CreateVariations"
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	var id uuid.UUID
+	if err := tx.QueryRow(ctx, "select id from listings where id = $1 for update", listingID).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound)
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	rows, err := tx.Query(ctx, "select attrs from listing_skus where listing_id = $1", listingID)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var attrs yeahapi.ListingAttrs
+		if err := rows.Scan(&attrs); err != nil {
+			rows.Close()
+			return nil, yeahapi.E(op, err)
+		}
+		existing[yeahapi.VariationKey(attrs, keys)] = true
+	}
+
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	created := make([]yeahapi.ListingSku, 0, len(skus))
+	for _, sku := range skus {
+		key := yeahapi.VariationKey(sku.Attrs, keys)
+		if existing[key] {
+			continue
+		}
+
+		if sku.Quantity < 0 {
+			return nil, yeahapi.E(op, yeahapi.EInvalid, "Quantity can't be negative")
+		}
+
+		if sku.ID, err = uuid.NewV7(); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+
+		sku.ListingID = listingID
+		err = tx.QueryRow(ctx, insertSkuQuery,
+			sku.ID, sku.CustomSku, sku.ListingID, sku.Attrs, sku.Price, sku.PriceCurrency, sku.Quantity,
+		).Scan(&sku.CreatedAt)
+
+		if err != nil {
+			if unknownCurrency(err) {
+				return nil, yeahapi.E(op, yeahapi.EInvalid, "Unknown currency")
+			}
+			return nil, yeahapi.E(op, err)
+		}
+
+		sku.UpdatedAt = sku.CreatedAt
+		existing[key] = true
+		created = append(created, sku)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return created, nil
+}
+
+func (s *ListingService) UpdateSku(ctx context.Context, id uuid.UUID, upd yeahapi.ListingSkuUpdate) (*yeahapi.ListingSku, error) {
+	const op yeahapi.Op = "postgres/ListingService.UpdateSku"
+
+	if err := upd.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	set, args := make([]string, 0), []interface{}{id, upd.UpdatedAt}
+	if v := upd.CustomSku; v != nil {
+		args = append(args, *v)
+		set = append(set, fmt.Sprintf("custom_sku = $%d", len(args)))
+	}
+	if v := upd.Attrs; v != nil {
+		args = append(args, *v)
+		set = append(set, fmt.Sprintf("attrs = $%d", len(args)))
+	}
+	if upd.Price != nil {
+		args = append(args, *upd.Price, *upd.PriceCurrency)
+		set = append(set, fmt.Sprintf("price = $%d, price_currency = $%d", len(args)-1, len(args)))
+	}
+
+	err := s.pool.QueryRow(ctx,
+		`update listing_skus set `+strings.Join(set, ", ")+` where id = $1 and coalesce(updated_at, created_at) = $2 returning id`,
+		args...).Scan(&id)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err := s.Sku(ctx, id); err != nil {
+				return nil, yeahapi.E(op, err)
+			}
+			return nil, yeahapi.E(op, yeahapi.EConflict)
+		}
+		if unknownCurrency(err) {
+			return nil, yeahapi.E(op, yeahapi.EInvalid, "Unknown currency")
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	// Scheduled prices may be in effect instead of the one stored on the sku.
+	updated, err := s.Sku(ctx, id)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return updated, nil
+}
+
 func (s *ListingService) DeleteSku(ctx context.Context, id uuid.UUID) error {
 	const op yeahapi.Op = "postgres/ListingService.DeleteSku"
 
@@ -105,7 +569,10 @@ func (s *ListingService) Skus(ctx context.Context, listingID uuid.UUID) ([]yeaha
 	skus := make([]yeahapi.ListingSku, 0)
 
 	rows, err := s.pool.Query(ctx,
-		`select id, custom_sku, listing_id, attrs, price, price_currency from listing_skus`)
+		`select s.id, s.custom_sku, s.listing_id, s.attrs, p.price, p.price_currency, coalesce(st.quantity, 0), s.created_at, coalesce(s.updated_at, s.created_at)
+		from listing_skus s join effective_sku_prices p on p.sku_id = s.id
+		left join listing_sku_stock st on st.sku_id = s.id
+		where s.listing_id = $1 order by s.id`, listingID)
 
 	defer rows.Close()
 	if err != nil {
@@ -114,7 +581,7 @@ func (s *ListingService) Skus(ctx context.Context, listingID uuid.UUID) ([]yeaha
 
 	for rows.Next() {
 		var s yeahapi.ListingSku
-		if err := rows.Scan(s.ID, s.CustomSku, s.ListingID, s.Attrs, s.Price, s.PriceCurrency); err != nil {
+		if err := rows.Scan(&s.ID, &s.CustomSku, &s.ListingID, &s.Attrs, &s.Price, &s.PriceCurrency, &s.Quantity, &s.CreatedAt, &s.UpdatedAt); err != nil {
 			return nil, yeahapi.E(op, err)
 		}
 
@@ -133,9 +600,11 @@ func (s *ListingService) Sku(ctx context.Context, skuID uuid.UUID) (*yeahapi.Lis
 
 	var sku yeahapi.ListingSku
 	err := s.pool.QueryRow(ctx,
-		"select id, custom_sku, listing_id, attrs, price, price_currency from listing_skus where id = $1",
+		`select s.id, s.custom_sku, s.listing_id, s.attrs, p.price, p.price_currency, coalesce(st.quantity, 0), s.created_at, coalesce(s.updated_at, s.created_at)
+		from listing_skus s join effective_sku_prices p on p.sku_id = s.id
+		left join listing_sku_stock st on st.sku_id = s.id where s.id = $1`,
 		skuID,
-	).Scan(&sku.ID, &sku.CustomSku, &sku.ListingID, &sku.Attrs, &sku.Price, &sku.PriceCurrency)
+	).Scan(&sku.ID, &sku.CustomSku, &sku.ListingID, &sku.Attrs, &sku.Price, &sku.PriceCurrency, &sku.Quantity, &sku.CreatedAt, &sku.UpdatedAt)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -147,3 +616,109 @@ func (s *ListingService) Sku(ctx context.Context, skuID uuid.UUID) (*yeahapi.Lis
 
 	return &sku, nil
 }
+
+func (s *ListingService) SetTranslation(ctx context.Context, tr *yeahapi.ListingTranslation) (*yeahapi.ListingTranslation, error) {
+	const op yeahapi.Op = "postgres/ListingService.SetTranslation"
+
+	if err := tr.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	tr.Description = yeahapi.SanitizeDescription(tr.Description)
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := resubmitListing(ctx, tx, tr.ListingID); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	var lang string
+	err = tx.QueryRow(ctx,
+		`insert into listings_tr (listing_id, lang_code, title, description)
+		select id, $2, $3, $4 from listings where id = $1 and coalesce(lang_code, '') <> $2
+		on conflict (listing_id, lang_code) do update set title = excluded.title, description = excluded.description
+		returning lang_code`,
+		tr.ListingID, tr.Lang, tr.Title, tr.Description).Scan(&lang)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.EInvalid, "Listing is already written in this language")
+		}
+		if unknownLanguage(err) {
+			return nil, yeahapi.E(op, yeahapi.EInvalid, "Unknown language")
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return tr, nil
+}
+
+func (s *ListingService) DeleteTranslation(ctx context.Context, listingID uuid.UUID, lang string) error {
+	const op yeahapi.Op = "postgres/ListingService.DeleteTranslation"
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := resubmitListing(ctx, tx, listingID); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	tag, err := tx.Exec(ctx, "delete from listings_tr where listing_id = $1 and lang_code = $2", listingID, lang)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return yeahapi.E(op, yeahapi.ENotFound)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *ListingService) Translations(ctx context.Context, listingID uuid.UUID) ([]yeahapi.ListingTranslation, error) {
+	const op yeahapi.Op = "postgres/ListingService.Translations"
+	rows, err := s.pool.Query(ctx,
+		"select listing_id, lang_code, title, description from listings_tr where listing_id = $1 order by lang_code", listingID)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	translations := make([]yeahapi.ListingTranslation, 0)
+	for rows.Next() {
+		var tr yeahapi.ListingTranslation
+		if err := rows.Scan(&tr.ListingID, &tr.Lang, &tr.Title, &tr.Description); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		translations = append(translations, tr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return translations, nil
+}
+
+// unknownLanguage reports whether err is caused by a language code that is
+// not in languages.
+func unknownLanguage(err error) bool {
+	var pgerr *pgconn.PgError
+	return errors.As(err, &pgerr) && pgerr.Code == pgerrcode.ForeignKeyViolation && strings.HasSuffix(pgerr.ConstraintName, "lang_code_fkey")
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	yeahapi "github.com/yeahuz/yeah-api"
 	"github.com/yeahuz/yeah-api/postgres"
@@ -21,6 +23,140 @@ func TestListingService_CreateListing(t *testing.T) {
 			t.Fatalf("mismatch: %#v != %#v", other, listing)
 		}
 	})
+
+	t.Run("Details", func(t *testing.T) {
+		ctx := context.Background()
+		other := MustCreateListing(t, ctx, pool)
+		listing, err := s.CreateListing(ctx, &yeahapi.Listing{
+			Title:       "Phone",
+			Description: `<p onclick="x">Barely <b>used</b><script>alert(1)</script>`,
+			Condition:   yeahapi.ListingConditionUsed,
+			Brand:       "Apple",
+			Lang:        "en",
+			OwnerID:     other.OwnerID,
+			CategoryID:  other.CategoryID,
+			Status:      yeahapi.ListingStatusDraft,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		} else if listing.Description != "<p>Barely <b>used</b></p>" {
+			t.Fatalf("unexpected description: %q", listing.Description)
+		}
+
+		if got, err := s.Listing(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(got, listing) {
+			t.Fatalf("mismatch: %#v != %#v", got, listing)
+		}
+	})
+
+	t.Run("ErrUnknownCondition", func(t *testing.T) {
+		ctx := context.Background()
+		other := MustCreateListing(t, ctx, pool)
+		_, err := s.CreateListing(ctx, &yeahapi.Listing{
+			Title:      "Phone",
+			Condition:  "BROKEN",
+			OwnerID:    other.OwnerID,
+			CategoryID: other.CategoryID,
+			Status:     yeahapi.ListingStatusDraft,
+		})
+
+		if !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+}
+
+func TestListingService_SetTranslation(t *testing.T) {
+	s := postgres.NewListingService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		lang := "en"
+		listing, err := s.UpdateListing(ctx, listing.ID, yeahapi.ListingUpdate{Lang: &lang, UpdatedAt: listing.UpdatedAt})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := s.SetTranslation(ctx, &yeahapi.ListingTranslation{
+			ListingID:   listing.ID,
+			Lang:        "ru",
+			Title:       "Привет, мир",
+			Description: "<p>Описание</p>",
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := s.LocalizedListing(ctx, listing.ID, "ru"); err != nil {
+			t.Fatal(err)
+		} else if got.Title != "Привет, мир" || got.Description != "<p>Описание</p>" || got.Lang != "ru" {
+			t.Fatalf("unexpected listing: %#v", got)
+		}
+
+		// Languages without a translation get the listing as written.
+		if got, err := s.LocalizedListing(ctx, listing.ID, "uz"); err != nil {
+			t.Fatal(err)
+		} else if got.Title != listing.Title || got.Lang != "en" {
+			t.Fatalf("unexpected listing: %#v", got)
+		}
+
+		if err := s.DeleteTranslation(ctx, listing.ID, "ru"); err != nil {
+			t.Fatal(err)
+		} else if translations, err := s.Translations(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if len(translations) != 0 {
+			t.Fatalf("len=%d, want 0", len(translations))
+		}
+	})
+
+	t.Run("ErrSameLanguage", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		lang := "uz"
+		if _, err := s.UpdateListing(ctx, listing.ID, yeahapi.ListingUpdate{Lang: &lang, UpdatedAt: listing.UpdatedAt}); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := s.SetTranslation(ctx, &yeahapi.ListingTranslation{ListingID: listing.ID, Lang: "uz", Title: "Salom"})
+		if !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+
+	t.Run("ErrUnknownLanguage", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		_, err := s.SetTranslation(ctx, &yeahapi.ListingTranslation{ListingID: listing.ID, Lang: "xx", Title: "Hello"})
+		if !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+
+	t.Run("Resubmit", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		if _, err := s.SetTranslation(ctx, &yeahapi.ListingTranslation{ListingID: listing.ID, Lang: "ru", Title: "Велосипед"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := s.Listing(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if got.Status != yeahapi.ListingStatusModeration {
+			t.Fatalf("unexpected status: %s", got.Status)
+		}
+		MustFindOutboxMessage(t, ctx, yeahapi.ListingModerationSubmitted, listing.ID)
+
+		// Translations of listings under review are left as they are.
+		_, err := s.SetTranslation(ctx, &yeahapi.ListingTranslation{ListingID: listing.ID, Lang: "ru", Title: "Велосипед Stels"})
+		if !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+		if err := s.DeleteTranslation(ctx, listing.ID, "ru"); !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
 }
 
 func TestListingService_DeleteListing(t *testing.T) {
@@ -68,6 +204,335 @@ func TestListingService_CreateSku(t *testing.T) {
 	})
 }
 
+func TestListingService_UpdateListing(t *testing.T) {
+	s := postgres.NewListingService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		title := "Updated title"
+
+		updated, err := s.UpdateListing(ctx, listing.ID, yeahapi.ListingUpdate{
+			Title:     &title,
+			UpdatedAt: listing.UpdatedAt,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if updated.Title != title {
+			t.Fatalf("mismatch: %q != %q", updated.Title, title)
+		} else if updated.CategoryID != listing.CategoryID {
+			t.Fatalf("mismatch: %d != %d", updated.CategoryID, listing.CategoryID)
+		}
+
+		if other, err := s.Listing(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(other, updated) {
+			t.Fatalf("mismatch: %#v != %#v", other, updated)
+		}
+	})
+
+	t.Run("Resubmit", func(t *testing.T) {
+		ctx := context.Background()
+		title := "Updated title"
+
+		draft := MustCreateListing(t, ctx, pool)
+		if updated, err := s.UpdateListing(ctx, draft.ID, yeahapi.ListingUpdate{Title: &title, UpdatedAt: draft.UpdatedAt, Resubmit: true}); err != nil {
+			t.Fatal(err)
+		} else if updated.Status != yeahapi.ListingStatusDraft {
+			t.Fatalf("unexpected status: %s", updated.Status)
+		}
+
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		updated, err := s.UpdateListing(ctx, listing.ID, yeahapi.ListingUpdate{Title: &title, UpdatedAt: listing.UpdatedAt, Resubmit: true})
+		if err != nil {
+			t.Fatal(err)
+		} else if updated.Status != yeahapi.ListingStatusModeration || updated.Title != title {
+			t.Fatalf("unexpected listing: %#v", updated)
+		}
+		MustFindOutboxMessage(t, ctx, yeahapi.ListingModerationSubmitted, listing.ID)
+
+		// Listings under review are left as they are.
+		_, err = s.UpdateListing(ctx, listing.ID, yeahapi.ListingUpdate{Title: &title, UpdatedAt: updated.UpdatedAt, Resubmit: true})
+		if !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+
+	t.Run("ErrConflict", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		title := "Updated title"
+
+		if _, err := s.UpdateListing(ctx, listing.ID, yeahapi.ListingUpdate{
+			Title:     &title,
+			UpdatedAt: listing.UpdatedAt,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := s.UpdateListing(ctx, listing.ID, yeahapi.ListingUpdate{
+			Title:     &title,
+			UpdatedAt: listing.UpdatedAt,
+		})
+
+		if !yeahapi.EIs(yeahapi.EConflict, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrListingNotFound", func(t *testing.T) {
+		id, _ := uuid.NewV7()
+		title := "Updated title"
+		_, err := s.UpdateListing(context.Background(), id, yeahapi.ListingUpdate{
+			Title:     &title,
+			UpdatedAt: time.Now(),
+		})
+
+		if !yeahapi.EIs(yeahapi.ENotFound, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestListingService_UpdateSku(t *testing.T) {
+	s := postgres.NewListingService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+
+		price, currency := 349, yeahapi.CurrencyUSD
+		updated, err := s.UpdateSku(ctx, sku.ID, yeahapi.ListingSkuUpdate{
+			Price:         &price,
+			PriceCurrency: &currency,
+			UpdatedAt:     sku.UpdatedAt,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if updated.Price != price {
+			t.Fatalf("mismatch: %d != %d", updated.Price, price)
+		} else if !reflect.DeepEqual(updated.Attrs, sku.Attrs) {
+			t.Fatalf("mismatch: %#v != %#v", updated.Attrs, sku.Attrs)
+		}
+	})
+
+	t.Run("ErrConflict", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+
+		customSku := "SKU-1"
+		if _, err := s.UpdateSku(ctx, sku.ID, yeahapi.ListingSkuUpdate{
+			CustomSku: &customSku,
+			UpdatedAt: sku.UpdatedAt,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := s.UpdateSku(ctx, sku.ID, yeahapi.ListingSkuUpdate{
+			CustomSku: &customSku,
+			UpdatedAt: sku.UpdatedAt,
+		})
+
+		if !yeahapi.EIs(yeahapi.EConflict, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestListingService_UpdateStatus(t *testing.T) {
+	s := postgres.NewListingService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+
+		updated, err := s.UpdateStatus(ctx, listing.ID, yeahapi.ListingStatusDraft, yeahapi.ListingStatusModeration)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if updated.Status != yeahapi.ListingStatusModeration {
+			t.Fatalf("mismatch: %s != %s", updated.Status, yeahapi.ListingStatusModeration)
+		}
+	})
+
+	t.Run("ErrInvalidTransition", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+
+		_, err := s.UpdateStatus(ctx, listing.ID, yeahapi.ListingStatusDraft, yeahapi.ListingStatusActive)
+		if !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrConflict", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+
+		_, err := s.UpdateStatus(ctx, listing.ID, yeahapi.ListingStatusActive, yeahapi.ListingStatusArchived)
+		if !yeahapi.EIs(yeahapi.EConflict, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestListingService_Listings(t *testing.T) {
+	s := postgres.NewListingService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		MustCreateSku(t, ctx, pool, listing.ID)
+
+		for i := 0; i < 2; i++ {
+			if _, err := s.CreateListing(ctx, &yeahapi.Listing{
+				Title:      "Another listing",
+				OwnerID:    listing.OwnerID,
+				CategoryID: listing.CategoryID,
+				Status:     yeahapi.ListingStatusDraft,
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		filter := yeahapi.ListingFilter{OwnerID: listing.OwnerID, Limit: 2}
+		page, err := s.Listings(ctx, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if page.TotalCount != 3 {
+			t.Fatalf("mismatch: %d != %d", page.TotalCount, 3)
+		} else if len(page.Listings) != 2 || page.NextCursor == nil {
+			t.Fatalf("unexpected page: %#v", page)
+		}
+
+		filter.After = *page.NextCursor
+		page, err = s.Listings(ctx, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(page.Listings) != 1 || page.NextCursor != nil {
+			t.Fatalf("unexpected page: %#v", page)
+		} else if page.Listings[0].ID != listing.ID {
+			t.Fatalf("mismatch: %s != %s", page.Listings[0].ID, listing.ID)
+		} else if page.Listings[0].MinPrice == nil || page.Listings[0].MinPrice.Amount != 299 {
+			t.Fatalf("unexpected min price: %#v", page.Listings[0].MinPrice)
+		}
+	})
+
+	t.Run("MixedCurrencies", func(t *testing.T) {
+		ctx := context.Background()
+		if _, err := postgres.NewCurrencyService(pool).SaveRates(ctx, []yeahapi.ExchangeRate{
+			{Base: yeahapi.CurrencyUSD, Quote: yeahapi.CurrencyUZS, Rate: "12345.67", Date: time.Now(), Source: "test"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		listing := MustCreateListing(t, ctx, pool)
+		MustCreateSku(t, ctx, pool, listing.ID)
+		// 1000 UZS is less than the 2.99 USD of the other sku, though the amount
+		// is bigger.
+		if _, err := s.CreateSku(ctx, &yeahapi.ListingSku{
+			ListingID:     listing.ID,
+			Price:         100000,
+			PriceCurrency: yeahapi.CurrencyUZS,
+			Attrs:         yeahapi.ListingAttrs{"ram": "4 GB"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		page, err := s.Listings(ctx, yeahapi.ListingFilter{OwnerID: listing.OwnerID, Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := yeahapi.ListingPrice{Amount: 100000, Currency: yeahapi.CurrencyUZS}
+		if len(page.Listings) != 1 || page.Listings[0].MinPrice == nil || *page.Listings[0].MinPrice != want {
+			t.Fatalf("unexpected listings: %#v", page.Listings)
+		}
+	})
+}
+
+func TestListingService_CreateVariations(t *testing.T) {
+	s := postgres.NewListingService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		keys := []string{"color", "storage"}
+		variations := func(colors ...string) []yeahapi.ListingSku {
+			var skus []yeahapi.ListingSku
+			for _, color := range colors {
+				for _, storage := range []string{"128", "256"} {
+					skus = append(skus, yeahapi.ListingSku{
+						Attrs:         yeahapi.ListingAttrs{"color": color, "storage": storage},
+						Price:         999,
+						PriceCurrency: yeahapi.CurrencyUSD,
+					})
+				}
+			}
+			return skus
+		}
+
+		created, err := s.CreateVariations(ctx, listing.ID, keys, variations("black"))
+		if err != nil {
+			t.Fatal(err)
+		} else if len(created) != 2 {
+			t.Fatalf("mismatch: %d != %d", len(created), 2)
+		}
+
+		created, err = s.CreateVariations(ctx, listing.ID, keys, variations("black", "white"))
+		if err != nil {
+			t.Fatal(err)
+		} else if len(created) != 2 || created[0].Attrs["color"] != "white" {
+			t.Fatalf("unexpected skus: %#v", created)
+		}
+
+		skus, err := s.Skus(ctx, listing.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(skus) != 4 {
+			t.Fatalf("mismatch: %d != %d", len(skus), 4)
+		}
+	})
+
+	t.Run("ErrListingNotFound", func(t *testing.T) {
+		ctx := context.Background()
+		if _, err := s.CreateVariations(ctx, uuid.Must(uuid.NewV7()), nil, nil); !yeahapi.EIs(yeahapi.ENotFound, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func MustCreateSku(tb testing.TB, ctx context.Context, pool *pgxpool.Pool, listingID uuid.UUID) *yeahapi.ListingSku {
+	tb.Helper()
+	sku, err := postgres.NewListingService(pool).CreateSku(ctx, &yeahapi.ListingSku{
+		ListingID:     listingID,
+		Price:         299,
+		PriceCurrency: yeahapi.CurrencyUSD,
+		Attrs: yeahapi.ListingAttrs{
+			"ram": "8 GB",
+		},
+	})
+
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return sku
+}
+
 func MustCreateListing(tb testing.TB, ctx context.Context, pool *pgxpool.Pool) *yeahapi.Listing {
 	tb.Helper()
 	user := MustCreateUser(tb, ctx, pool, &yeahapi.User{
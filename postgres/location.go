@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type LocationService struct {
+	pool *pgxpool.Pool
+}
+
+func NewLocationService(pool *pgxpool.Pool) *LocationService {
+	return &LocationService{
+		pool: pool,
+	}
+}
+
+func (s *LocationService) Locations(ctx context.Context, lang string) ([]yeahapi.Location, error) {
+	const op yeahapi.Op = "postgres/LocationService.Locations"
+	locations := make([]yeahapi.Location, 0)
+
+	rows, err := s.pool.Query(ctx,
+		`select l.id, coalesce(l.parent_id, 0), l.kind, coalesce(lt.name, ''), l.lat, l.lng
+		from locations l left join locations_tr lt on lt.location_id = l.id and lt.lang_code = $1
+		order by l.id`, lang)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var l yeahapi.Location
+		if err := rows.Scan(&l.ID, &l.ParentID, &l.Kind, &l.Name, &l.Lat, &l.Lng); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		locations = append(locations, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return locations, nil
+}
+
+func (s *LocationService) Location(ctx context.Context, id int, lang string) (*yeahapi.Location, error) {
+	const op yeahapi.Op = "postgres/LocationService.Location"
+	var l yeahapi.Location
+	err := s.pool.QueryRow(ctx,
+		`select l.id, coalesce(l.parent_id, 0), l.kind, coalesce(lt.name, ''), l.lat, l.lng
+		from locations l left join locations_tr lt on lt.location_id = l.id and lt.lang_code = $2
+		where l.id = $1`, id, lang).Scan(&l.ID, &l.ParentID, &l.Kind, &l.Name, &l.Lat, &l.Lng)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound)
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	return &l, nil
+}
+
+// unknownLocation reports whether err is caused by a location id that is not
+// in locations.
+func unknownLocation(err error) bool {
+	var pgerr *pgconn.PgError
+	return errors.As(err, &pgerr) && pgerr.Code == pgerrcode.ForeignKeyViolation && strings.HasSuffix(pgerr.ConstraintName, "location_id_fkey")
+}
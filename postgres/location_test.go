@@ -0,0 +1,116 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestLocationService_Locations(t *testing.T) {
+	s := postgres.NewLocationService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		locations, err := s.Locations(ctx, "ru")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		regions, districts := 0, 0
+		withDistricts := make(map[int]bool)
+		for _, l := range locations {
+			switch l.Kind {
+			case yeahapi.LocationKindRegion:
+				regions++
+			case yeahapi.LocationKindDistrict:
+				districts++
+				withDistricts[l.ParentID] = true
+			}
+		}
+
+		if regions != 14 || districts != 175 {
+			t.Fatalf("got %d regions and %d districts, want 14 and 175", regions, districts)
+		} else if len(withDistricts) != regions {
+			t.Fatalf("only %d of %d regions have districts", len(withDistricts), regions)
+		}
+
+		tashkent := MustFindLocation(t, locations, "Ташкент")
+		if district := MustFindLocation(t, locations, "Юнусабадский район"); district.ParentID != tashkent.ID {
+			t.Fatalf("mismatch: %d != %d", district.ParentID, tashkent.ID)
+		}
+	})
+}
+
+func TestSearchService_SearchNearby(t *testing.T) {
+	s := postgres.NewSearchService(pool)
+	listings := postgres.NewListingService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		locations, err := postgres.NewLocationService(pool).Locations(ctx, "en")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tashkent := MustFindLocation(t, locations, "Tashkent")
+		chilanzar := MustFindLocation(t, locations, "Chilanzar District")
+		samarkand := MustFindLocation(t, locations, "Samarkand")
+
+		near := MustCreateActiveListing(t, ctx, pool, "Sofa in Chilanzar")
+		far := MustCreateActiveListing(t, ctx, pool, "Sofa in Samarkand")
+		for _, l := range []struct {
+			listing  *yeahapi.Listing
+			location yeahapi.Location
+		}{{near, chilanzar}, {far, samarkand}} {
+			_, err := listings.UpdateListing(ctx, l.listing.ID, yeahapi.ListingUpdate{
+				Location: &yeahapi.ListingLocation{
+					LocationID: l.location.ID,
+					Point:      &yeahapi.Point{Lat: l.location.Lat, Lng: l.location.Lng},
+				},
+				UpdatedAt: l.listing.UpdatedAt,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Chilanzar is in Tashkent, Samarkand is some 270 km away.
+		page, err := s.Search(ctx, yeahapi.ListingSearch{CategoryID: near.CategoryID, LocationID: tashkent.ID, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		} else if len(page.Hits) != 1 || page.Hits[0].ID != near.ID {
+			t.Fatalf("unexpected page: %#v", page)
+		}
+
+		center := &yeahapi.Point{Lat: tashkent.Lat, Lng: tashkent.Lng}
+		for _, categoryID := range []int{near.CategoryID, far.CategoryID} {
+			page, err := s.Search(ctx, yeahapi.ListingSearch{CategoryID: categoryID, Near: center, Radius: 20, Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if categoryID == near.CategoryID {
+				if len(page.Hits) != 1 || page.Hits[0].Distance == nil || *page.Hits[0].Distance > 10 {
+					t.Fatalf("unexpected page: %#v", page)
+				} else if page.Hits[0].Point == nil || page.Hits[0].LocationID != chilanzar.ID {
+					t.Fatalf("unexpected hit: %#v", page.Hits[0])
+				}
+			} else if len(page.Hits) != 0 {
+				t.Fatalf("unexpected page: %#v", page)
+			}
+		}
+	})
+}
+
+func MustFindLocation(tb testing.TB, locations []yeahapi.Location, name string) yeahapi.Location {
+	tb.Helper()
+	for _, l := range locations {
+		if l.Name == name {
+			return l
+		}
+	}
+	tb.Fatalf("location %q not found", name)
+	return yeahapi.Location{}
+}
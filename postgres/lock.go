@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+// Locker takes Postgres advisory locks, which are shared by every replica
+// using the database and let go of when the connection holding them is
+// closed.
+type Locker struct {
+	pool *pgxpool.Pool
+}
+
+func NewLocker(pool *pgxpool.Pool) *Locker {
+	return &Locker{
+		pool: pool,
+	}
+}
+
+// TryLock holds the lock on a connection of its own for as long as fn runs.
+func (l *Locker) TryLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	const op yeahapi.Op = "postgres/Locker.TryLock"
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return false, yeahapi.E(op, err)
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, "select pg_try_advisory_lock(hashtextextended($1, 0))", name).Scan(&locked); err != nil {
+		return false, yeahapi.E(op, err)
+	}
+
+	if !locked {
+		return false, nil
+	}
+
+	fnErr := fn(ctx)
+
+	// The unlock must go through even when ctx is done, or the lock would
+	// stay with the pooled connection.
+	unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := conn.Exec(unlockCtx, "select pg_advisory_unlock(hashtextextended($1, 0))", name); err != nil {
+		// Closing the connection lets go of the lock instead.
+		conn.Conn().Close(unlockCtx)
+		return true, yeahapi.E(op, err)
+	}
+
+	if fnErr != nil {
+		return true, yeahapi.E(op, fnErr)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,320 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type MediaService struct {
+	pool *pgxpool.Pool
+}
+
+func NewMediaService(pool *pgxpool.Pool) *MediaService {
+	return &MediaService{
+		pool: pool,
+	}
+}
+
+const mediaColumns = "id, listing_id, owner_id, key, content_type, size, position, is_primary, created_at, width, height, placeholder, variants"
+
+func scanMedia(row pgx.Row, m *yeahapi.Media) error {
+	return row.Scan(&m.ID, &m.ListingID, &m.OwnerID, &m.Key, &m.ContentType, &m.Size, &m.Position, &m.Primary, &m.CreatedAt,
+		&m.Width, &m.Height, &m.Placeholder, &m.Variants)
+}
+
+func (s *MediaService) CreateMedia(ctx context.Context, media *yeahapi.Media) (*yeahapi.Media, error) {
+	const op yeahapi.Op = "postgres/MediaService.CreateMedia"
+
+	if err := media.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if media.ID.IsNil() {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		media.ID = id
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := lockListing(ctx, tx, media.ListingID); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	var count int
+	err = tx.QueryRow(ctx, "select count(*), coalesce(max(position) + 1, 0) from listing_media where listing_id = $1", media.ListingID).Scan(&count, &media.Position)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if count >= yeahapi.MaxListingMedia {
+		return nil, yeahapi.E(op, yeahapi.EInvalid, fmt.Sprintf("A listing can have at most %d photos", yeahapi.MaxListingMedia))
+	}
+
+	media.Primary = count == 0
+	err = tx.QueryRow(ctx,
+		`insert into listing_media (id, listing_id, owner_id, key, content_type, size, position, is_primary)
+		values ($1, $2, $3, $4, $5, $6, $7, $8) returning created_at`,
+		media.ID, media.ListingID, media.OwnerID, media.Key, media.ContentType, media.Size, media.Position, media.Primary).Scan(&media.CreatedAt)
+
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := enqueue(ctx, tx, yeahapi.NewMediaUploadedEvent(media)); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return media, nil
+}
+
+func (s *MediaService) Media(ctx context.Context, id uuid.UUID) (*yeahapi.Media, error) {
+	const op yeahapi.Op = "postgres/MediaService.Media"
+	var media yeahapi.Media
+	if err := scanMedia(s.pool.QueryRow(ctx, "select "+mediaColumns+" from listing_media where id = $1", id), &media); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound)
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	return &media, nil
+}
+
+func (s *MediaService) ListingMedia(ctx context.Context, listingID uuid.UUID) ([]yeahapi.Media, error) {
+	const op yeahapi.Op = "postgres/MediaService.ListingMedia"
+	rows, err := s.pool.Query(ctx, "select "+mediaColumns+" from listing_media where listing_id = $1 order by position, id", listingID)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	media := make([]yeahapi.Media, 0)
+	for rows.Next() {
+		var m yeahapi.Media
+		if err := scanMedia(rows, &m); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		media = append(media, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return media, nil
+}
+
+func (s *MediaService) ReorderMedia(ctx context.Context, listingID uuid.UUID, ids []uuid.UUID) ([]yeahapi.Media, error) {
+	const op yeahapi.Op = "postgres/MediaService.ReorderMedia"
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := lockListing(ctx, tx, listingID); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	order := make([]string, len(ids))
+	for i, id := range ids {
+		order[i] = id.String()
+	}
+
+	// Every photo of the listing must be given exactly once.
+	var ok bool
+	err = tx.QueryRow(ctx,
+		`select count(distinct o.id) = cardinality($2::uuid[]) and count(distinct o.id) = (select count(*) from listing_media where listing_id = $1)
+		and coalesce(bool_and(m.id is not null), true)
+		from unnest($2::uuid[]) o(id) left join listing_media m on m.id = o.id and m.listing_id = $1`,
+		listingID, order).Scan(&ok)
+
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if !ok {
+		return nil, yeahapi.E(op, yeahapi.EInvalid, "Every photo of the listing must be listed once")
+	}
+
+	if _, err := tx.Exec(ctx,
+		`update listing_media m set position = o.n - 1
+		from unnest($2::uuid[]) with ordinality o(id, n)
+		where m.id = o.id and m.listing_id = $1`,
+		listingID, order); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	media, err := s.ListingMedia(ctx, listingID)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return media, nil
+}
+
+func (s *MediaService) SetPrimaryMedia(ctx context.Context, id uuid.UUID) error {
+	const op yeahapi.Op = "postgres/MediaService.SetPrimaryMedia"
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	listingID, err := lockMediaListing(ctx, tx, id)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	// The old primary photo is demoted first, the unique index on primary
+	// photos is checked row by row.
+	if _, err := tx.Exec(ctx, "update listing_media set is_primary = false where listing_id = $1 and is_primary and id <> $2", listingID, id); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	if _, err := tx.Exec(ctx, "update listing_media set is_primary = true where id = $1", id); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *MediaService) DeleteMedia(ctx context.Context, id uuid.UUID) (*yeahapi.Media, error) {
+	const op yeahapi.Op = "postgres/MediaService.DeleteMedia"
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	listingID, err := lockMediaListing(ctx, tx, id)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	var media yeahapi.Media
+	if err := scanMedia(tx.QueryRow(ctx, "delete from listing_media where id = $1 returning "+mediaColumns, id), &media); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound)
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	if media.Primary {
+		if _, err := tx.Exec(ctx,
+			`update listing_media set is_primary = true
+			where id = (select id from listing_media where listing_id = $1 order by position, id limit 1)`, listingID); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return &media, nil
+}
+
+func (s *MediaService) SaveVariants(ctx context.Context, media *yeahapi.Media) error {
+	const op yeahapi.Op = "postgres/MediaService.SaveVariants"
+	variants := media.Variants
+	if variants == nil {
+		variants = make([]yeahapi.MediaVariant, 0)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	var status yeahapi.ListingStatus
+	err = tx.QueryRow(ctx,
+		`with m as (
+			update listing_media set width = $2, height = $3, placeholder = $4, variants = $5, phash = $6 where id = $1
+			returning listing_id
+		) select l.status from m join listings l on l.id = m.listing_id`,
+		media.ID, media.Width, media.Height, media.Placeholder, variants, media.PerceptualHash).Scan(&status)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return yeahapi.E(op, yeahapi.ENotFound)
+		}
+		return yeahapi.E(op, err)
+	}
+
+	// Photos of submitted listings may be hashed after the listing was
+	// checked, or added once it was approved.
+	if status != yeahapi.ListingStatusDraft && status != yeahapi.ListingStatusDeleted {
+		if err := enqueue(ctx, tx, yeahapi.NewMediaProcessedEvent(media)); err != nil {
+			return yeahapi.E(op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
+
+// lockListing locks a listing for the rest of tx, so that changes to its
+// photos are serialized.
+func lockListing(ctx context.Context, tx pgx.Tx, listingID uuid.UUID) error {
+	var id uuid.UUID
+	if err := tx.QueryRow(ctx, "select id from listings where id = $1 for no key update", listingID).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return yeahapi.E(yeahapi.ENotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+// lockMediaListing locks the listing of a photo and returns its id.
+func lockMediaListing(ctx context.Context, tx pgx.Tx, mediaID uuid.UUID) (uuid.UUID, error) {
+	var listingID uuid.UUID
+	if err := tx.QueryRow(ctx, "select listing_id from listing_media where id = $1", mediaID).Scan(&listingID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, yeahapi.E(yeahapi.ENotFound)
+		}
+		return uuid.Nil, err
+	}
+
+	if err := lockListing(ctx, tx, listingID); err != nil {
+		return uuid.Nil, err
+	}
+
+	return listingID, nil
+}
@@ -0,0 +1,279 @@
+package postgres_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/disk"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestMediaService_CreateMedia(t *testing.T) {
+	s := postgres.NewMediaService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		store := disk.NewBlobStore(t.TempDir(), "http://localhost/blobs")
+		listing := MustCreateListing(t, ctx, pool)
+
+		// A 2x1 photo taken with the camera turned, which only shows upright
+		// thanks to its EXIF orientation.
+		data, contentType, err := yeahapi.SanitizeImage(MustEncodeJPEG(t, 2, 1, 6))
+		if err != nil {
+			t.Fatal(err)
+		} else if contentType != "image/jpeg" {
+			t.Fatalf("mismatch: %s != %s", contentType, "image/jpeg")
+		} else if bytes.Contains(data, []byte("Exif")) {
+			t.Fatal("expected EXIF to be stripped")
+		}
+
+		media := &yeahapi.Media{
+			ID:          uuid.Must(uuid.NewV7()),
+			ListingID:   listing.ID,
+			OwnerID:     listing.OwnerID,
+			ContentType: contentType,
+			Size:        int64(len(data)),
+		}
+		media.Key = yeahapi.MediaKey(media)
+
+		if err := store.Put(ctx, media.Key, bytes.NewReader(data), media.Size, media.ContentType); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := s.CreateMedia(ctx, media); err != nil {
+			t.Fatal(err)
+		} else if !media.Primary || media.Position != 0 {
+			t.Fatalf("unexpected media: %#v", media)
+		}
+		MustFindOutboxMessage(t, ctx, yeahapi.MediaUploaded, listing.ID)
+
+		blob, err := store.Get(ctx, media.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer blob.Body.Close()
+		stored, err := io.ReadAll(blob.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		config, err := jpeg.DecodeConfig(bytes.NewReader(stored))
+		if err != nil {
+			t.Fatal(err)
+		} else if config.Width != 1 || config.Height != 2 {
+			t.Fatalf("expected photo to be rotated, got %dx%d", config.Width, config.Height)
+		}
+
+		second := MustCreateMedia(t, ctx, s, listing)
+		if second.Primary || second.Position != 1 {
+			t.Fatalf("unexpected media: %#v", second)
+		}
+	})
+
+	t.Run("ErrUnsupported", func(t *testing.T) {
+		if _, _, err := yeahapi.SanitizeImage([]byte("<html></html>")); !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrTooMany", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		for i := 0; i < yeahapi.MaxListingMedia; i++ {
+			MustCreateMedia(t, ctx, s, listing)
+		}
+
+		media := &yeahapi.Media{ID: uuid.Must(uuid.NewV7()), ListingID: listing.ID, OwnerID: listing.OwnerID, ContentType: "image/png"}
+		media.Key = yeahapi.MediaKey(media)
+		if _, err := s.CreateMedia(ctx, media); !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestMediaService_ReorderMedia(t *testing.T) {
+	s := postgres.NewMediaService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		a, b, c := MustCreateMedia(t, ctx, s, listing), MustCreateMedia(t, ctx, s, listing), MustCreateMedia(t, ctx, s, listing)
+
+		media, err := s.ReorderMedia(ctx, listing.ID, []uuid.UUID{c.ID, a.ID, b.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(media) != 3 || media[0].ID != c.ID || media[1].ID != a.ID || media[2].ID != b.ID {
+			t.Fatalf("unexpected order: %#v", media)
+		}
+	})
+
+	t.Run("ErrIncomplete", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		a, _ := MustCreateMedia(t, ctx, s, listing), MustCreateMedia(t, ctx, s, listing)
+
+		for _, ids := range [][]uuid.UUID{{a.ID}, {a.ID, a.ID}, {a.ID, uuid.Must(uuid.NewV7())}} {
+			if _, err := s.ReorderMedia(ctx, listing.ID, ids); !yeahapi.EIs(yeahapi.EInvalid, err) {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+		}
+	})
+}
+
+func TestMediaService_SetPrimaryMedia(t *testing.T) {
+	s := postgres.NewMediaService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		a, b := MustCreateMedia(t, ctx, s, listing), MustCreateMedia(t, ctx, s, listing)
+
+		if err := s.SetPrimaryMedia(ctx, b.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		media, err := s.ListingMedia(ctx, listing.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if media[0].ID != a.ID || media[0].Primary || !media[1].Primary {
+			t.Fatalf("unexpected media: %#v", media)
+		}
+
+		// Deleting the primary photo makes the first one left primary.
+		if _, err := s.DeleteMedia(ctx, b.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := s.Media(ctx, a.ID); err != nil {
+			t.Fatal(err)
+		} else if !got.Primary {
+			t.Fatal("expected photo to become primary")
+		}
+	})
+}
+
+func TestMediaProcessor_Process(t *testing.T) {
+	s := postgres.NewMediaService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		store := disk.NewBlobStore(t.TempDir(), "http://localhost/blobs")
+		listing := MustCreateListing(t, ctx, pool)
+		media := MustCreateMedia(t, ctx, s, listing)
+
+		data := MustEncodeJPEG(t, 2000, 1000, 1)
+		if err := store.Put(ctx, media.Key, bytes.NewReader(data), int64(len(data)), media.ContentType); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := yeahapi.NewMediaProcessor(s, store).Process(ctx, media); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.Media(ctx, media.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if got.Width != 2000 || got.Height != 1000 || got.Placeholder == "" {
+			t.Fatalf("unexpected media: %#v", got)
+		} else if len(got.Variants) != len(yeahapi.MediaVariantSizes) {
+			t.Fatalf("len=%d, want %d", len(got.Variants), len(yeahapi.MediaVariantSizes))
+		}
+
+		// Variants are scaled down to fit their box, keeping the aspect ratio.
+		for _, variant := range got.Variants {
+			blob, err := store.Get(ctx, yeahapi.VariantKey(got, variant.Name))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := jpeg.DecodeConfig(blob.Body)
+			blob.Body.Close()
+			if err != nil {
+				t.Fatal(err)
+			} else if config.Width != variant.Width || config.Height != variant.Height || variant.Width != 2*variant.Height {
+				t.Fatalf("unexpected %s variant: %dx%d", variant.Name, config.Width, config.Height)
+			}
+		}
+	})
+}
+
+func TestMediaService_SaveVariants(t *testing.T) {
+	s := postgres.NewMediaService(pool)
+
+	t.Run("Submitted", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		media := MustCreateMedia(t, ctx, s, listing)
+		media.Width, media.Height, media.PerceptualHash = 800, 600, 0x0f0f0f0f0f0f0f0f
+
+		// Photos of listings past review are checked for copies once hashed.
+		if err := s.SaveVariants(ctx, media); err != nil {
+			t.Fatal(err)
+		}
+		MustFindOutboxMessage(t, ctx, yeahapi.MediaProcessed, listing.ID)
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		err := s.SaveVariants(context.Background(), &yeahapi.Media{ID: uuid.Must(uuid.NewV7())})
+		if !yeahapi.EIs(yeahapi.ENotFound, err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func MustCreateMedia(tb testing.TB, ctx context.Context, s *postgres.MediaService, listing *yeahapi.Listing) *yeahapi.Media {
+	tb.Helper()
+	media := &yeahapi.Media{
+		ID:          uuid.Must(uuid.NewV7()),
+		ListingID:   listing.ID,
+		OwnerID:     listing.OwnerID,
+		ContentType: "image/jpeg",
+		Size:        1024,
+	}
+	media.Key = yeahapi.MediaKey(media)
+
+	media, err := s.CreateMedia(ctx, media)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return media
+}
+
+// MustEncodeJPEG encodes a w by h JPEG with an EXIF orientation tag.
+func MustEncodeJPEG(tb testing.TB, w, h int, orientation uint16) []byte {
+	tb.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		tb.Fatal(err)
+	}
+
+	// A big endian TIFF header followed by an IFD with just the orientation.
+	var exif bytes.Buffer
+	exif.WriteString("Exif\x00\x00MM\x00\x2a")
+	for _, v := range []interface{}{uint32(8), uint16(1), uint16(0x0112), uint16(3), uint32(1), orientation, uint16(0), uint32(0)} {
+		binary.Write(&exif, binary.BigEndian, v)
+	}
+
+	var out bytes.Buffer
+	out.Write(buf.Bytes()[:2])
+	out.Write([]byte{0xff, 0xe1})
+	binary.Write(&out, binary.BigEndian, uint16(exif.Len()+2))
+	out.Write(exif.Bytes())
+	out.Write(buf.Bytes()[2:])
+	return out.Bytes()
+}
@@ -0,0 +1,10 @@
+begin;
+
+drop function if exists insert_moderation_reason;
+drop table if exists moderation_decisions cascade;
+drop table if exists moderation_queue cascade;
+drop table if exists moderation_reasons_tr cascade;
+drop table if exists moderation_reasons cascade;
+drop table if exists moderators cascade;
+
+commit;
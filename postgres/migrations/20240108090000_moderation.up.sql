@@ -0,0 +1,68 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS moderators (
+  user_id uuid PRIMARY KEY,
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS moderation_reasons (
+  code varchar(255) PRIMARY KEY,
+  active boolean DEFAULT TRUE
+);
+
+CREATE TABLE IF NOT EXISTS moderation_reasons_tr (
+  reason_code varchar(255) NOT NULL,
+  lang_code varchar(255) NOT NULL,
+  name varchar(255) DEFAULT '',
+  FOREIGN KEY (reason_code) REFERENCES moderation_reasons (code) ON DELETE CASCADE,
+  FOREIGN KEY (lang_code) REFERENCES languages (code) ON DELETE CASCADE,
+  PRIMARY KEY (reason_code, lang_code)
+);
+
+CREATE TABLE IF NOT EXISTS moderation_queue (
+  listing_id uuid PRIMARY KEY,
+  submitted_at timestamp with time zone DEFAULT now() NOT NULL,
+  moderator_id uuid,
+  lease_expires_at timestamp with time zone,
+  FOREIGN KEY (listing_id) REFERENCES listings (id) ON DELETE CASCADE,
+  FOREIGN KEY (moderator_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS moderation_decisions (
+  id uuid PRIMARY KEY,
+  listing_id uuid NOT NULL,
+  moderator_id uuid NOT NULL,
+  kind varchar(255) CHECK (kind IN ('APPROVED', 'REJECTED')) NOT NULL,
+  reason_code varchar(255),
+  comment text DEFAULT '',
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  FOREIGN KEY (listing_id) REFERENCES listings (id) ON DELETE CASCADE,
+  FOREIGN KEY (moderator_id) REFERENCES users (id) ON DELETE CASCADE,
+  FOREIGN KEY (reason_code) REFERENCES moderation_reasons (code) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_moderation_queue_submitted_at ON moderation_queue (submitted_at);
+CREATE INDEX idx_moderation_decisions_listing_id ON moderation_decisions (listing_id);
+
+CREATE OR REPLACE FUNCTION insert_moderation_reason(code varchar(255), en varchar(255), ru varchar(255), uz varchar(255))
+RETURNS varchar(255)
+AS $$
+BEGIN
+  INSERT INTO moderation_reasons (code) VALUES (code);
+  INSERT INTO moderation_reasons_tr (reason_code, lang_code, name)
+    VALUES (code, 'en', en), (code, 'ru', ru), (code, 'uz', uz);
+  RETURN code;
+END;
+$$
+LANGUAGE plpgsql;
+
+select insert_moderation_reason('PROHIBITED_ITEM', 'Prohibited item', 'Запрещённый товар', 'Taqiqlangan mahsulot');
+select insert_moderation_reason('WRONG_CATEGORY', 'Wrong category', 'Неверная категория', 'Noto''g''ri kategoriya');
+select insert_moderation_reason('MISLEADING_INFO', 'Misleading title or description', 'Вводящее в заблуждение описание', 'Chalg''ituvchi sarlavha yoki tavsif');
+select insert_moderation_reason('CONTACTS_IN_TEXT', 'Contacts in title or description', 'Контакты в заголовке или описании', 'Sarlavha yoki tavsifda kontaktlar');
+select insert_moderation_reason('INVALID_PRICE', 'Invalid price', 'Некорректная цена', 'Noto''g''ri narx');
+select insert_moderation_reason('DUPLICATE', 'Duplicate listing', 'Дубликат объявления', 'Takroriy e''lon');
+select insert_moderation_reason('OTHER', 'Other', 'Другое', 'Boshqa');
+
+COMMIT;
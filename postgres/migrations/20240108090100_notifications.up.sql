@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS notifications (
+  id uuid PRIMARY KEY,
+  user_id uuid NOT NULL,
+  kind varchar(255) NOT NULL,
+  payload jsonb,
+  read boolean DEFAULT FALSE,
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications (user_id);
+
+COMMIT;
@@ -0,0 +1,8 @@
+begin;
+
+drop table if exists banned_words cascade;
+drop table if exists listing_check_results cascade;
+alter table moderation_queue drop column if exists score;
+alter table moderation_decisions drop column if exists automated;
+
+commit;
@@ -0,0 +1,30 @@
+BEGIN;
+
+ALTER TABLE moderation_decisions ALTER COLUMN moderator_id DROP NOT NULL;
+ALTER TABLE moderation_decisions ADD COLUMN IF NOT EXISTS automated boolean DEFAULT FALSE;
+ALTER TABLE moderation_queue ADD COLUMN IF NOT EXISTS score real DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS listing_check_results (
+  listing_id uuid NOT NULL,
+  check_name varchar(255) NOT NULL,
+  score real DEFAULT 0,
+  reason_code varchar(255) DEFAULT '',
+  detail varchar(1024) DEFAULT '',
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  FOREIGN KEY (listing_id) REFERENCES listings (id) ON DELETE CASCADE,
+  PRIMARY KEY (listing_id, check_name)
+);
+
+CREATE TABLE IF NOT EXISTS banned_words (
+  lang_code varchar(255) NOT NULL,
+  word varchar(255) NOT NULL,
+  FOREIGN KEY (lang_code) REFERENCES languages (code) ON DELETE CASCADE,
+  PRIMARY KEY (lang_code, word)
+);
+
+INSERT INTO banned_words (lang_code, word)
+  VALUES ('en', 'cocaine'), ('en', 'heroin'), ('en', 'firearm'), ('en', 'counterfeit'), ('en', 'replica'),
+  ('ru', 'кокаин'), ('ru', 'героин'), ('ru', 'оружие'), ('ru', 'наркотики'), ('ru', 'подделка'),
+  ('uz', 'kokain'), ('uz', 'geroin'), ('uz', 'qurol'), ('uz', 'giyohvand'), ('uz', 'qalbaki');
+
+COMMIT;
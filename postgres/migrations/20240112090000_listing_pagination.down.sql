@@ -0,0 +1,6 @@
+begin;
+
+drop index if exists idx_listing_skus_listing_id_price;
+drop index if exists idx_listings_owner_id_id;
+
+commit;
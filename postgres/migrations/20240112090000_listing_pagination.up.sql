@@ -0,0 +1,6 @@
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_listings_owner_id_id ON listings (owner_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_listing_skus_listing_id_price ON listing_skus (listing_id, price);
+
+COMMIT;
@@ -0,0 +1,12 @@
+begin;
+
+drop trigger if exists trigger_refresh_category_listing_search on categories_tr;
+drop trigger if exists trigger_refresh_listing_search on listings;
+drop function if exists refresh_category_listing_search;
+drop function if exists refresh_listing_search;
+drop function if exists listing_search_document;
+drop index if exists idx_listings_status_id;
+drop index if exists idx_listings_title_trgm;
+drop table if exists listing_search cascade;
+
+commit;
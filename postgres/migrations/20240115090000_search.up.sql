@@ -0,0 +1,69 @@
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS listing_search (
+  listing_id uuid PRIMARY KEY,
+  document tsvector NOT NULL,
+  FOREIGN KEY (listing_id) REFERENCES listings (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_listing_search_document ON listing_search USING GIN (document);
+CREATE INDEX idx_listings_title_trgm ON listings USING GIN (lower(title) gin_trgm_ops);
+CREATE INDEX idx_listings_status_id ON listings (status, id DESC);
+
+-- Titles are indexed with every config since a listing's language is unknown:
+-- english and russian stem their languages, simple covers uzbek and anything
+-- else word by word. Category titles weigh less than the listing title.
+CREATE OR REPLACE FUNCTION listing_search_document(lid uuid)
+RETURNS tsvector
+AS $$
+  SELECT
+    setweight(to_tsvector('english', l.title), 'A') ||
+    setweight(to_tsvector('russian', l.title), 'A') ||
+    setweight(to_tsvector('simple', l.title), 'A') ||
+    setweight(to_tsvector('simple', coalesce((
+      SELECT string_agg(ct.title, ' ') FROM categories_tr ct WHERE ct.category_id = l.category_id
+    ), '')), 'C')
+  FROM listings l WHERE l.id = lid;
+$$
+LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION refresh_listing_search()
+RETURNS TRIGGER
+AS $$
+BEGIN
+  INSERT INTO listing_search (listing_id, document)
+    VALUES (NEW.id, listing_search_document(NEW.id))
+    ON CONFLICT (listing_id) DO UPDATE SET document = excluded.document;
+  RETURN NULL;
+END;
+$$
+LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_refresh_listing_search
+  AFTER INSERT OR UPDATE OF title, category_id ON listings
+  FOR EACH ROW
+  EXECUTE PROCEDURE refresh_listing_search();
+
+CREATE OR REPLACE FUNCTION refresh_category_listing_search()
+RETURNS TRIGGER
+AS $$
+BEGIN
+  UPDATE listing_search s SET document = listing_search_document(s.listing_id)
+    FROM listings l WHERE l.id = s.listing_id AND l.category_id = NEW.category_id;
+  RETURN NULL;
+END;
+$$
+LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_refresh_category_listing_search
+  AFTER INSERT OR UPDATE ON categories_tr
+  FOR EACH ROW
+  EXECUTE PROCEDURE refresh_category_listing_search();
+
+INSERT INTO listing_search (listing_id, document)
+  SELECT id, listing_search_document(id) FROM listings
+  ON CONFLICT (listing_id) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,6 @@
+begin;
+
+drop index if exists idx_attribute_options_attribute_id_value;
+drop index if exists idx_listing_skus_attrs;
+
+commit;
@@ -0,0 +1,6 @@
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS idx_listing_skus_attrs ON listing_skus USING GIN (attrs jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_attribute_options_attribute_id_value ON attribute_options (attribute_id, value);
+
+COMMIT;
@@ -0,0 +1,9 @@
+begin;
+
+drop view if exists effective_sku_prices;
+drop trigger if exists trigger_record_listing_sku_price on listing_skus;
+drop function if exists record_listing_sku_price;
+drop index if exists idx_listing_sku_prices_due;
+alter table listing_sku_prices drop column if exists announced_at;
+
+commit;
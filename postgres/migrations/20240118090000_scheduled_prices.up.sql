@@ -0,0 +1,43 @@
+BEGIN;
+
+-- Set once the price row became effective and price watchers were told about
+-- it.
+ALTER TABLE listing_sku_prices ADD COLUMN announced_at timestamp with time zone;
+
+CREATE INDEX idx_listing_sku_prices_due ON listing_sku_prices (start_date) WHERE announced_at IS NULL;
+
+-- listing_skus.price is the price set directly by the seller. Every change to
+-- it is recorded as a price starting immediately, so that price history is
+-- complete and scheduled prices take over from it in order.
+CREATE OR REPLACE FUNCTION record_listing_sku_price()
+RETURNS TRIGGER
+AS $$
+BEGIN
+  INSERT INTO listing_sku_prices (sku_id, amount, currency, start_date)
+    VALUES (NEW.id, NEW.price, NEW.price_currency, now())
+    ON CONFLICT (sku_id, start_date) DO UPDATE SET amount = excluded.amount, currency = excluded.currency;
+  RETURN NULL;
+END;
+$$
+LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_record_listing_sku_price
+  AFTER INSERT OR UPDATE OF price, price_currency ON listing_skus
+  FOR EACH ROW
+  EXECUTE PROCEDURE record_listing_sku_price();
+
+INSERT INTO listing_sku_prices (sku_id, amount, currency, start_date, announced_at)
+  SELECT id, price, price_currency, created_at, now() FROM listing_skus
+  ON CONFLICT (sku_id, start_date) DO NOTHING;
+
+-- Prices in effect right now.
+CREATE OR REPLACE VIEW effective_sku_prices AS
+  SELECT s.id AS sku_id, s.listing_id, coalesce(p.amount, s.price) AS price, coalesce(p.currency, s.price_currency) AS price_currency
+  FROM listing_skus s
+  LEFT JOIN LATERAL (
+    SELECT amount, currency FROM listing_sku_prices
+    WHERE sku_id = s.id AND start_date <= now()
+    ORDER BY start_date DESC LIMIT 1
+  ) p ON true;
+
+COMMIT;
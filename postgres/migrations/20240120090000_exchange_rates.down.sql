@@ -0,0 +1,10 @@
+begin;
+
+drop function if exists convert_amount;
+drop function if exists exchange_rate;
+drop view if exists latest_exchange_rates;
+drop table if exists exchange_rates cascade;
+delete from currencies where code in ('EUR', 'RUB', 'KZT', 'GBP', 'CNY', 'TRY', 'KGS', 'JPY', 'KRW');
+alter table currencies drop column if exists minor_units;
+
+commit;
@@ -0,0 +1,54 @@
+BEGIN;
+
+-- Prices are stored in minor units of their currency, e.g. cents for USD.
+ALTER TABLE currencies ADD COLUMN minor_units int NOT NULL DEFAULT 2;
+
+INSERT INTO currencies (code, symbol, minor_units)
+  VALUES ('EUR', '€', 2), ('RUB', '₽', 2), ('KZT', '₸', 2), ('GBP', '£', 2), ('CNY', '¥', 2),
+    ('TRY', '₺', 2), ('KGS', 'сом', 2), ('JPY', '¥', 0), ('KRW', '₩', 0)
+  ON CONFLICT (code) DO NOTHING;
+
+-- One unit of base costs rate units of quote.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+  base varchar(10) NOT NULL,
+  quote varchar(10) NOT NULL,
+  rate numeric NOT NULL CHECK (rate > 0),
+  date date NOT NULL,
+  source varchar(255) NOT NULL,
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  FOREIGN KEY (base) REFERENCES currencies (code) ON DELETE CASCADE,
+  FOREIGN KEY (quote) REFERENCES currencies (code) ON DELETE CASCADE,
+  PRIMARY KEY (base, quote, date)
+);
+
+CREATE OR REPLACE VIEW latest_exchange_rates AS
+  SELECT DISTINCT ON (base, quote) base, quote, rate, date, source
+  FROM exchange_rates
+  ORDER BY base, quote, date DESC;
+
+-- Direct rates win over inverse ones, which win over cross rates through a
+-- currency both have a rate to. Null when there's no way to convert.
+CREATE OR REPLACE FUNCTION exchange_rate(base varchar, quote varchar)
+RETURNS numeric
+AS $$
+  SELECT CASE WHEN base = quote THEN 1 ELSE coalesce(
+    (SELECT r.rate FROM latest_exchange_rates r WHERE r.base = $1 AND r.quote = $2),
+    (SELECT 1 / r.rate FROM latest_exchange_rates r WHERE r.base = $2 AND r.quote = $1),
+    (SELECT a.rate / b.rate FROM latest_exchange_rates a JOIN latest_exchange_rates b ON b.quote = a.quote
+      WHERE a.base = $1 AND b.base = $2 ORDER BY a.quote LIMIT 1)
+  ) END;
+$$
+LANGUAGE sql STABLE;
+
+-- Converts an amount in minor units of one currency into minor units of
+-- another. The result is rounded half away from zero once, at the end.
+CREATE OR REPLACE FUNCTION convert_amount(amount bigint, from_currency varchar, to_currency varchar)
+RETURNS bigint
+AS $$
+  SELECT round(amount * exchange_rate(from_currency, to_currency) * power(10::numeric, t.minor_units - f.minor_units))::bigint
+  FROM currencies f, currencies t
+  WHERE f.code = from_currency AND t.code = to_currency;
+$$
+LANGUAGE sql STABLE;
+
+COMMIT;
@@ -0,0 +1,8 @@
+begin;
+
+drop view if exists listing_stock;
+drop table if exists listing_sku_stock_adjustments;
+drop table if exists listing_sku_reservations;
+drop table if exists listing_sku_stock;
+
+commit;
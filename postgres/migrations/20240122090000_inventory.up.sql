@@ -0,0 +1,53 @@
+BEGIN;
+
+-- Stock lives apart from listing_skus so that buyers reserving stock don't
+-- bump the sku's updated_at, which sellers use as a concurrency token.
+CREATE TABLE IF NOT EXISTS listing_sku_stock (
+  sku_id uuid PRIMARY KEY,
+  quantity int NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+  FOREIGN KEY (sku_id) REFERENCES listing_skus (id) ON DELETE CASCADE
+);
+
+-- Skus that exist already are single items.
+INSERT INTO listing_sku_stock (sku_id, quantity)
+  SELECT id, 1 FROM listing_skus
+  ON CONFLICT (sku_id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS listing_sku_reservations (
+  id uuid PRIMARY KEY,
+  sku_id uuid NOT NULL,
+  user_id uuid NOT NULL,
+  quantity int NOT NULL CHECK (quantity > 0),
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  released_at timestamp with time zone,
+  FOREIGN KEY (sku_id) REFERENCES listing_skus (id) ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_listing_sku_reservations_sku_id ON listing_sku_reservations (sku_id);
+CREATE INDEX idx_listing_sku_reservations_user_id ON listing_sku_reservations (user_id);
+
+CREATE TABLE IF NOT EXISTS listing_sku_stock_adjustments (
+  id bigserial PRIMARY KEY,
+  sku_id uuid NOT NULL,
+  reservation_id uuid,
+  delta int NOT NULL,
+  quantity int NOT NULL,
+  listing_quantity int NOT NULL,
+  reason varchar(32) NOT NULL,
+  note text NOT NULL DEFAULT '',
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  FOREIGN KEY (sku_id) REFERENCES listing_skus (id) ON DELETE CASCADE,
+  FOREIGN KEY (reservation_id) REFERENCES listing_sku_reservations (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_listing_sku_stock_adjustments_sku_id ON listing_sku_stock_adjustments (sku_id, id);
+
+-- Total stock of listings that have skus. A listing is sold out when it's 0.
+CREATE OR REPLACE VIEW listing_stock AS
+  SELECT s.listing_id, sum(st.quantity)::int AS quantity
+  FROM listing_skus s
+  JOIN listing_sku_stock st ON st.sku_id = s.id
+  GROUP BY s.listing_id;
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS listing_media (
+  id uuid PRIMARY KEY,
+  listing_id uuid NOT NULL,
+  owner_id uuid NOT NULL,
+  key varchar(255) NOT NULL,
+  content_type varchar(64) NOT NULL,
+  size bigint NOT NULL,
+  position int NOT NULL,
+  is_primary boolean NOT NULL DEFAULT FALSE,
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  FOREIGN KEY (listing_id) REFERENCES listings (id) ON DELETE CASCADE,
+  FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_listing_media_listing_id ON listing_media (listing_id, position);
+CREATE UNIQUE INDEX idx_listing_media_primary ON listing_media (listing_id) WHERE is_primary;
+
+COMMIT;
@@ -0,0 +1,9 @@
+begin;
+
+alter table listing_media
+  drop column if exists width,
+  drop column if exists height,
+  drop column if exists placeholder,
+  drop column if exists variants;
+
+commit;
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE listing_media
+  ADD COLUMN IF NOT EXISTS width int NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS height int NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS placeholder varchar(16) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS variants jsonb NOT NULL DEFAULT '[]';
+
+COMMIT;
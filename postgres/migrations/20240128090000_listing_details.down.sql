@@ -0,0 +1,36 @@
+begin;
+
+drop trigger if exists trigger_refresh_translated_listing_search on listings_tr;
+drop function if exists refresh_translated_listing_search();
+drop table if exists listings_tr;
+
+create or replace function listing_search_document(lid uuid)
+returns tsvector
+as $$
+  select
+    setweight(to_tsvector('english', l.title), 'A') ||
+    setweight(to_tsvector('russian', l.title), 'A') ||
+    setweight(to_tsvector('simple', l.title), 'A') ||
+    setweight(to_tsvector('simple', coalesce((
+      select string_agg(ct.title, ' ') from categories_tr ct where ct.category_id = l.category_id
+    ), '')), 'C')
+  from listings l where l.id = lid;
+$$
+language sql stable;
+
+drop trigger if exists trigger_refresh_listing_search on listings;
+
+create trigger trigger_refresh_listing_search
+  after insert or update of title, category_id on listings
+  for each row
+  execute procedure refresh_listing_search();
+
+update listing_search s set document = listing_search_document(s.listing_id);
+
+alter table listings
+  drop column if exists description,
+  drop column if exists condition,
+  drop column if exists brand,
+  drop column if exists lang_code;
+
+commit;
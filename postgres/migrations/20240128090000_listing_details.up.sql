@@ -0,0 +1,73 @@
+BEGIN;
+
+ALTER TABLE listings
+  ADD COLUMN IF NOT EXISTS description text NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS condition varchar(255) CHECK (condition IN ('NEW', 'USED', 'REFURBISHED')),
+  ADD COLUMN IF NOT EXISTS brand varchar(255) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS lang_code varchar(2) REFERENCES languages (code) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS listings_tr (
+  listing_id uuid NOT NULL,
+  lang_code varchar(2) NOT NULL,
+  title varchar(255) NOT NULL,
+  description text NOT NULL DEFAULT '',
+  FOREIGN KEY (listing_id) REFERENCES listings (id) ON DELETE CASCADE,
+  FOREIGN KEY (lang_code) REFERENCES languages (code) ON DELETE CASCADE,
+  PRIMARY KEY (listing_id, lang_code)
+);
+
+-- Descriptions are stored as sanitized HTML, tags are replaced with spaces
+-- before they are indexed. Translations weigh as much as what they translate.
+CREATE OR REPLACE FUNCTION listing_search_document(lid uuid)
+RETURNS tsvector
+AS $$
+  SELECT
+    setweight(to_tsvector('english', t.title), 'A') ||
+    setweight(to_tsvector('russian', t.title), 'A') ||
+    setweight(to_tsvector('simple', t.title), 'A') ||
+    setweight(to_tsvector('simple', l.brand), 'B') ||
+    setweight(to_tsvector('simple', coalesce((
+      SELECT string_agg(ct.title, ' ') FROM categories_tr ct WHERE ct.category_id = l.category_id
+    ), '')), 'C') ||
+    setweight(to_tsvector('english', t.description), 'D') ||
+    setweight(to_tsvector('russian', t.description), 'D') ||
+    setweight(to_tsvector('simple', t.description), 'D')
+  FROM listings l
+  CROSS JOIN LATERAL (
+    SELECT
+      string_agg(x.title, ' ') AS title,
+      regexp_replace(string_agg(x.description, ' '), '<[^>]*>', ' ', 'g') AS description
+    FROM (
+      SELECT l.title, l.description
+      UNION ALL
+      SELECT tr.title, tr.description FROM listings_tr tr WHERE tr.listing_id = l.id
+    ) x
+  ) t
+  WHERE l.id = lid;
+$$
+LANGUAGE sql STABLE;
+
+DROP TRIGGER IF EXISTS trigger_refresh_listing_search ON listings;
+
+CREATE TRIGGER trigger_refresh_listing_search
+  AFTER INSERT OR UPDATE OF title, description, brand, category_id ON listings
+  FOR EACH ROW
+  EXECUTE PROCEDURE refresh_listing_search();
+
+CREATE OR REPLACE FUNCTION refresh_translated_listing_search()
+RETURNS TRIGGER
+AS $$
+BEGIN
+  UPDATE listing_search s SET document = listing_search_document(s.listing_id)
+    FROM listings l WHERE l.id = s.listing_id AND l.id = coalesce(NEW.listing_id, OLD.listing_id);
+  RETURN NULL;
+END;
+$$
+LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_refresh_translated_listing_search
+  AFTER INSERT OR UPDATE OR DELETE ON listings_tr
+  FOR EACH ROW
+  EXECUTE PROCEDURE refresh_translated_listing_search();
+
+COMMIT;
@@ -0,0 +1,15 @@
+begin;
+
+drop function if exists insert_location;
+drop function if exists haversine_km;
+
+alter table listings
+  drop constraint if exists listings_point_check,
+  drop column if exists location_id,
+  drop column if exists lat,
+  drop column if exists lng;
+
+drop table if exists locations_tr;
+drop table if exists locations;
+
+commit;
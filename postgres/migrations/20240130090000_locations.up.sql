@@ -0,0 +1,125 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS locations (
+  id serial PRIMARY KEY,
+  parent_id int,
+  kind varchar(255) NOT NULL CHECK (kind IN ('REGION', 'DISTRICT', 'CITY')),
+  lat double precision NOT NULL,
+  lng double precision NOT NULL,
+  FOREIGN KEY (parent_id) REFERENCES locations (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_locations_parent_id ON locations (parent_id);
+
+CREATE TABLE IF NOT EXISTS locations_tr (
+  location_id int NOT NULL,
+  lang_code varchar(255) NOT NULL,
+  name varchar(255) DEFAULT '',
+  FOREIGN KEY (location_id) REFERENCES locations (id) ON DELETE CASCADE,
+  FOREIGN KEY (lang_code) REFERENCES languages (code) ON DELETE CASCADE,
+  PRIMARY KEY (location_id, lang_code)
+);
+
+ALTER TABLE listings
+  ADD COLUMN IF NOT EXISTS location_id int REFERENCES locations (id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS lat double precision CHECK (lat BETWEEN -90 AND 90),
+  ADD COLUMN IF NOT EXISTS lng double precision CHECK (lng BETWEEN -180 AND 180),
+  ADD CONSTRAINT listings_point_check CHECK ((lat IS NULL) = (lng IS NULL));
+
+CREATE INDEX idx_listings_location_id ON listings (location_id);
+CREATE INDEX idx_listings_lat_lng ON listings (lat, lng) WHERE lat IS NOT NULL;
+
+-- haversine_km is the great-circle distance between two points in
+-- kilometers.
+CREATE OR REPLACE FUNCTION haversine_km(lat1 double precision, lng1 double precision, lat2 double precision, lng2 double precision)
+RETURNS double precision
+AS $$
+  SELECT 2 * 6371.0 * asin(least(1, sqrt(
+    power(sin(radians(lat2 - lat1) / 2), 2) +
+    cos(radians(lat1)) * cos(radians(lat2)) * power(sin(radians(lng2 - lng1) / 2), 2)
+  )));
+$$
+LANGUAGE sql IMMUTABLE STRICT;
+
+CREATE OR REPLACE FUNCTION insert_location(kind varchar(255), parent_id int, lat double precision, lng double precision, en varchar(255), ru varchar(255), uz varchar(255))
+RETURNS int
+AS $$
+DECLARE
+  location_id int;
+BEGIN
+  INSERT INTO locations (kind, parent_id, lat, lng) VALUES (kind, parent_id, lat, lng) RETURNING id INTO location_id;
+  INSERT INTO locations_tr (location_id, lang_code, name)
+    VALUES (location_id, 'en', en), (location_id, 'ru', ru), (location_id, 'uz', uz);
+  RETURN location_id;
+END;
+$$
+LANGUAGE plpgsql;
+
+-- Regions of Uzbekistan with the cities subordinate to them, and the
+-- districts of Tashkent.
+DO $$
+DECLARE
+  region int;
+BEGIN
+  region := insert_location('REGION', NULL, 41.2995, 69.2401, 'Tashkent', 'Ташкент', 'Toshkent shahri');
+  PERFORM insert_location('DISTRICT', region, 41.2090, 69.3340, 'Bektemir District', 'Бектемирский район', 'Bektemir tumani');
+  PERFORM insert_location('DISTRICT', region, 41.2756, 69.2034, 'Chilanzar District', 'Чиланзарский район', 'Chilonzor tumani');
+  PERFORM insert_location('DISTRICT', region, 41.2920, 69.3380, 'Yashnabad District', 'Яшнабадский район', 'Yashnobod tumani');
+  PERFORM insert_location('DISTRICT', region, 41.2870, 69.2790, 'Mirabad District', 'Мирабадский район', 'Mirobod tumani');
+  PERFORM insert_location('DISTRICT', region, 41.3380, 69.3350, 'Mirzo Ulugbek District', 'Мирзо-Улугбекский район', 'Mirzo Ulug''bek tumani');
+  PERFORM insert_location('DISTRICT', region, 41.2260, 69.2190, 'Sergeli District', 'Сергелийский район', 'Sergeli tumani');
+  PERFORM insert_location('DISTRICT', region, 41.3230, 69.2280, 'Shaykhantakhur District', 'Шайхантахурский район', 'Shayxontohur tumani');
+  PERFORM insert_location('DISTRICT', region, 41.3500, 69.2150, 'Almazar District', 'Алмазарский район', 'Olmazor tumani');
+  PERFORM insert_location('DISTRICT', region, 41.2900, 69.1700, 'Uchtepa District', 'Учтепинский район', 'Uchtepa tumani');
+  PERFORM insert_location('DISTRICT', region, 41.2860, 69.2530, 'Yakkasaray District', 'Яккасарайский район', 'Yakkasaroy tumani');
+  PERFORM insert_location('DISTRICT', region, 41.3640, 69.2870, 'Yunusabad District', 'Юнусабадский район', 'Yunusobod tumani');
+  PERFORM insert_location('DISTRICT', region, 41.2000, 69.2000, 'Yangihayot District', 'Янгихаётский район', 'Yangihayot tumani');
+
+  region := insert_location('REGION', NULL, 41.0422, 69.3581, 'Tashkent Region', 'Ташкентская область', 'Toshkent viloyati');
+  PERFORM insert_location('CITY', region, 41.0422, 69.3581, 'Nurafshon', 'Нурафшан', 'Nurafshon');
+  PERFORM insert_location('CITY', region, 41.4689, 69.5822, 'Chirchiq', 'Чирчик', 'Chirchiq');
+  PERFORM insert_location('CITY', region, 41.0167, 70.1436, 'Angren', 'Ангрен', 'Angren');
+  PERFORM insert_location('CITY', region, 40.8447, 69.5983, 'Olmaliq', 'Алмалык', 'Olmaliq');
+
+  region := insert_location('REGION', NULL, 42.4600, 59.6100, 'Republic of Karakalpakstan', 'Республика Каракалпакстан', 'Qoraqalpog''iston Respublikasi');
+  PERFORM insert_location('CITY', region, 42.4600, 59.6100, 'Nukus', 'Нукус', 'Nukus');
+
+  region := insert_location('REGION', NULL, 40.7821, 72.3442, 'Andijan Region', 'Андижанская область', 'Andijon viloyati');
+  PERFORM insert_location('CITY', region, 40.7821, 72.3442, 'Andijan', 'Андижан', 'Andijon');
+
+  region := insert_location('REGION', NULL, 39.7747, 64.4286, 'Bukhara Region', 'Бухарская область', 'Buxoro viloyati');
+  PERFORM insert_location('CITY', region, 39.7747, 64.4286, 'Bukhara', 'Бухара', 'Buxoro');
+
+  region := insert_location('REGION', NULL, 40.3842, 71.7843, 'Fergana Region', 'Ферганская область', 'Farg''ona viloyati');
+  PERFORM insert_location('CITY', region, 40.3842, 71.7843, 'Fergana', 'Фергана', 'Farg''ona');
+  PERFORM insert_location('CITY', region, 40.5286, 70.9425, 'Kokand', 'Коканд', 'Qo''qon');
+  PERFORM insert_location('CITY', region, 40.4714, 71.7244, 'Margilan', 'Маргилан', 'Marg''ilon');
+
+  region := insert_location('REGION', NULL, 40.1158, 67.8422, 'Jizzakh Region', 'Джизакская область', 'Jizzax viloyati');
+  PERFORM insert_location('CITY', region, 40.1158, 67.8422, 'Jizzakh', 'Джизак', 'Jizzax');
+
+  region := insert_location('REGION', NULL, 38.8606, 65.7891, 'Kashkadarya Region', 'Кашкадарьинская область', 'Qashqadaryo viloyati');
+  PERFORM insert_location('CITY', region, 38.8606, 65.7891, 'Karshi', 'Карши', 'Qarshi');
+
+  region := insert_location('REGION', NULL, 41.5500, 60.6333, 'Khorezm Region', 'Хорезмская область', 'Xorazm viloyati');
+  PERFORM insert_location('CITY', region, 41.5500, 60.6333, 'Urgench', 'Ургенч', 'Urganch');
+  PERFORM insert_location('CITY', region, 41.3783, 60.3639, 'Khiva', 'Хива', 'Xiva');
+
+  region := insert_location('REGION', NULL, 40.9983, 71.6726, 'Namangan Region', 'Наманганская область', 'Namangan viloyati');
+  PERFORM insert_location('CITY', region, 40.9983, 71.6726, 'Namangan', 'Наманган', 'Namangan');
+
+  region := insert_location('REGION', NULL, 40.0844, 65.3792, 'Navoiy Region', 'Навоийская область', 'Navoiy viloyati');
+  PERFORM insert_location('CITY', region, 40.0844, 65.3792, 'Navoiy', 'Навои', 'Navoiy');
+
+  region := insert_location('REGION', NULL, 39.6542, 66.9597, 'Samarkand Region', 'Самаркандская область', 'Samarqand viloyati');
+  PERFORM insert_location('CITY', region, 39.6542, 66.9597, 'Samarkand', 'Самарканд', 'Samarqand');
+
+  region := insert_location('REGION', NULL, 37.2242, 67.2783, 'Surkhandarya Region', 'Сурхандарьинская область', 'Surxondaryo viloyati');
+  PERFORM insert_location('CITY', region, 37.2242, 67.2783, 'Termez', 'Термез', 'Termiz');
+
+  region := insert_location('REGION', NULL, 40.4897, 68.7842, 'Syrdarya Region', 'Сырдарьинская область', 'Sirdaryo viloyati');
+  PERFORM insert_location('CITY', region, 40.4897, 68.7842, 'Gulistan', 'Гулистан', 'Guliston');
+END;
+$$;
+
+COMMIT;
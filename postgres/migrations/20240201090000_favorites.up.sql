@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS favorites (
+  id uuid PRIMARY KEY,
+  user_id uuid NOT NULL,
+  listing_id uuid NOT NULL,
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  FOREIGN KEY (listing_id) REFERENCES listings (id) ON DELETE CASCADE,
+  UNIQUE (user_id, listing_id)
+);
+
+CREATE INDEX idx_favorites_listing_id ON favorites (listing_id);
+CREATE INDEX idx_favorites_user_id_id ON favorites (user_id, id DESC);
+
+COMMIT;
@@ -0,0 +1,7 @@
+begin;
+
+drop table if exists saved_search_settings;
+drop table if exists saved_search_matches;
+drop table if exists saved_searches;
+
+commit;
@@ -0,0 +1,42 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+  id uuid PRIMARY KEY,
+  user_id uuid NOT NULL,
+  name varchar(255) DEFAULT '',
+  query varchar(255) DEFAULT '',
+  category_id int,
+  min_price int,
+  max_price int,
+  price_currency varchar(255),
+  attrs jsonb DEFAULT '{}'::jsonb NOT NULL,
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_saved_searches_user_id ON saved_searches (user_id);
+CREATE INDEX idx_saved_searches_category_id ON saved_searches (category_id);
+
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+  saved_search_id uuid NOT NULL,
+  listing_id uuid NOT NULL,
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  notified_at timestamp with time zone,
+  FOREIGN KEY (saved_search_id) REFERENCES saved_searches (id) ON DELETE CASCADE,
+  FOREIGN KEY (listing_id) REFERENCES listings (id) ON DELETE CASCADE,
+  PRIMARY KEY (saved_search_id, listing_id)
+);
+
+CREATE INDEX idx_saved_search_matches_pending ON saved_search_matches (saved_search_id) WHERE notified_at IS NULL;
+
+-- saved_search_settings holds when users were last told about matches and
+-- how often they want to be.
+CREATE TABLE IF NOT EXISTS saved_search_settings (
+  user_id uuid PRIMARY KEY,
+  frequency varchar(255) DEFAULT 'INSTANT' NOT NULL CHECK (frequency IN ('INSTANT', 'DAILY', 'WEEKLY')),
+  notified_at timestamp with time zone,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+COMMIT;
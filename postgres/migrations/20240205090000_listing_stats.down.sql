@@ -0,0 +1,6 @@
+begin;
+
+drop table if exists listing_stats;
+drop table if exists listing_visitors;
+
+commit;
@@ -0,0 +1,26 @@
+BEGIN;
+
+-- listing_visitors remembers who was counted for a listing on a day. Only
+-- recent days are kept.
+CREATE TABLE IF NOT EXISTS listing_visitors (
+  listing_id uuid NOT NULL,
+  kind varchar(255) NOT NULL CHECK (kind IN ('IMPRESSION', 'VIEW', 'CONTACT_REVEAL')),
+  day date NOT NULL,
+  visitor varchar(255) NOT NULL,
+  FOREIGN KEY (listing_id) REFERENCES listings (id) ON DELETE CASCADE,
+  PRIMARY KEY (listing_id, kind, day, visitor)
+);
+
+CREATE INDEX idx_listing_visitors_day ON listing_visitors (day);
+
+CREATE TABLE IF NOT EXISTS listing_stats (
+  listing_id uuid NOT NULL,
+  day date NOT NULL,
+  impressions int DEFAULT 0 NOT NULL,
+  views int DEFAULT 0 NOT NULL,
+  contact_reveals int DEFAULT 0 NOT NULL,
+  FOREIGN KEY (listing_id) REFERENCES listings (id) ON DELETE CASCADE,
+  PRIMARY KEY (listing_id, day)
+);
+
+COMMIT;
@@ -0,0 +1,10 @@
+begin;
+
+drop trigger if exists trigger_start_listing_expiry on listings;
+drop function if exists start_listing_expiry;
+drop function if exists listing_lifetime;
+drop table if exists listing_expiry;
+
+alter table categories drop column if exists lifetime_days;
+
+commit;
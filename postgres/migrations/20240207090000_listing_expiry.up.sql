@@ -0,0 +1,60 @@
+BEGIN;
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS lifetime_days int CHECK (lifetime_days > 0);
+
+-- listing_expiry is kept apart from listings so that renewals and warnings
+-- don't touch listings.updated_at, which edits are checked against.
+CREATE TABLE IF NOT EXISTS listing_expiry (
+  listing_id uuid PRIMARY KEY,
+  expires_at timestamp with time zone NOT NULL,
+  warned_at timestamp with time zone,
+  FOREIGN KEY (listing_id) REFERENCES listings (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_listing_expiry_expires_at ON listing_expiry (expires_at);
+
+-- listing_lifetime is how long listings of a category stay active: the
+-- lifetime of the category or of its closest ancestor that has one, 30 days
+-- otherwise.
+CREATE OR REPLACE FUNCTION listing_lifetime(cid int)
+RETURNS interval
+AS $$
+  WITH RECURSIVE c AS (
+    SELECT id, parent_id, lifetime_days, 0 AS depth FROM categories WHERE id = cid
+    UNION ALL
+    SELECT p.id, p.parent_id, p.lifetime_days, c.depth + 1 FROM categories p JOIN c ON p.id = c.parent_id
+  )
+  SELECT make_interval(days => coalesce((
+    SELECT lifetime_days FROM c WHERE lifetime_days IS NOT NULL ORDER BY depth LIMIT 1
+  ), 30));
+$$
+LANGUAGE sql STABLE;
+
+-- Listings start their lifetime over whenever they are published.
+CREATE OR REPLACE FUNCTION start_listing_expiry()
+RETURNS TRIGGER
+AS $$
+BEGIN
+  IF TG_OP = 'UPDATE' AND OLD.status = 'ACTIVE' THEN
+    RETURN NULL;
+  END IF;
+  INSERT INTO listing_expiry (listing_id, expires_at)
+    VALUES (NEW.id, now() + listing_lifetime(NEW.category_id))
+    ON CONFLICT (listing_id) DO UPDATE SET expires_at = excluded.expires_at, warned_at = NULL;
+  RETURN NULL;
+END;
+$$
+LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_start_listing_expiry
+  AFTER INSERT OR UPDATE OF status ON listings
+  FOR EACH ROW
+  WHEN (NEW.status = 'ACTIVE')
+  EXECUTE PROCEDURE start_listing_expiry();
+
+-- Listings that are already active get a full lifetime from now on rather
+-- than expiring all at once.
+INSERT INTO listing_expiry (listing_id, expires_at)
+  SELECT id, now() + listing_lifetime(category_id) FROM listings WHERE status = 'ACTIVE';
+
+COMMIT;
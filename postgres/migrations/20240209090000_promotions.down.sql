@@ -0,0 +1,8 @@
+begin;
+
+drop table if exists promotion_purchases;
+drop table if exists promotion_product_prices;
+drop table if exists promotion_products;
+drop table if exists admins;
+
+commit;
@@ -0,0 +1,69 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS admins (
+  user_id uuid PRIMARY KEY,
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS promotion_products (
+  id serial PRIMARY KEY,
+  code varchar(50) UNIQUE NOT NULL,
+  kind varchar(20) NOT NULL CHECK (kind IN ('TOP', 'HIGHLIGHT', 'URGENT')),
+  duration_days int NOT NULL CHECK (duration_days > 0),
+  active boolean DEFAULT TRUE NOT NULL,
+  created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS promotion_product_prices (
+  product_id int NOT NULL,
+  currency varchar(10) NOT NULL,
+  amount bigint NOT NULL CHECK (amount > 0),
+  PRIMARY KEY (product_id, currency),
+  FOREIGN KEY (product_id) REFERENCES promotion_products (id) ON DELETE CASCADE,
+  FOREIGN KEY (currency) REFERENCES currencies (code)
+);
+
+-- Purchases copy the kind, duration and price of their product so that
+-- later changes to the product don't affect them.
+CREATE TABLE IF NOT EXISTS promotion_purchases (
+  id uuid PRIMARY KEY,
+  listing_id uuid NOT NULL,
+  user_id uuid NOT NULL,
+  product_id int NOT NULL,
+  kind varchar(20) NOT NULL,
+  duration_days int NOT NULL,
+  amount bigint NOT NULL,
+  currency varchar(10) NOT NULL,
+  status varchar(20) DEFAULT 'PENDING' NOT NULL CHECK (status IN ('PENDING', 'PAID', 'CANCELED')),
+  provider varchar(20),
+  transaction_id varchar(255),
+  paid_at timestamp with time zone,
+  starts_at timestamp with time zone,
+  ends_at timestamp with time zone,
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  UNIQUE (provider, transaction_id),
+  FOREIGN KEY (listing_id) REFERENCES listings (id) ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  FOREIGN KEY (product_id) REFERENCES promotion_products (id),
+  FOREIGN KEY (currency) REFERENCES currencies (code)
+);
+
+CREATE INDEX idx_promotion_purchases_windows ON promotion_purchases (listing_id, kind, ends_at) WHERE status = 'PAID';
+
+INSERT INTO promotion_products (code, kind, duration_days)
+  VALUES ('top_3', 'TOP', 3), ('top_7', 'TOP', 7), ('highlight_7', 'HIGHLIGHT', 7), ('urgent_7', 'URGENT', 7)
+  ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO promotion_product_prices (product_id, currency, amount)
+  SELECT p.id, v.currency, v.amount
+  FROM (VALUES
+    ('top_3', 'UZS', 1500000), ('top_3', 'USD', 120),
+    ('top_7', 'UZS', 3000000), ('top_7', 'USD', 240),
+    ('highlight_7', 'UZS', 1000000), ('highlight_7', 'USD', 80),
+    ('urgent_7', 'UZS', 1000000), ('urgent_7', 'USD', 80)
+  ) v (code, currency, amount)
+  JOIN promotion_products p ON p.code = v.code
+  ON CONFLICT (product_id, currency) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,8 @@
+begin;
+
+drop function if exists insert_report_reason;
+drop table if exists reports cascade;
+drop table if exists report_reasons_tr cascade;
+drop table if exists report_reasons cascade;
+
+commit;
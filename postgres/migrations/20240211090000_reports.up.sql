@@ -0,0 +1,60 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS report_reasons (
+  code varchar(255) PRIMARY KEY,
+  active boolean DEFAULT TRUE
+);
+
+CREATE TABLE IF NOT EXISTS report_reasons_tr (
+  reason_code varchar(255) NOT NULL,
+  lang_code varchar(255) NOT NULL,
+  name varchar(255) DEFAULT '',
+  FOREIGN KEY (reason_code) REFERENCES report_reasons (code) ON DELETE CASCADE,
+  FOREIGN KEY (lang_code) REFERENCES languages (code) ON DELETE CASCADE,
+  PRIMARY KEY (reason_code, lang_code)
+);
+
+-- Targets aren't foreign keys since they live in different tables depending
+-- on target_kind.
+CREATE TABLE IF NOT EXISTS reports (
+  id uuid PRIMARY KEY,
+  reporter_id uuid NOT NULL,
+  target_kind varchar(20) NOT NULL CHECK (target_kind IN ('LISTING', 'USER', 'MESSAGE')),
+  target_id uuid NOT NULL,
+  reason_code varchar(255) NOT NULL,
+  detail text DEFAULT '' NOT NULL,
+  status varchar(20) DEFAULT 'OPEN' NOT NULL CHECK (status IN ('OPEN', 'RESOLVED')),
+  outcome varchar(20) CHECK (outcome IN ('ACTION_TAKEN', 'DISMISSED')),
+  moderator_id uuid,
+  comment text DEFAULT '' NOT NULL,
+  resolved_at timestamp with time zone,
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  FOREIGN KEY (reporter_id) REFERENCES users (id) ON DELETE CASCADE,
+  FOREIGN KEY (reason_code) REFERENCES report_reasons (code),
+  FOREIGN KEY (moderator_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_reports_open_reporter ON reports (reporter_id, target_kind, target_id) WHERE status = 'OPEN';
+CREATE INDEX idx_reports_open_target ON reports (target_kind, target_id) WHERE status = 'OPEN';
+
+CREATE OR REPLACE FUNCTION insert_report_reason(code varchar(255), en varchar(255), ru varchar(255), uz varchar(255))
+RETURNS varchar(255)
+AS $$
+BEGIN
+  INSERT INTO report_reasons (code) VALUES (code);
+  INSERT INTO report_reasons_tr (reason_code, lang_code, name)
+    VALUES (code, 'en', en), (code, 'ru', ru), (code, 'uz', uz);
+  RETURN code;
+END;
+$$
+LANGUAGE plpgsql;
+
+select insert_report_reason('FRAUD', 'Fraud or scam', 'Мошенничество', 'Firibgarlik');
+select insert_report_reason('SPAM', 'Spam', 'Спам', 'Spam');
+select insert_report_reason('PROHIBITED_ITEM', 'Prohibited item', 'Запрещённый товар', 'Taqiqlangan mahsulot');
+select insert_report_reason('OFFENSIVE', 'Offensive content', 'Оскорбительный контент', 'Haqoratli kontent');
+select insert_report_reason('ALREADY_SOLD', 'Already sold', 'Уже продано', 'Allaqachon sotilgan');
+select insert_report_reason('WRONG_PRICE', 'Wrong price', 'Неверная цена', 'Noto''g''ri narx');
+select insert_report_reason('OTHER', 'Other', 'Другое', 'Boshqa');
+
+COMMIT;
@@ -0,0 +1,7 @@
+begin;
+
+delete from moderation_reasons where code = 'SUSPECTED_FRAUD';
+alter table listing_media drop column if exists phash;
+alter table listings drop column if exists title_simhash;
+
+commit;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- Hashes are computed by the application. Listings created before have no
+-- title hash until their title is edited, and photos processed before have no
+-- photo hash.
+ALTER TABLE listings ADD COLUMN IF NOT EXISTS title_simhash bigint;
+ALTER TABLE listing_media ADD COLUMN IF NOT EXISTS phash bigint;
+
+select insert_moderation_reason('SUSPECTED_FRAUD', 'Suspected fraud', 'Подозрение на мошенничество', 'Firibgarlik gumoni');
+
+COMMIT;
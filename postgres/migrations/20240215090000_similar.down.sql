@@ -0,0 +1,5 @@
+begin;
+
+drop index if exists idx_listing_visitors_visitor;
+
+commit;
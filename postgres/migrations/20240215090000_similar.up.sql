@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Co-views of a listing are found through the other listings its visitors
+-- viewed.
+CREATE INDEX IF NOT EXISTS idx_listing_visitors_visitor ON listing_visitors (visitor, kind);
+
+COMMIT;
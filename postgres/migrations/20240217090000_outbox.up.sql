@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Events are stored in the same transaction as the changes they announce and
+-- published by a relay afterwards, so a change is never committed without its
+-- event.
+CREATE TABLE IF NOT EXISTS outbox (
+  id bigserial PRIMARY KEY,
+  subject varchar(255) NOT NULL,
+  data jsonb NOT NULL,
+  created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMIT;
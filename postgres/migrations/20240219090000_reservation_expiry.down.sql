@@ -0,0 +1,6 @@
+begin;
+
+drop index if exists idx_listing_sku_reservations_expires_at;
+alter table listing_sku_reservations drop column if exists expires_at;
+
+commit;
@@ -0,0 +1,9 @@
+BEGIN;
+
+-- Reservations made before expiry was tracked expire right away.
+ALTER TABLE listing_sku_reservations ADD COLUMN IF NOT EXISTS expires_at timestamp with time zone DEFAULT now() NOT NULL;
+ALTER TABLE listing_sku_reservations ALTER COLUMN expires_at DROP DEFAULT;
+
+CREATE INDEX idx_listing_sku_reservations_expires_at ON listing_sku_reservations (expires_at) WHERE released_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,10 @@
+begin;
+
+delete from locations
+where kind = 'DISTRICT' and parent_id not in (
+  select l.id from locations l
+  join locations_tr t on t.location_id = l.id and t.lang_code = 'en'
+  where l.kind = 'REGION' and t.name = 'Tashkent'
+);
+
+commit;
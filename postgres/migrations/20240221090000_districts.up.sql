@@ -0,0 +1,212 @@
+BEGIN;
+
+CREATE OR REPLACE FUNCTION region_location(en varchar(255))
+RETURNS int
+AS $$
+  SELECT l.id FROM locations l
+  JOIN locations_tr t ON t.location_id = l.id AND t.lang_code = 'en'
+  WHERE l.kind = 'REGION' AND t.name = en;
+$$
+LANGUAGE sql STABLE;
+
+-- Districts of the regions outside of Tashkent. Points are the approximate
+-- administrative centers of the districts. Cities subordinate to a region are
+-- on the level of its districts and stay where they are.
+DO $$
+DECLARE
+  region int;
+BEGIN
+  region := region_location('Tashkent Region');
+  PERFORM insert_location('DISTRICT', region, 40.9700, 69.3100, 'Bekabad District', 'Бекабадский район', 'Bekobod tumani');
+  PERFORM insert_location('DISTRICT', region, 41.5600, 69.7700, 'Bostanliq District', 'Бостанлыкский район', 'Bo''stonliq tumani');
+  PERFORM insert_location('DISTRICT', region, 40.8100, 69.2000, 'Buka District', 'Букинский район', 'Bo''ka tumani');
+  PERFORM insert_location('DISTRICT', region, 40.9400, 68.7600, 'Chinaz District', 'Чиназский район', 'Chinoz tumani');
+  PERFORM insert_location('DISTRICT', region, 41.3900, 69.4600, 'Qibray District', 'Кибрайский район', 'Qibray tumani');
+  PERFORM insert_location('DISTRICT', region, 40.9100, 69.6400, 'Akhangaran District', 'Ахангаранский район', 'Ohangaron tumani');
+  PERFORM insert_location('DISTRICT', region, 40.8700, 69.0500, 'Akkurgan District', 'Аккурганский район', 'Oqqo''rg''on tumani');
+  PERFORM insert_location('DISTRICT', region, 41.2900, 69.6800, 'Parkent District', 'Паркентский район', 'Parkent tumani');
+  PERFORM insert_location('DISTRICT', region, 40.9000, 69.3500, 'Piskent District', 'Пскентский район', 'Piskent tumani');
+  PERFORM insert_location('DISTRICT', region, 40.8200, 69.0300, 'Quyi Chirchiq District', 'Куйичирчикский район', 'Quyi Chirchiq tumani');
+  PERFORM insert_location('DISTRICT', region, 41.0300, 69.3000, 'Orta Chirchiq District', 'Уртачирчикский район', 'O''rta Chirchiq tumani');
+  PERFORM insert_location('DISTRICT', region, 41.0900, 69.0200, 'Yangiyul District', 'Янгиюльский район', 'Yangiyo''l tumani');
+  PERFORM insert_location('DISTRICT', region, 41.3300, 69.5800, 'Yuqori Chirchiq District', 'Юкоричирчикский район', 'Yuqori Chirchiq tumani');
+  PERFORM insert_location('DISTRICT', region, 41.1900, 69.1600, 'Zangiota District', 'Зангиатинский район', 'Zangiota tumani');
+  PERFORM insert_location('DISTRICT', region, 41.4000, 69.2000, 'Tashkent District', 'Ташкентский район', 'Toshkent tumani');
+
+  region := region_location('Republic of Karakalpakstan');
+  PERFORM insert_location('DISTRICT', region, 42.1200, 60.0600, 'Amudarya District', 'Амударьинский район', 'Amudaryo tumani');
+  PERFORM insert_location('DISTRICT', region, 41.6900, 60.7500, 'Beruniy District', 'Берунийский район', 'Beruniy tumani');
+  PERFORM insert_location('DISTRICT', region, 43.0000, 59.4000, 'Bozatau District', 'Бозатауский район', 'Bo''zatov tumani');
+  PERFORM insert_location('DISTRICT', region, 42.9400, 59.7700, 'Chimbay District', 'Чимбайский район', 'Chimboy tumani');
+  PERFORM insert_location('DISTRICT', region, 41.8600, 60.9600, 'Ellikqala District', 'Элликкалинский район', 'Ellikqal''a tumani');
+  PERFORM insert_location('DISTRICT', region, 42.7800, 59.6100, 'Kegeyli District', 'Кегейлийский район', 'Kegeyli tumani');
+  PERFORM insert_location('DISTRICT', region, 42.8300, 59.0000, 'Kanlikul District', 'Канлыкульский район', 'Qanliko''l tumani');
+  PERFORM insert_location('DISTRICT', region, 43.0300, 60.0200, 'Karauzyak District', 'Караузякский район', 'Qorao''zak tumani');
+  PERFORM insert_location('DISTRICT', region, 43.0500, 58.8400, 'Kungrad District', 'Кунградский район', 'Qo''ng''irot tumani');
+  PERFORM insert_location('DISTRICT', region, 43.7700, 59.0200, 'Muynak District', 'Муйнакский район', 'Mo''ynoq tumani');
+  PERFORM insert_location('DISTRICT', region, 42.6200, 59.8600, 'Nukus District', 'Нукусский район', 'Nukus tumani');
+  PERFORM insert_location('DISTRICT', region, 42.7100, 58.9200, 'Shumanay District', 'Шуманайский район', 'Shumanay tumani');
+  PERFORM insert_location('DISTRICT', region, 42.3200, 59.6000, 'Takhiatash District', 'Тахиаташский район', 'Taxiatosh tumani');
+  PERFORM insert_location('DISTRICT', region, 43.0200, 60.3000, 'Takhtakupir District', 'Тахтакупырский район', 'Taxtako''pir tumani');
+  PERFORM insert_location('DISTRICT', region, 41.5500, 61.0000, 'Turtkul District', 'Турткульский район', 'To''rtko''l tumani');
+  PERFORM insert_location('DISTRICT', region, 42.4000, 59.4600, 'Khodjeyli District', 'Ходжейлийский район', 'Xo''jayli tumani');
+
+  region := region_location('Andijan Region');
+  PERFORM insert_location('DISTRICT', region, 40.8600, 72.2900, 'Andijan District', 'Андижанский район', 'Andijon tumani');
+  PERFORM insert_location('DISTRICT', region, 40.6400, 72.2400, 'Asaka District', 'Асакинский район', 'Asaka tumani');
+  PERFORM insert_location('DISTRICT', region, 40.8900, 71.8600, 'Baliqchi District', 'Балыкчинский район', 'Baliqchi tumani');
+  PERFORM insert_location('DISTRICT', region, 40.6800, 71.9100, 'Buston District', 'Бустонский район', 'Bo''ston tumani');
+  PERFORM insert_location('DISTRICT', region, 40.6200, 72.5000, 'Bulakbashi District', 'Булакбашинский район', 'Buloqboshi tumani');
+  PERFORM insert_location('DISTRICT', region, 40.9000, 72.2500, 'Izboskan District', 'Избасканский район', 'Izboskan tumani');
+  PERFORM insert_location('DISTRICT', region, 40.7200, 72.6400, 'Jalaquduq District', 'Джалакудукский район', 'Jalaquduq tumani');
+  PERFORM insert_location('DISTRICT', region, 40.6700, 72.5600, 'Khojaabad District', 'Ходжаабадский район', 'Xo''jaobod tumani');
+  PERFORM insert_location('DISTRICT', region, 40.7300, 72.7600, 'Kurgantepa District', 'Кургантепинский район', 'Qo''rg''ontepa tumani');
+  PERFORM insert_location('DISTRICT', region, 40.5000, 72.3200, 'Marhamat District', 'Мархаматский район', 'Marhamat tumani');
+  PERFORM insert_location('DISTRICT', region, 40.7300, 72.1800, 'Oltinkul District', 'Алтынкульский район', 'Oltinko''l tumani');
+  PERFORM insert_location('DISTRICT', region, 40.9300, 72.5000, 'Pakhtaabad District', 'Пахтаабадский район', 'Paxtaobod tumani');
+  PERFORM insert_location('DISTRICT', region, 40.7100, 72.0600, 'Shahrikhan District', 'Шахриханский район', 'Shahrixon tumani');
+  PERFORM insert_location('DISTRICT', region, 40.7600, 71.7000, 'Ulugnor District', 'Улугнорский район', 'Ulug''nor tumani');
+
+  region := region_location('Bukhara Region');
+  PERFORM insert_location('DISTRICT', region, 39.4200, 63.8000, 'Olot District', 'Алатский район', 'Olot tumani');
+  PERFORM insert_location('DISTRICT', region, 39.8600, 64.4500, 'Bukhara District', 'Бухарский район', 'Buxoro tumani');
+  PERFORM insert_location('DISTRICT', region, 40.1000, 64.6800, 'Gijduvan District', 'Гиждуванский район', 'G''ijduvon tumani');
+  PERFORM insert_location('DISTRICT', region, 39.7300, 64.1800, 'Jondor District', 'Жондорский район', 'Jondor tumani');
+  PERFORM insert_location('DISTRICT', region, 39.7200, 64.5500, 'Kagan District', 'Каганский район', 'Kogon tumani');
+  PERFORM insert_location('DISTRICT', region, 39.5000, 63.8500, 'Karakul District', 'Каракульский район', 'Qorako''l tumani');
+  PERFORM insert_location('DISTRICT', region, 39.5000, 64.8000, 'Karaulbazar District', 'Караулбазарский район', 'Qorovulbozor tumani');
+  PERFORM insert_location('DISTRICT', region, 40.1000, 64.2500, 'Peshku District', 'Пешкунский район', 'Peshku tumani');
+  PERFORM insert_location('DISTRICT', region, 39.9300, 64.3800, 'Romitan District', 'Ромитанский район', 'Romitan tumani');
+  PERFORM insert_location('DISTRICT', region, 40.1200, 64.5000, 'Shofirkon District', 'Шафирканский район', 'Shofirkon tumani');
+  PERFORM insert_location('DISTRICT', region, 40.0300, 64.5200, 'Vobkent District', 'Вабкентский район', 'Vobkent tumani');
+
+  region := region_location('Fergana Region');
+  PERFORM insert_location('DISTRICT', region, 40.3900, 71.4900, 'Oltiariq District', 'Алтыарыкский район', 'Oltiariq tumani');
+  PERFORM insert_location('DISTRICT', region, 40.4600, 71.2200, 'Bagdad District', 'Багдадский район', 'Bag''dod tumani');
+  PERFORM insert_location('DISTRICT', region, 40.4300, 70.6100, 'Beshariq District', 'Бешарыкский район', 'Beshariq tumani');
+  PERFORM insert_location('DISTRICT', region, 40.6000, 71.0200, 'Buvayda District', 'Бувайдинский район', 'Buvayda tumani');
+  PERFORM insert_location('DISTRICT', region, 40.5800, 70.9100, 'Dangara District', 'Дангаринский район', 'Dang''ara tumani');
+  PERFORM insert_location('DISTRICT', region, 40.1800, 71.7300, 'Fergana District', 'Ферганский район', 'Farg''ona tumani');
+  PERFORM insert_location('DISTRICT', region, 40.5300, 70.7500, 'Furqat District', 'Фуркатский район', 'Furqat tumani');
+  PERFORM insert_location('DISTRICT', region, 40.5000, 71.6000, 'Qushtepa District', 'Куштепинский район', 'Qo''shtepa tumani');
+  PERFORM insert_location('DISTRICT', region, 40.5200, 72.0700, 'Quva District', 'Кувинский район', 'Quva tumani');
+  PERFORM insert_location('DISTRICT', region, 40.3600, 71.2800, 'Rishtan District', 'Риштанский район', 'Rishton tumani');
+  PERFORM insert_location('DISTRICT', region, 39.9600, 71.1300, 'Sokh District', 'Сохский район', 'So''x tumani');
+  PERFORM insert_location('DISTRICT', region, 40.4800, 71.7700, 'Tashlaq District', 'Ташлакский район', 'Toshloq tumani');
+  PERFORM insert_location('DISTRICT', region, 40.5300, 71.0500, 'Uchkuprik District', 'Учкуприкский район', 'Uchko''prik tumani');
+  PERFORM insert_location('DISTRICT', region, 40.3800, 70.8200, 'Uzbekistan District', 'Узбекистанский район', 'O''zbekiston tumani');
+  PERFORM insert_location('DISTRICT', region, 40.6600, 71.7400, 'Yozyovon District', 'Язъяванский район', 'Yozyovon tumani');
+
+  region := region_location('Jizzakh Region');
+  PERFORM insert_location('DISTRICT', region, 40.5400, 67.9300, 'Arnasoy District', 'Арнасайский район', 'Arnasoy tumani');
+  PERFORM insert_location('DISTRICT', region, 39.7400, 67.6400, 'Bakhmal District', 'Бахмальский район', 'Baxmal tumani');
+  PERFORM insert_location('DISTRICT', region, 40.5200, 68.0400, 'Dustlik District', 'Дустликский район', 'Do''stlik tumani');
+  PERFORM insert_location('DISTRICT', region, 40.5700, 66.8800, 'Forish District', 'Фаришский район', 'Forish tumani');
+  PERFORM insert_location('DISTRICT', region, 40.0300, 67.5900, 'Gallaorol District', 'Галляаральский район', 'G''allaorol tumani');
+  PERFORM insert_location('DISTRICT', region, 40.1500, 67.8700, 'Sharof Rashidov District', 'Шараф-Рашидовский район', 'Sharof Rashidov tumani');
+  PERFORM insert_location('DISTRICT', region, 40.6600, 68.1700, 'Mirzachul District', 'Мирзачульский район', 'Mirzacho''l tumani');
+  PERFORM insert_location('DISTRICT', region, 40.3200, 67.9500, 'Pakhtakor District', 'Пахтакорский район', 'Paxtakor tumani');
+  PERFORM insert_location('DISTRICT', region, 40.0300, 68.5400, 'Yangiabad District', 'Янгиабадский район', 'Yangiobod tumani');
+  PERFORM insert_location('DISTRICT', region, 40.3900, 67.8200, 'Zafarabad District', 'Зафарабадский район', 'Zafarobod tumani');
+  PERFORM insert_location('DISTRICT', region, 40.0800, 68.1700, 'Zarbdor District', 'Зарбдарский район', 'Zarbdor tumani');
+  PERFORM insert_location('DISTRICT', region, 39.9600, 68.4000, 'Zaamin District', 'Зааминский район', 'Zomin tumani');
+
+  region := region_location('Kashkadarya Region');
+  PERFORM insert_location('DISTRICT', region, 39.0300, 66.5700, 'Chiroqchi District', 'Чиракчинский район', 'Chiroqchi tumani');
+  PERFORM insert_location('DISTRICT', region, 38.3500, 66.5500, 'Dehqonobod District', 'Дехканабадский район', 'Dehqonobod tumani');
+  PERFORM insert_location('DISTRICT', region, 38.6200, 66.2500, 'Guzar District', 'Гузарский район', 'G''uzor tumani');
+  PERFORM insert_location('DISTRICT', region, 38.8200, 66.4600, 'Kamashi District', 'Камашинский район', 'Qamashi tumani');
+  PERFORM insert_location('DISTRICT', region, 38.8200, 65.6500, 'Karshi District', 'Каршинский район', 'Qarshi tumani');
+  PERFORM insert_location('DISTRICT', region, 39.0400, 65.5800, 'Koson District', 'Касанский район', 'Koson tumani');
+  PERFORM insert_location('DISTRICT', region, 38.9600, 65.4200, 'Kasbi District', 'Касбийский район', 'Kasbi tumani');
+  PERFORM insert_location('DISTRICT', region, 39.1200, 66.8800, 'Kitab District', 'Китабский район', 'Kitob tumani');
+  PERFORM insert_location('DISTRICT', region, 39.2300, 66.0000, 'Kukdala District', 'Кукдалинский район', 'Ko''kdala tumani');
+  PERFORM insert_location('DISTRICT', region, 38.8800, 65.1300, 'Mirishkor District', 'Миришкорский район', 'Mirishkor tumani');
+  PERFORM insert_location('DISTRICT', region, 39.2600, 65.1500, 'Mubarek District', 'Мубарекский район', 'Muborak tumani');
+  PERFORM insert_location('DISTRICT', region, 38.6400, 65.6900, 'Nishan District', 'Нишанский район', 'Nishon tumani');
+  PERFORM insert_location('DISTRICT', region, 39.0600, 66.8300, 'Shahrisabz District', 'Шахрисабзский район', 'Shahrisabz tumani');
+  PERFORM insert_location('DISTRICT', region, 38.9800, 66.6800, 'Yakkabog District', 'Яккабагский район', 'Yakkabog'' tumani');
+
+  region := region_location('Khorezm Region');
+  PERFORM insert_location('DISTRICT', region, 41.3300, 60.8400, 'Bagat District', 'Багатский район', 'Bog''ot tumani');
+  PERFORM insert_location('DISTRICT', region, 41.8400, 60.3900, 'Gurlen District', 'Гурленский район', 'Gurlan tumani');
+  PERFORM insert_location('DISTRICT', region, 41.4700, 60.7800, 'Khanka District', 'Ханкинский район', 'Xonqa tumani');
+  PERFORM insert_location('DISTRICT', region, 41.3200, 61.0700, 'Hazarasp District', 'Хазараспский район', 'Hazorasp tumani');
+  PERFORM insert_location('DISTRICT', region, 41.4000, 60.3500, 'Khiva District', 'Хивинский район', 'Xiva tumani');
+  PERFORM insert_location('DISTRICT', region, 41.5400, 60.3500, 'Koshkupyr District', 'Кошкупырский район', 'Qo''shko''pir tumani');
+  PERFORM insert_location('DISTRICT', region, 41.6500, 60.3000, 'Shavat District', 'Шаватский район', 'Shovot tumani');
+  PERFORM insert_location('DISTRICT', region, 41.5500, 60.6000, 'Urgench District', 'Ургенчский район', 'Urganch tumani');
+  PERFORM insert_location('DISTRICT', region, 41.3500, 60.5800, 'Yangiarik District', 'Янгиарыкский район', 'Yangiariq tumani');
+  PERFORM insert_location('DISTRICT', region, 41.7300, 60.5600, 'Yangibazar District', 'Янгибазарский район', 'Yangibozor tumani');
+  PERFORM insert_location('DISTRICT', region, 41.2000, 61.3000, 'Tuprakkala District', 'Тупраккалинский район', 'Tuproqqal''a tumani');
+
+  region := region_location('Namangan Region');
+  PERFORM insert_location('DISTRICT', region, 41.0700, 71.8200, 'Chartak District', 'Чартакский район', 'Chortoq tumani');
+  PERFORM insert_location('DISTRICT', region, 41.0000, 71.2400, 'Chust District', 'Чустский район', 'Chust tumani');
+  PERFORM insert_location('DISTRICT', region, 41.2500, 71.5500, 'Kasansay District', 'Касансайский район', 'Kosonsoy tumani');
+  PERFORM insert_location('DISTRICT', region, 40.8400, 71.3500, 'Mingbulak District', 'Мингбулакский район', 'Mingbuloq tumani');
+  PERFORM insert_location('DISTRICT', region, 40.9400, 71.5500, 'Namangan District', 'Наманганский район', 'Namangan tumani');
+  PERFORM insert_location('DISTRICT', region, 40.9200, 71.9900, 'Naryn District', 'Нарынский район', 'Norin tumani');
+  PERFORM insert_location('DISTRICT', region, 40.8700, 71.1100, 'Pap District', 'Папский район', 'Pop tumani');
+  PERFORM insert_location('DISTRICT', region, 41.0000, 71.5100, 'Turakurgan District', 'Туракурганский район', 'To''raqo''rg''on tumani');
+  PERFORM insert_location('DISTRICT', region, 41.1100, 72.0800, 'Uchkurgan District', 'Учкурганский район', 'Uchqo''rg''on tumani');
+  PERFORM insert_location('DISTRICT', region, 41.0800, 71.9200, 'Uychi District', 'Уйчинский район', 'Uychi tumani');
+  PERFORM insert_location('DISTRICT', region, 41.1900, 71.7300, 'Yangikurgan District', 'Янгикурганский район', 'Yangiqo''rg''on tumani');
+
+  region := region_location('Navoiy Region');
+  PERFORM insert_location('DISTRICT', region, 40.2800, 65.1500, 'Konimex District', 'Канимехский район', 'Konimex tumani');
+  PERFORM insert_location('DISTRICT', region, 40.1400, 65.3600, 'Karmana District', 'Карманинский район', 'Karmana tumani');
+  PERFORM insert_location('DISTRICT', region, 40.0300, 64.8500, 'Qiziltepa District', 'Кызылтепинский район', 'Qiziltepa tumani');
+  PERFORM insert_location('DISTRICT', region, 40.0300, 65.9600, 'Khatirchi District', 'Хатырчинский район', 'Xatirchi tumani');
+  PERFORM insert_location('DISTRICT', region, 40.2100, 65.6000, 'Navbahor District', 'Навбахорский район', 'Navbahor tumani');
+  PERFORM insert_location('DISTRICT', region, 40.5600, 65.6900, 'Nurata District', 'Нуратинский район', 'Nurota tumani');
+  PERFORM insert_location('DISTRICT', region, 41.7500, 64.6200, 'Tamdy District', 'Тамдынский район', 'Tomdi tumani');
+  PERFORM insert_location('DISTRICT', region, 42.1600, 63.5500, 'Uchkuduk District', 'Учкудукский район', 'Uchquduq tumani');
+
+  region := region_location('Samarkand Region');
+  PERFORM insert_location('DISTRICT', region, 39.7600, 67.2700, 'Bulungur District', 'Булунгурский район', 'Bulung''ur tumani');
+  PERFORM insert_location('DISTRICT', region, 39.9700, 66.4900, 'Ishtikhan District', 'Иштыханский район', 'Ishtixon tumani');
+  PERFORM insert_location('DISTRICT', region, 39.7000, 67.0900, 'Jomboy District', 'Джамбайский район', 'Jomboy tumani');
+  PERFORM insert_location('DISTRICT', region, 40.0000, 66.2300, 'Kattakurgan District', 'Каттакурганский район', 'Kattaqo''rg''on tumani');
+  PERFORM insert_location('DISTRICT', region, 40.2700, 66.6700, 'Koshrabot District', 'Кошрабадский район', 'Qo''shrabot tumani');
+  PERFORM insert_location('DISTRICT', region, 39.9200, 65.9300, 'Narpay District', 'Нарпайский район', 'Narpay tumani');
+  PERFORM insert_location('DISTRICT', region, 39.6000, 66.2800, 'Nurabad District', 'Нурабадский район', 'Nurobod tumani');
+  PERFORM insert_location('DISTRICT', region, 39.8700, 66.8400, 'Akdarya District', 'Акдарьинский район', 'Oqdaryo tumani');
+  PERFORM insert_location('DISTRICT', region, 40.0600, 65.6700, 'Pakhtachi District', 'Пахтачийский район', 'Paxtachi tumani');
+  PERFORM insert_location('DISTRICT', region, 40.0200, 66.9300, 'Payariq District', 'Пайарыкский район', 'Payariq tumani');
+  PERFORM insert_location('DISTRICT', region, 39.7100, 66.6600, 'Pastdargom District', 'Пастдаргомский район', 'Pastdarg''om tumani');
+  PERFORM insert_location('DISTRICT', region, 39.6200, 66.9000, 'Samarkand District', 'Самаркандский район', 'Samarqand tumani');
+  PERFORM insert_location('DISTRICT', region, 39.5900, 67.0700, 'Tayloq District', 'Тайлакский район', 'Toyloq tumani');
+  PERFORM insert_location('DISTRICT', region, 39.4000, 67.2500, 'Urgut District', 'Ургутский район', 'Urgut tumani');
+
+  region := region_location('Surkhandarya Region');
+  PERFORM insert_location('DISTRICT', region, 37.4600, 67.1800, 'Angor District', 'Ангорский район', 'Angor tumani');
+  PERFORM insert_location('DISTRICT', region, 37.8300, 67.4800, 'Bandikhon District', 'Бандиханский район', 'Bandixon tumani');
+  PERFORM insert_location('DISTRICT', region, 38.2100, 67.2000, 'Boysun District', 'Байсунский район', 'Boysun tumani');
+  PERFORM insert_location('DISTRICT', region, 38.2700, 67.9000, 'Denau District', 'Денауский район', 'Denov tumani');
+  PERFORM insert_location('DISTRICT', region, 37.5100, 67.4100, 'Jarkurgan District', 'Джаркурганский район', 'Jarqo''rg''on tumani');
+  PERFORM insert_location('DISTRICT', region, 37.6200, 67.2100, 'Kiziriq District', 'Кизирикский район', 'Qiziriq tumani');
+  PERFORM insert_location('DISTRICT', region, 37.8300, 67.6100, 'Kumkurgan District', 'Кумкурганский район', 'Qumqo''rg''on tumani');
+  PERFORM insert_location('DISTRICT', region, 37.4200, 66.9200, 'Muzrabot District', 'Музрабадский район', 'Muzrabot tumani');
+  PERFORM insert_location('DISTRICT', region, 38.2600, 67.6500, 'Oltinsoy District', 'Алтынсайский район', 'Oltinsoy tumani');
+  PERFORM insert_location('DISTRICT', region, 38.4100, 67.9600, 'Sariosiyo District', 'Сариасийский район', 'Sariosiyo tumani');
+  PERFORM insert_location('DISTRICT', region, 37.6700, 67.0000, 'Sherobod District', 'Шерабадский район', 'Sherobod tumani');
+  PERFORM insert_location('DISTRICT', region, 38.0000, 67.7900, 'Shurchi District', 'Шурчинский район', 'Sho''rchi tumani');
+  PERFORM insert_location('DISTRICT', region, 37.3200, 67.2600, 'Termez District', 'Термезский район', 'Termiz tumani');
+  PERFORM insert_location('DISTRICT', region, 38.3700, 68.0000, 'Uzun District', 'Узунский район', 'Uzun tumani');
+
+  region := region_location('Syrdarya Region');
+  PERFORM insert_location('DISTRICT', region, 40.6000, 68.2000, 'Akaltyn District', 'Акалтынский район', 'Oqoltin tumani');
+  PERFORM insert_location('DISTRICT', region, 40.3700, 68.9500, 'Bayaut District', 'Баяутский район', 'Boyovut tumani');
+  PERFORM insert_location('DISTRICT', region, 40.4600, 68.7600, 'Gulistan District', 'Гулистанский район', 'Guliston tumani');
+  PERFORM insert_location('DISTRICT', region, 40.2300, 68.8400, 'Khavast District', 'Хавастский район', 'Xovos tumani');
+  PERFORM insert_location('DISTRICT', region, 40.5500, 68.6000, 'Mirzaabad District', 'Мирзаабадский район', 'Mirzaobod tumani');
+  PERFORM insert_location('DISTRICT', region, 40.4700, 68.4000, 'Sardoba District', 'Сардобинский район', 'Sardoba tumani');
+  PERFORM insert_location('DISTRICT', region, 40.6600, 68.7700, 'Sayhunabad District', 'Сайхунабадский район', 'Sayxunobod tumani');
+  PERFORM insert_location('DISTRICT', region, 40.8400, 68.6600, 'Syrdarya District', 'Сырдарьинский район', 'Sirdaryo tumani');
+END;
+$$;
+
+DROP FUNCTION region_location;
+
+COMMIT;
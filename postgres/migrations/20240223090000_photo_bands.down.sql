@@ -0,0 +1,6 @@
+begin;
+
+alter table listing_media drop column if exists phash_bands;
+drop function if exists hash_bands;
+
+commit;
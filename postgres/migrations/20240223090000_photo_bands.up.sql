@@ -0,0 +1,22 @@
+BEGIN;
+
+-- hash_bands splits a 64-bit hash into four 16-bit bands tagged with their
+-- position. Hashes a few bits apart share at least one band, so copies of a
+-- photo are looked up by band instead of comparing against every photo.
+CREATE OR REPLACE FUNCTION hash_bands(hash bigint)
+RETURNS int[]
+AS $$
+  SELECT ARRAY[
+    (hash & 65535)::int,
+    (1 << 16) | ((hash >> 16) & 65535)::int,
+    (2 << 16) | ((hash >> 32) & 65535)::int,
+    (3 << 16) | ((hash >> 48) & 65535)::int
+  ];
+$$
+LANGUAGE sql IMMUTABLE STRICT;
+
+ALTER TABLE listing_media ADD COLUMN IF NOT EXISTS phash_bands int[] GENERATED ALWAYS AS (hash_bands(phash)) STORED;
+
+CREATE INDEX idx_listing_media_phash_bands ON listing_media USING GIN (phash_bands);
+
+COMMIT;
@@ -0,0 +1,5 @@
+begin;
+
+drop table if exists favorite_alerts;
+
+commit;
@@ -0,0 +1,15 @@
+BEGIN;
+
+-- favorite_alerts records who was alerted about an event of a favorited
+-- listing, so that redelivered events don't alert anyone twice.
+CREATE TABLE IF NOT EXISTS favorite_alerts (
+  listing_id uuid NOT NULL,
+  event_key text NOT NULL,
+  user_id uuid NOT NULL,
+  created_at timestamp with time zone DEFAULT now() NOT NULL,
+  PRIMARY KEY (listing_id, event_key, user_id),
+  FOREIGN KEY (listing_id) REFERENCES listings (id) ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+COMMIT;
@@ -0,0 +1,331 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type ModerationService struct {
+	pool *pgxpool.Pool
+}
+
+func NewModerationService(pool *pgxpool.Pool) *ModerationService {
+	return &ModerationService{
+		pool: pool,
+	}
+}
+
+func (s *ModerationService) IsModerator(ctx context.Context, userID yeahapi.UserID) (bool, error) {
+	const op yeahapi.Op = "postgres/ModerationService.IsModerator"
+	var ok bool
+	if err := s.pool.QueryRow(ctx, "select exists(select 1 from moderators where user_id = $1)", userID).Scan(&ok); err != nil {
+		return false, yeahapi.E(op, err)
+	}
+	return ok, nil
+}
+
+func (s *ModerationService) Enqueue(ctx context.Context, listingID uuid.UUID, score float64) error {
+	const op yeahapi.Op = "postgres/ModerationService.Enqueue"
+	_, err := s.pool.Exec(ctx,
+		"insert into moderation_queue (listing_id, score) values ($1, $2) on conflict (listing_id) do update set score = excluded.score",
+		listingID, score)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+	return nil
+}
+
+func (s *ModerationService) Queue(ctx context.Context, limit int) ([]yeahapi.ModerationItem, error) {
+	const op yeahapi.Op = "postgres/ModerationService.Queue"
+	items := make([]yeahapi.ModerationItem, 0)
+
+	rows, err := s.pool.Query(ctx,
+		`select listing_id, submitted_at, score, moderator_id, lease_expires_at from moderation_queue
+		order by submitted_at limit $1`, limit)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var item yeahapi.ModerationItem
+		if err := rows.Scan(&item.ListingID, &item.SubmittedAt, &item.Score, &item.ModeratorID, &item.LeaseExpiresAt); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return items, nil
+}
+
+// Claim leases the oldest item nobody is working on to the moderator. Items
+// whose lease has expired are handed out again. Concurrent claims skip rows
+// locked by each other, so two moderators never get the same item.
+func (s *ModerationService) Claim(ctx context.Context, moderatorID yeahapi.UserID, lease time.Duration) (*yeahapi.ModerationItem, error) {
+	const op yeahapi.Op = "postgres/ModerationService.Claim"
+	var item yeahapi.ModerationItem
+
+	err := s.pool.QueryRow(ctx,
+		`update moderation_queue set moderator_id = $1, lease_expires_at = now() + make_interval(secs => $2)
+		where listing_id = (
+			select listing_id from moderation_queue
+			where moderator_id is null or lease_expires_at < now()
+			order by submitted_at
+			limit 1
+			for update skip locked
+		)
+		returning listing_id, submitted_at, score, moderator_id, lease_expires_at`,
+		moderatorID, lease.Seconds(),
+	).Scan(&item.ListingID, &item.SubmittedAt, &item.Score, &item.ModeratorID, &item.LeaseExpiresAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound)
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	return &item, nil
+}
+
+func (s *ModerationService) Release(ctx context.Context, listingID uuid.UUID, moderatorID yeahapi.UserID) error {
+	const op yeahapi.Op = "postgres/ModerationService.Release"
+	tag, err := s.pool.Exec(ctx,
+		"update moderation_queue set moderator_id = null, lease_expires_at = null where listing_id = $1 and moderator_id = $2",
+		listingID, moderatorID)
+
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return yeahapi.E(op, yeahapi.ENotFound)
+	}
+
+	return nil
+}
+
+// Decide records the decision and moves the listing out of moderation, along
+// with the events announcing it. The moderator must hold an active lease on
+// the listing, automated decisions take the listing out of the queue
+// regardless of leases.
+func (s *ModerationService) Decide(ctx context.Context, decision *yeahapi.ModerationDecision) (*yeahapi.ModerationDecision, error) {
+	const op yeahapi.Op = "postgres/ModerationService.Decide"
+
+	if err := decision.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	decision.ID = id
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	var moderatorID *yeahapi.UserID
+	if decision.Automated {
+		if _, err := tx.Exec(ctx, "delete from moderation_queue where listing_id = $1", decision.ListingID); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+	} else {
+		tag, err := tx.Exec(ctx,
+			"delete from moderation_queue where listing_id = $1 and moderator_id = $2 and lease_expires_at > now()",
+			decision.ListingID, decision.ModeratorID)
+
+		if err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+
+		if tag.RowsAffected() == 0 {
+			return nil, yeahapi.E(op, yeahapi.EConflict)
+		}
+
+		moderatorID = &decision.ModeratorID
+	}
+
+	listing := yeahapi.Listing{ID: decision.ListingID, Status: decision.Kind.ListingStatus()}
+	err = tx.QueryRow(ctx, "update listings set status = $3 where id = $1 and status = $2 returning owner_id",
+		decision.ListingID, yeahapi.ListingStatusModeration, listing.Status).Scan(&listing.OwnerID)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.EConflict)
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	var reasonCode *string
+	if decision.ReasonCode != "" {
+		reasonCode = &decision.ReasonCode
+	}
+
+	err = tx.QueryRow(ctx,
+		`insert into moderation_decisions (id, listing_id, moderator_id, automated, kind, reason_code, comment)
+		values ($1, $2, $3, $4, $5, $6, $7) returning created_at`,
+		decision.ID, decision.ListingID, moderatorID, decision.Automated, decision.Kind, reasonCode, decision.Comment,
+	).Scan(&decision.CreatedAt)
+
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) && pgerr.Code == pgerrcode.ForeignKeyViolation {
+			return nil, yeahapi.E(op, yeahapi.EInvalid, "Unknown moderation reason")
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	messages := []yeahapi.CQRSMessage{yeahapi.NewListingStatusChangedEvent(&listing, yeahapi.ListingStatusModeration)}
+	if decision.Kind == yeahapi.ModerationRejected {
+		messages = append(messages, yeahapi.NewListingRejectedEvent(&listing, decision))
+	}
+
+	if err := enqueue(ctx, tx, messages...); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return decision, nil
+}
+
+func (s *ModerationService) Decisions(ctx context.Context, listingID uuid.UUID) ([]yeahapi.ModerationDecision, error) {
+	const op yeahapi.Op = "postgres/ModerationService.Decisions"
+	decisions := make([]yeahapi.ModerationDecision, 0)
+
+	rows, err := s.pool.Query(ctx,
+		`select id, listing_id, moderator_id, automated, kind, coalesce(reason_code, ''), comment, created_at
+		from moderation_decisions where listing_id = $1 order by created_at`, listingID)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var d yeahapi.ModerationDecision
+		var moderatorID *yeahapi.UserID
+		if err := rows.Scan(&d.ID, &d.ListingID, &moderatorID, &d.Automated, &d.Kind, &d.ReasonCode, &d.Comment, &d.CreatedAt); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		if moderatorID != nil {
+			d.ModeratorID = *moderatorID
+		}
+		decisions = append(decisions, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return decisions, nil
+}
+
+func (s *ModerationService) Reasons(ctx context.Context, lang string) ([]yeahapi.ModerationReason, error) {
+	const op yeahapi.Op = "postgres/ModerationService.Reasons"
+	reasons := make([]yeahapi.ModerationReason, 0)
+
+	rows, err := s.pool.Query(ctx,
+		`select r.code, coalesce(rt.name, r.code) from moderation_reasons r
+		left join moderation_reasons_tr rt on rt.reason_code = r.code and rt.lang_code = $1
+		where r.active order by r.code`, lang)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var r yeahapi.ModerationReason
+		if err := rows.Scan(&r.Code, &r.Name); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		reasons = append(reasons, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return reasons, nil
+}
+
+func (s *ModerationService) SaveCheckResults(ctx context.Context, listingID uuid.UUID, results []yeahapi.CheckResult) error {
+	const op yeahapi.Op = "postgres/ModerationService.SaveCheckResults"
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "delete from listing_check_results where listing_id = $1", listingID); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	for _, r := range results {
+		_, err := tx.Exec(ctx,
+			"insert into listing_check_results (listing_id, check_name, score, reason_code, detail) values ($1, $2, $3, $4, $5)",
+			listingID, r.Check, r.Score, r.ReasonCode, r.Detail)
+
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *ModerationService) CheckResults(ctx context.Context, listingID uuid.UUID) ([]yeahapi.CheckResult, error) {
+	const op yeahapi.Op = "postgres/ModerationService.CheckResults"
+	results := make([]yeahapi.CheckResult, 0)
+
+	rows, err := s.pool.Query(ctx,
+		`select check_name, score, reason_code, detail from listing_check_results
+		where listing_id = $1 order by score desc`, listingID)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var r yeahapi.CheckResult
+		if err := rows.Scan(&r.Check, &r.Score, &r.ReasonCode, &r.Detail); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		results = append(results, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,187 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestModerationService_Claim(t *testing.T) {
+	s := postgres.NewModerationService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		MustSubmitListing(t, ctx, pool)
+		moderator := MustCreateModerator(t, ctx, pool)
+
+		item, err := s.Claim(ctx, moderator.ID, yeahapi.ModerationLease)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if *item.ModeratorID != moderator.ID {
+			t.Fatalf("mismatch: %v != %v", item.ModeratorID, moderator.ID)
+		}
+
+		if err := s.Release(ctx, item.ListingID, moderator.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("NoDoubleClaim", func(t *testing.T) {
+		ctx := context.Background()
+		MustSubmitListing(t, ctx, pool)
+		first, second := MustCreateModerator(t, ctx, pool), MustCreateModerator(t, ctx, pool)
+
+		claimed := make(map[string]bool)
+		for _, m := range []*yeahapi.User{first, second} {
+			item, err := s.Claim(ctx, m.ID, yeahapi.ModerationLease)
+			if yeahapi.EIs(yeahapi.ENotFound, err) {
+				continue
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if claimed[item.ListingID.String()] {
+				t.Fatalf("listing %s claimed twice", item.ListingID)
+			}
+			claimed[item.ListingID.String()] = true
+		}
+	})
+}
+
+func TestModerationService_Decide(t *testing.T) {
+	s := postgres.NewModerationService(pool)
+	ls := postgres.NewListingService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustSubmitListing(t, ctx, pool)
+		moderator := MustCreateModerator(t, ctx, pool)
+		MustClaim(t, ctx, s, listing, moderator)
+
+		if _, err := s.Decide(ctx, &yeahapi.ModerationDecision{
+			ListingID:   listing.ID,
+			ModeratorID: moderator.ID,
+			Kind:        yeahapi.ModerationRejected,
+			ReasonCode:  "WRONG_CATEGORY",
+			Comment:     "Should be in accessories",
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if other, err := ls.Listing(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if other.Status != yeahapi.ListingStatusDraft {
+			t.Fatalf("mismatch: %s != %s", other.Status, yeahapi.ListingStatusDraft)
+		}
+
+		decisions, err := s.Decisions(ctx, listing.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(decisions) != 1 || decisions[0].ReasonCode != "WRONG_CATEGORY" {
+			t.Fatalf("unexpected decisions: %#v", decisions)
+		}
+	})
+
+	t.Run("Automated", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustSubmitListing(t, ctx, pool)
+
+		if _, err := s.Decide(ctx, &yeahapi.ModerationDecision{
+			ListingID: listing.ID,
+			Automated: true,
+			Kind:      yeahapi.ModerationApproved,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if other, err := ls.Listing(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if other.Status != yeahapi.ListingStatusIndexing {
+			t.Fatalf("mismatch: %s != %s", other.Status, yeahapi.ListingStatusIndexing)
+		}
+	})
+
+	t.Run("ErrNotClaimed", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustSubmitListing(t, ctx, pool)
+		moderator := MustCreateModerator(t, ctx, pool)
+
+		_, err := s.Decide(ctx, &yeahapi.ModerationDecision{
+			ListingID:   listing.ID,
+			ModeratorID: moderator.ID,
+			Kind:        yeahapi.ModerationApproved,
+		})
+
+		if !yeahapi.EIs(yeahapi.EConflict, err) {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestModerationService_Reasons(t *testing.T) {
+	s := postgres.NewModerationService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		reasons, err := s.Reasons(context.Background(), "ru")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(reasons) == 0 {
+			t.Fatal("expected moderation reasons")
+		}
+	})
+}
+
+func MustSubmitListing(tb testing.TB, ctx context.Context, pool *pgxpool.Pool) *yeahapi.Listing {
+	tb.Helper()
+	listing := MustCreateListing(tb, ctx, pool)
+	listing, err := postgres.NewListingService(pool).UpdateStatus(ctx, listing.ID, listing.Status, yeahapi.ListingStatusModeration)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	if err := postgres.NewModerationService(pool).Enqueue(ctx, listing.ID, 0); err != nil {
+		tb.Fatal(err)
+	}
+
+	return listing
+}
+
+func MustCreateModerator(tb testing.TB, ctx context.Context, pool *pgxpool.Pool) *yeahapi.User {
+	tb.Helper()
+	user := MustCreateUser(tb, ctx, pool, &yeahapi.User{
+		Email:     randEmail(),
+		FirstName: "Jane",
+		LastName:  "Doe",
+	})
+
+	if _, err := pool.Exec(ctx, "insert into moderators (user_id) values ($1)", user.ID); err != nil {
+		tb.Fatal(err)
+	}
+
+	return user
+}
+
+// MustClaim claims queue items until the given listing is leased to the
+// moderator. Other items claimed along the way are released.
+func MustClaim(tb testing.TB, ctx context.Context, s *postgres.ModerationService, listing *yeahapi.Listing, moderator *yeahapi.User) {
+	tb.Helper()
+	for {
+		item, err := s.Claim(ctx, moderator.ID, yeahapi.ModerationLease)
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		if item.ListingID == listing.ID {
+			return
+		}
+
+		defer s.Release(ctx, item.ListingID, moderator.ID)
+	}
+}
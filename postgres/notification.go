@@ -0,0 +1,226 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go/jetstream"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type NotificationService struct {
+	pool *pgxpool.Pool
+}
+
+func NewNotificationService(pool *pgxpool.Pool) *NotificationService {
+	return &NotificationService{
+		pool: pool,
+	}
+}
+
+func (s *NotificationService) CreateNotification(ctx context.Context, notification *yeahapi.Notification) (*yeahapi.Notification, error) {
+	const op yeahapi.Op = "postgres/NotificationService.CreateNotification"
+
+	if err := notification.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	notification.ID = id
+	err = s.pool.QueryRow(ctx,
+		"insert into notifications (id, user_id, kind, payload) values ($1, $2, $3, $4) returning created_at",
+		notification.ID, notification.UserID, notification.Kind, notification.Payload,
+	).Scan(&notification.CreatedAt)
+
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return notification, nil
+}
+
+func (s *NotificationService) Notifications(ctx context.Context, userID yeahapi.UserID, limit int) ([]yeahapi.Notification, error) {
+	const op yeahapi.Op = "postgres/NotificationService.Notifications"
+	notifications := make([]yeahapi.Notification, 0)
+
+	rows, err := s.pool.Query(ctx,
+		`select id, user_id, kind, payload, read, created_at from notifications
+		where user_id = $1 order by id desc limit $2`, userID, limit)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var n yeahapi.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Payload, &n.Read, &n.CreatedAt); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return notifications, nil
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID yeahapi.UserID, ids []uuid.UUID) error {
+	const op yeahapi.Op = "postgres/NotificationService.MarkRead"
+	_, err := s.pool.Exec(ctx, "update notifications set read = true where user_id = $1 and id = any($2)", userID, ids)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+	return nil
+}
+
+// ListingRejected lets the owner know why their listing didn't pass
+// moderation.
+func (s *NotificationService) ListingRejected(m jetstream.Msg) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var event yeahapi.ListingRejectedEvent
+	if err := json.Unmarshal(m.Data(), &event); err != nil {
+		return err
+	}
+
+	_, err := s.CreateNotification(ctx, &yeahapi.Notification{
+		UserID: event.OwnerID,
+		Kind:   yeahapi.NotificationListingRejected,
+		Payload: yeahapi.NotificationPayload{
+			"listing_id":  event.ListingID,
+			"reason_code": event.ReasonCode,
+			"comment":     event.Comment,
+		},
+	})
+
+	return err
+}
+
+// FavoriteAlert lets a user know that a listing they favorited changed.
+func (s *NotificationService) FavoriteAlert(m jetstream.Msg) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var event yeahapi.FavoriteAlertEvent
+	if err := json.Unmarshal(m.Data(), &event); err != nil {
+		return err
+	}
+
+	payload := yeahapi.NotificationPayload{"listing_id": event.ListingID}
+	if event.Kind == yeahapi.NotificationFavoritePriceDropped {
+		payload["amount"] = event.Amount
+		payload["previous_amount"] = event.PreviousAmount
+		payload["currency"] = event.Currency
+	}
+
+	_, err := s.CreateNotification(ctx, &yeahapi.Notification{
+		UserID:  event.UserID,
+		Kind:    event.Kind,
+		Payload: payload,
+	})
+
+	return err
+}
+
+// savedSearchListings is how many listings a saved search notification
+// mentions per search. The rest are only counted.
+const savedSearchListings = 10
+
+// SavedSearchMatched tells a user about a batch of new listings matching their
+// saved searches, in a single notification.
+func (s *NotificationService) SavedSearchMatched(m jetstream.Msg) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var event yeahapi.SavedSearchMatchedEvent
+	if err := json.Unmarshal(m.Data(), &event); err != nil {
+		return err
+	}
+
+	type search struct {
+		ID         uuid.UUID   `json:"saved_search_id"`
+		Name       string      `json:"name"`
+		ListingIDs []uuid.UUID `json:"listing_ids"`
+		Count      int         `json:"count"`
+	}
+
+	searches := make([]*search, 0)
+	byID := make(map[uuid.UUID]*search)
+	for _, match := range event.Matches {
+		found, ok := byID[match.SavedSearchID]
+		if !ok {
+			found = &search{ID: match.SavedSearchID, Name: match.Name, ListingIDs: make([]uuid.UUID, 0)}
+			byID[match.SavedSearchID] = found
+			searches = append(searches, found)
+		}
+		if len(found.ListingIDs) < savedSearchListings {
+			found.ListingIDs = append(found.ListingIDs, match.ListingID)
+		}
+		found.Count++
+	}
+
+	_, err := s.CreateNotification(ctx, &yeahapi.Notification{
+		UserID:  event.UserID,
+		Kind:    yeahapi.NotificationSavedSearchMatches,
+		Payload: yeahapi.NotificationPayload{"searches": searches},
+	})
+
+	return err
+}
+
+// ListingExpiring warns the owner that their listing is about to be archived
+// unless they renew it.
+func (s *NotificationService) ListingExpiring(m jetstream.Msg) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var event yeahapi.ListingExpiringEvent
+	if err := json.Unmarshal(m.Data(), &event); err != nil {
+		return err
+	}
+
+	_, err := s.CreateNotification(ctx, &yeahapi.Notification{
+		UserID: event.OwnerID,
+		Kind:   yeahapi.NotificationListingExpiring,
+		Payload: yeahapi.NotificationPayload{
+			"listing_id": event.ListingID,
+			"expires_at": event.ExpiresAt,
+		},
+	})
+
+	return err
+}
+
+func (s *NotificationService) ReportResolved(m jetstream.Msg) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var event yeahapi.ReportResolvedEvent
+	if err := json.Unmarshal(m.Data(), &event); err != nil {
+		return err
+	}
+
+	_, err := s.CreateNotification(ctx, &yeahapi.Notification{
+		UserID: event.ReporterID,
+		Kind:   yeahapi.NotificationReportResolved,
+		Payload: yeahapi.NotificationPayload{
+			"report_id":   event.ReportID,
+			"target_kind": event.TargetKind,
+			"target_id":   event.TargetID,
+			"outcome":     event.Outcome,
+		},
+	})
+
+	return err
+}
@@ -0,0 +1,44 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestNotificationService_CreateNotification(t *testing.T) {
+	s := postgres.NewNotificationService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		user := MustCreateUser(t, ctx, pool, &yeahapi.User{
+			Email:     randEmail(),
+			FirstName: "John",
+			LastName:  "Doe",
+		})
+
+		n, err := s.CreateNotification(ctx, &yeahapi.Notification{
+			UserID:  user.ID,
+			Kind:    yeahapi.NotificationListingRejected,
+			Payload: yeahapi.NotificationPayload{"reason_code": "OTHER"},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.MarkRead(ctx, user.ID, []uuid.UUID{n.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		notifications, err := s.Notifications(ctx, user.ID, 10)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(notifications) != 1 || !notifications[0].Read {
+			t.Fatalf("unexpected notifications: %#v", notifications)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type OutboxService struct {
+	pool *pgxpool.Pool
+}
+
+func NewOutboxService(pool *pgxpool.Pool) *OutboxService {
+	return &OutboxService{
+		pool: pool,
+	}
+}
+
+// enqueue stores messages in the outbox as part of tx, they are published
+// once it commits.
+func enqueue(ctx context.Context, tx pgx.Tx, messages ...yeahapi.CQRSMessage) error {
+	for _, m := range messages {
+		data, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, "insert into outbox (subject, data) values ($1, $2)", m.Subject(), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *OutboxService) PendingMessages(ctx context.Context, limit int) ([]yeahapi.OutboxMessage, error) {
+	const op yeahapi.Op = "postgres/OutboxService.PendingMessages"
+	rows, err := s.pool.Query(ctx, "select id, subject, data from outbox order by id limit $1", limit)
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	messages := make([]yeahapi.OutboxMessage, 0)
+	for rows.Next() {
+		var m yeahapi.OutboxMessage
+		if err := rows.Scan(&m.ID, &m.Topic, &m.Data); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		messages = append(messages, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return messages, nil
+}
+
+func (s *OutboxService) DeleteMessages(ctx context.Context, ids []int64) error {
+	const op yeahapi.Op = "postgres/OutboxService.DeleteMessages"
+	if _, err := s.pool.Exec(ctx, "delete from outbox where id = any($1)", ids); err != nil {
+		return yeahapi.E(op, err)
+	}
+	return nil
+}
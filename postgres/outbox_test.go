@@ -0,0 +1,87 @@
+package postgres_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestOutboxService_PendingMessages(t *testing.T) {
+	s := postgres.NewOutboxService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		if _, err := postgres.NewListingService(pool).UpdateStatus(ctx, listing.ID, listing.Status, yeahapi.ListingStatusModeration); err != nil {
+			t.Fatal(err)
+		}
+
+		messages, err := s.PendingMessages(ctx, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var found *yeahapi.OutboxMessage
+		for i, m := range messages {
+			var event yeahapi.ListingStatusChangedEvent
+			if err := json.Unmarshal(m.Data, &event); err != nil {
+				t.Fatal(err)
+			}
+			if event.ListingID == listing.ID {
+				found = &messages[i]
+				if m.Subject() != yeahapi.ListingModerationSubmitted || event.From != listing.Status || event.To != yeahapi.ListingStatusModeration {
+					t.Fatalf("unexpected event: %s %#v", m.Subject(), event)
+				}
+			}
+		}
+
+		if found == nil {
+			t.Fatal("status change not stored in outbox")
+		}
+
+		if err := s.DeleteMessages(ctx, []int64{found.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		messages, err = s.PendingMessages(ctx, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, m := range messages {
+			if m.ID == found.ID {
+				t.Fatal("deleted message still pending")
+			}
+		}
+	})
+}
+
+// MustFindOutboxMessage returns the pending message with subject about the
+// listing, or about the target for report events.
+func MustFindOutboxMessage(tb testing.TB, ctx context.Context, subject string, listingID uuid.UUID) yeahapi.OutboxMessage {
+	tb.Helper()
+	messages, err := postgres.NewOutboxService(pool).PendingMessages(ctx, 1000)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	for _, m := range messages {
+		var event struct {
+			ListingID uuid.UUID `json:"listing_id"`
+			TargetID  uuid.UUID `json:"target_id"`
+		}
+		if err := json.Unmarshal(m.Data, &event); err != nil {
+			tb.Fatal(err)
+		}
+		if m.Subject() == subject && (event.ListingID == listingID || event.TargetID == listingID) {
+			return m
+		}
+	}
+
+	tb.Fatalf("no %s message about %s in outbox", subject, listingID)
+	return yeahapi.OutboxMessage{}
+}
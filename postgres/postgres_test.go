@@ -18,7 +18,35 @@ var pool *pgxpool.Pool
 func TestMain(m *testing.M) {
 	pgContainer, err := postgres.RunContainer(context.Background(),
 		testcontainers.WithImage("postgres:14-alpine"),
-		postgres.WithInitScripts("migrations/20231122101049_initial.up.sql"),
+		postgres.WithInitScripts(
+			"migrations/20231122101049_initial.up.sql",
+			"migrations/20240108090000_moderation.up.sql",
+			"migrations/20240108090100_notifications.up.sql",
+			"migrations/20240110090000_content_checks.up.sql",
+			"migrations/20240112090000_listing_pagination.up.sql",
+			"migrations/20240115090000_search.up.sql",
+			"migrations/20240116090000_attribute_facets.up.sql",
+			"migrations/20240118090000_scheduled_prices.up.sql",
+			"migrations/20240120090000_exchange_rates.up.sql",
+			"migrations/20240122090000_inventory.up.sql",
+			"migrations/20240124090000_media.up.sql",
+			"migrations/20240126090000_media_variants.up.sql",
+			"migrations/20240128090000_listing_details.up.sql",
+			"migrations/20240130090000_locations.up.sql",
+			"migrations/20240201090000_favorites.up.sql",
+			"migrations/20240203090000_saved_searches.up.sql",
+			"migrations/20240205090000_listing_stats.up.sql",
+			"migrations/20240207090000_listing_expiry.up.sql",
+			"migrations/20240209090000_promotions.up.sql",
+			"migrations/20240211090000_reports.up.sql",
+			"migrations/20240213090000_duplicates.up.sql",
+			"migrations/20240215090000_similar.up.sql",
+			"migrations/20240217090000_outbox.up.sql",
+			"migrations/20240219090000_reservation_expiry.up.sql",
+			"migrations/20240221090000_districts.up.sql",
+			"migrations/20240223090000_photo_bands.up.sql",
+			"migrations/20240225090000_favorite_alerts.up.sql",
+		),
 		postgres.WithDatabase("test-db"),
 		postgres.WithUsername("postgres"),
 		postgres.WithPassword("postgres"),
@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type PriceService struct {
+	pool *pgxpool.Pool
+}
+
+func NewPriceService(pool *pgxpool.Pool) *PriceService {
+	return &PriceService{
+		pool: pool,
+	}
+}
+
+func (s *PriceService) SchedulePrice(ctx context.Context, price *yeahapi.ListingSkuPrice, until *time.Time) error {
+	const op yeahapi.Op = "postgres/PriceService.SchedulePrice"
+
+	if err := price.Ok(); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	if until != nil && !until.After(price.StartDate) {
+		return yeahapi.E(op, yeahapi.EInvalid, "Sale must end after it starts")
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	if until != nil {
+		var amount int
+		var currency yeahapi.Currency
+		err := tx.QueryRow(ctx,
+			`select coalesce(p.amount, s.price), coalesce(p.currency, s.price_currency)
+			from listing_skus s
+			left join lateral (
+				select amount, currency from listing_sku_prices where sku_id = s.id and start_date <= $2 order by start_date desc limit 1
+			) p on true
+			where s.id = $1`,
+			price.SkuID, until).Scan(&amount, &currency)
+
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return yeahapi.E(op, yeahapi.ENotFound)
+			}
+			return yeahapi.E(op, err)
+		}
+
+		// A price already scheduled for the end of the sale wins.
+		if _, err := tx.Exec(ctx,
+			`insert into listing_sku_prices (sku_id, amount, currency, start_date) values ($1, $2, $3, $4)
+			on conflict (sku_id, start_date) do nothing`,
+			price.SkuID, amount, currency, until); err != nil {
+			return yeahapi.E(op, err)
+		}
+	}
+
+	_, err = tx.Exec(ctx,
+		`insert into listing_sku_prices (sku_id, amount, currency, start_date) values ($1, $2, $3, $4)
+		on conflict (sku_id, start_date) do update set amount = excluded.amount, currency = excluded.currency, announced_at = null`,
+		price.SkuID, price.Amount, price.Currency, price.StartDate)
+
+	if err != nil {
+		if unknownCurrency(err) {
+			return yeahapi.E(op, yeahapi.EInvalid, "Unknown currency")
+		}
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) && pgerr.Code == pgerrcode.ForeignKeyViolation {
+			return yeahapi.E(op, yeahapi.ENotFound)
+		}
+		return yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *PriceService) PriceHistory(ctx context.Context, skuID uuid.UUID) ([]yeahapi.ListingSkuPrice, error) {
+	const op yeahapi.Op = "postgres/PriceService.PriceHistory"
+	rows, err := s.pool.Query(ctx,
+		"select sku_id, amount, currency, start_date from listing_sku_prices where sku_id = $1 order by start_date",
+		skuID)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	prices := make([]yeahapi.ListingSkuPrice, 0)
+	for rows.Next() {
+		var p yeahapi.ListingSkuPrice
+		if err := rows.Scan(&p.SkuID, &p.Amount, &p.Currency, &p.StartDate); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		prices = append(prices, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return prices, nil
+}
+
+func (s *PriceService) AnnouncePrices(ctx context.Context) ([]yeahapi.PriceDrop, error) {
+	const op yeahapi.Op = "postgres/PriceService.AnnouncePrices"
+	rows, err := s.pool.Query(ctx,
+		`with due as (
+			select s.listing_id, p.sku_id, p.amount, prev.amount as previous_amount, p.currency, p.start_date,
+			coalesce(prev.currency = p.currency and p.amount < prev.amount, false) as dropped
+			from listing_sku_prices p
+			join listing_skus s on s.id = p.sku_id
+			left join lateral (
+				select amount, currency from listing_sku_prices
+				where sku_id = p.sku_id and start_date < p.start_date
+				order by start_date desc limit 1
+			) prev on true
+			where p.announced_at is null and p.start_date <= now()
+		), announced as (
+			update listing_sku_prices p set announced_at = now() from due d
+			where p.sku_id = d.sku_id and p.start_date = d.start_date and not d.dropped
+		)
+		select listing_id, sku_id, amount, previous_amount, currency, start_date from due where dropped`)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	drops := make([]yeahapi.PriceDrop, 0)
+	for rows.Next() {
+		var d yeahapi.PriceDrop
+		if err := rows.Scan(&d.ListingID, &d.SkuID, &d.Amount, &d.PreviousAmount, &d.Currency, &d.StartDate); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		drops = append(drops, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return drops, nil
+}
+
+func (s *PriceService) MarkAnnounced(ctx context.Context, drop yeahapi.PriceDrop) error {
+	const op yeahapi.Op = "postgres/PriceService.MarkAnnounced"
+	_, err := s.pool.Exec(ctx,
+		"update listing_sku_prices set announced_at = now() where sku_id = $1 and start_date = $2 and announced_at is null",
+		drop.SkuID, drop.StartDate)
+
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestPriceService_SchedulePrice(t *testing.T) {
+	s := postgres.NewPriceService(pool)
+	listings := postgres.NewListingService(pool)
+
+	t.Run("Sale", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+
+		until := time.Now().Add(time.Hour)
+		if err := s.SchedulePrice(ctx, &yeahapi.ListingSkuPrice{
+			SkuID:     sku.ID,
+			Amount:    199,
+			Currency:  yeahapi.CurrencyUSD,
+			StartDate: time.Now(),
+		}, &until); err != nil {
+			t.Fatal(err)
+		}
+
+		if other, err := listings.Sku(ctx, sku.ID); err != nil {
+			t.Fatal(err)
+		} else if other.Price != 199 {
+			t.Fatalf("mismatch: %d != %d", other.Price, 199)
+		}
+
+		prices, err := s.PriceHistory(ctx, sku.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(prices) != 3 {
+			t.Fatalf("unexpected history: %#v", prices)
+		} else if last := prices[2]; last.Amount != sku.Price || !last.StartDate.Equal(until.Truncate(time.Microsecond)) {
+			t.Fatalf("unexpected sale end: %#v", last)
+		}
+	})
+
+	t.Run("Future", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+
+		if err := s.SchedulePrice(ctx, &yeahapi.ListingSkuPrice{
+			SkuID:     sku.ID,
+			Amount:    199,
+			Currency:  yeahapi.CurrencyUSD,
+			StartDate: time.Now().Add(time.Hour),
+		}, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if other, err := listings.Sku(ctx, sku.ID); err != nil {
+			t.Fatal(err)
+		} else if other.Price != sku.Price {
+			t.Fatalf("mismatch: %d != %d", other.Price, sku.Price)
+		}
+	})
+}
+
+func TestPriceService_AnnouncePrices(t *testing.T) {
+	s := postgres.NewPriceService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		sku := MustCreateSku(t, ctx, pool, listing.ID)
+
+		if err := s.SchedulePrice(ctx, &yeahapi.ListingSkuPrice{
+			SkuID:     sku.ID,
+			Amount:    199,
+			Currency:  yeahapi.CurrencyUSD,
+			StartDate: time.Now(),
+		}, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		find := func(drops []yeahapi.PriceDrop) *yeahapi.PriceDrop {
+			for i := range drops {
+				if drops[i].SkuID == sku.ID {
+					return &drops[i]
+				}
+			}
+			return nil
+		}
+
+		drops, err := s.AnnouncePrices(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := yeahapi.PriceDrop{ListingID: listing.ID, SkuID: sku.ID, Amount: 199, PreviousAmount: sku.Price, Currency: yeahapi.CurrencyUSD}
+		drop := find(drops)
+		if drop != nil {
+			want.StartDate = drop.StartDate
+		}
+		if drop == nil || *drop != want {
+			t.Fatalf("mismatch: %#v != %#v", drop, want)
+		}
+
+		// Drops stay unannounced until they are published.
+		if drops, err = s.AnnouncePrices(ctx); err != nil {
+			t.Fatal(err)
+		} else if find(drops) == nil {
+			t.Fatal("drop announced before being marked")
+		}
+
+		if err := s.MarkAnnounced(ctx, *drop); err != nil {
+			t.Fatal(err)
+		}
+
+		if drops, err = s.AnnouncePrices(ctx); err != nil {
+			t.Fatal(err)
+		} else if drop := find(drops); drop != nil {
+			t.Fatalf("announced twice: %#v", drop)
+		}
+	})
+}
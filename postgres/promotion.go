@@ -0,0 +1,342 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type PromotionService struct {
+	pool *pgxpool.Pool
+}
+
+func NewPromotionService(pool *pgxpool.Pool) *PromotionService {
+	return &PromotionService{
+		pool: pool,
+	}
+}
+
+const productColumns = `p.id, p.code, p.kind, p.duration_days, p.active, p.created_at,
+	(select json_agg(json_build_object('amount', amount, 'currency', currency) order by currency) from promotion_product_prices where product_id = p.id)`
+
+func productFields(p *yeahapi.PromotionProduct) []interface{} {
+	return []interface{}{&p.ID, &p.Code, &p.Kind, &p.DurationDays, &p.Active, &p.CreatedAt, &p.Prices}
+}
+
+const purchaseColumns = `id, listing_id, user_id, product_id, kind, duration_days, amount, currency, status,
+	coalesce(provider, ''), coalesce(transaction_id, ''), paid_at, starts_at, ends_at, created_at`
+
+func purchaseFields(p *yeahapi.PromotionPurchase) []interface{} {
+	return []interface{}{&p.ID, &p.ListingID, &p.UserID, &p.ProductID, &p.Kind, &p.DurationDays, &p.Amount, &p.Currency, &p.Status,
+		&p.Provider, &p.TransactionID, &p.PaidAt, &p.StartsAt, &p.EndsAt, &p.CreatedAt}
+}
+
+func (s *PromotionService) CreateProduct(ctx context.Context, product *yeahapi.PromotionProduct) (*yeahapi.PromotionProduct, error) {
+	const op yeahapi.Op = "postgres/PromotionService.CreateProduct"
+	if err := product.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	var id int
+	err = tx.QueryRow(ctx,
+		"insert into promotion_products (code, kind, duration_days, active) values ($1, $2, $3, $4) returning id",
+		product.Code, product.Kind, product.DurationDays, product.Active).Scan(&id)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) && pgerr.Code == pgerrcode.UniqueViolation {
+			return nil, yeahapi.E(op, yeahapi.EConflict, fmt.Sprintf("Product with code %s already exists", product.Code))
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := setProductPrices(ctx, tx, id, product.Prices); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	created, err := productByID(ctx, tx, id)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return created, nil
+}
+
+func (s *PromotionService) UpdateProduct(ctx context.Context, product *yeahapi.PromotionProduct) (*yeahapi.PromotionProduct, error) {
+	const op yeahapi.Op = "postgres/PromotionService.UpdateProduct"
+	if err := product.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// The code and kind identify what was bought and stay as they are.
+	tag, err := tx.Exec(ctx,
+		"update promotion_products set duration_days = $2, active = $3 where id = $1",
+		product.ID, product.DurationDays, product.Active)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return nil, yeahapi.E(op, yeahapi.ENotFound, fmt.Sprintf("Product with id %d not found", product.ID))
+	}
+
+	if _, err := tx.Exec(ctx, "delete from promotion_product_prices where product_id = $1", product.ID); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := setProductPrices(ctx, tx, product.ID, product.Prices); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	updated, err := productByID(ctx, tx, product.ID)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return updated, nil
+}
+
+func setProductPrices(ctx context.Context, tx pgx.Tx, productID int, prices []yeahapi.PromotionPrice) error {
+	const op yeahapi.Op = "postgres/PromotionService.setProductPrices"
+	for _, price := range prices {
+		_, err := tx.Exec(ctx,
+			"insert into promotion_product_prices (product_id, currency, amount) values ($1, $2, $3)",
+			productID, price.Currency, price.Amount)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+	}
+	return nil
+}
+
+func productByID(ctx context.Context, tx pgx.Tx, id int) (*yeahapi.PromotionProduct, error) {
+	const op yeahapi.Op = "postgres/PromotionService.productByID"
+	var product yeahapi.PromotionProduct
+	err := tx.QueryRow(ctx, "select "+productColumns+" from promotion_products p where p.id = $1", id).Scan(productFields(&product)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound, fmt.Sprintf("Product with id %d not found", id))
+		}
+		return nil, yeahapi.E(op, err)
+	}
+	return &product, nil
+}
+
+func (s *PromotionService) Products(ctx context.Context, activeOnly bool) ([]yeahapi.PromotionProduct, error) {
+	const op yeahapi.Op = "postgres/PromotionService.Products"
+	rows, err := s.pool.Query(ctx,
+		"select "+productColumns+" from promotion_products p where p.active or not $1 order by p.kind, p.duration_days, p.id",
+		activeOnly)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	products := make([]yeahapi.PromotionProduct, 0)
+	for rows.Next() {
+		var product yeahapi.PromotionProduct
+		if err := rows.Scan(productFields(&product)...); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return products, nil
+}
+
+func (s *PromotionService) Purchase(ctx context.Context, userID yeahapi.UserID, listingID uuid.UUID, productID int, currency yeahapi.Currency) (*yeahapi.PromotionPurchase, error) {
+	const op yeahapi.Op = "postgres/PromotionService.Purchase"
+
+	var ownerID yeahapi.UserID
+	var status yeahapi.ListingStatus
+	err := s.pool.QueryRow(ctx, "select owner_id, status from listings where id = $1", listingID).Scan(&ownerID, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound, "Listing not found")
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	if ownerID != userID {
+		return nil, yeahapi.E(op, yeahapi.EPermission, "You can only promote your own listings")
+	} else if status != yeahapi.ListingStatusActive {
+		return nil, yeahapi.E(op, yeahapi.EInvalid, "Only active listings can be promoted")
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	var purchase yeahapi.PromotionPurchase
+	err = s.pool.QueryRow(ctx,
+		`insert into promotion_purchases (id, listing_id, user_id, product_id, kind, duration_days, amount, currency)
+		select $1, $2, $3, p.id, p.kind, p.duration_days, pr.amount, pr.currency
+		from promotion_products p join promotion_product_prices pr on pr.product_id = p.id and pr.currency = $5
+		where p.id = $4 and p.active
+		returning `+purchaseColumns, id, listingID, userID, productID, currency).Scan(purchaseFields(&purchase)...)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound, fmt.Sprintf("Product with id %d isn't sold in %s", productID, currency))
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	return &purchase, nil
+}
+
+// ConfirmPayment locks the listing so that concurrent confirmations of the
+// same kind of promotion queue up behind each other instead of overlapping.
+func (s *PromotionService) ConfirmPayment(ctx context.Context, payment yeahapi.PromotionPayment) (*yeahapi.PromotionPurchase, error) {
+	const op yeahapi.Op = "postgres/PromotionService.ConfirmPayment"
+	if err := payment.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	var purchase yeahapi.PromotionPurchase
+	err = tx.QueryRow(ctx, "select "+purchaseColumns+" from promotion_purchases where id = $1 for update", payment.PurchaseID).
+		Scan(purchaseFields(&purchase)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound, fmt.Sprintf("Purchase with id %s not found", payment.PurchaseID))
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	switch purchase.Status {
+	case yeahapi.PromotionPurchasePaid:
+		if purchase.Provider == payment.Provider && purchase.TransactionID == payment.TransactionID {
+			return &purchase, nil
+		}
+		return nil, yeahapi.E(op, yeahapi.EConflict, "Purchase is already paid")
+	case yeahapi.PromotionPurchaseCanceled:
+		return nil, yeahapi.E(op, yeahapi.EConflict, "Purchase is canceled")
+	}
+
+	if purchase.Amount != payment.Amount || purchase.Currency != payment.Currency {
+		return nil, yeahapi.E(op, yeahapi.EInvalid, "Payment doesn't match the price of the purchase")
+	}
+
+	if _, err := tx.Exec(ctx, "select 1 from listings where id = $1 for update", purchase.ListingID); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	err = tx.QueryRow(ctx,
+		`with window_start as (
+			select greatest(now(), coalesce(max(ends_at), now())) as at from promotion_purchases
+			where listing_id = $4 and kind = $5 and status = $6
+		)
+		update promotion_purchases set status = $6, provider = $2, transaction_id = $3, paid_at = now(),
+			starts_at = w.at, ends_at = w.at + make_interval(days => duration_days)
+		from window_start w where id = $1
+		returning `+purchaseColumns,
+		purchase.ID, payment.Provider, payment.TransactionID, purchase.ListingID, purchase.Kind, yeahapi.PromotionPurchasePaid).Scan(purchaseFields(&purchase)...)
+	if err != nil {
+		var pgerr *pgconn.PgError
+		if errors.As(err, &pgerr) && pgerr.Code == pgerrcode.UniqueViolation {
+			return nil, yeahapi.E(op, yeahapi.EConflict, "Transaction already paid for another purchase")
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return &purchase, nil
+}
+
+func (s *PromotionService) Purchases(ctx context.Context, listingID uuid.UUID) ([]yeahapi.PromotionPurchase, error) {
+	const op yeahapi.Op = "postgres/PromotionService.Purchases"
+	rows, err := s.pool.Query(ctx,
+		"select "+purchaseColumns+" from promotion_purchases where listing_id = $1 order by id desc", listingID)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	purchases := make([]yeahapi.PromotionPurchase, 0)
+	for rows.Next() {
+		var purchase yeahapi.PromotionPurchase
+		if err := rows.Scan(purchaseFields(&purchase)...); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		purchases = append(purchases, purchase)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return purchases, nil
+}
+
+func (s *PromotionService) ListingPromotions(ctx context.Context, listingID uuid.UUID) ([]yeahapi.ListingPromotion, error) {
+	const op yeahapi.Op = "postgres/PromotionService.ListingPromotions"
+	rows, err := s.pool.Query(ctx,
+		`select kind, starts_at, ends_at from promotion_purchases
+		where listing_id = $1 and status = $2 and ends_at > now() order by kind, starts_at`,
+		listingID, yeahapi.PromotionPurchasePaid)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	promotions := make([]yeahapi.ListingPromotion, 0)
+	for rows.Next() {
+		var p yeahapi.ListingPromotion
+		if err := rows.Scan(&p.Kind, &p.StartsAt, &p.EndsAt); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		promotions = append(promotions, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return promotions, nil
+}
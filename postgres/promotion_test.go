@@ -0,0 +1,256 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestPromotionService_CreateProduct(t *testing.T) {
+	s := postgres.NewPromotionService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		product := MustCreateProduct(t, ctx, s, yeahapi.PromotionTop, 3)
+
+		products, err := s.Products(ctx, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, p := range products {
+			if p.ID == product.ID {
+				if p.Price(yeahapi.CurrencyUZS) == nil || p.Price(yeahapi.CurrencyUSD) == nil {
+					t.Fatalf("unexpected prices: %#v", p.Prices)
+				}
+				return
+			}
+		}
+		t.Fatalf("product wasn't listed: %#v", products)
+	})
+
+	t.Run("ErrMissingPrice", func(t *testing.T) {
+		ctx := context.Background()
+		_, err := s.CreateProduct(ctx, &yeahapi.PromotionProduct{
+			Code:         randCode(),
+			Kind:         yeahapi.PromotionUrgent,
+			DurationDays: 7,
+			Prices:       []yeahapi.PromotionPrice{{Amount: 1000000, Currency: yeahapi.CurrencyUZS}},
+		})
+
+		if !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+}
+
+func TestPromotionService_UpdateProduct(t *testing.T) {
+	s := postgres.NewPromotionService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		product := MustCreateProduct(t, ctx, s, yeahapi.PromotionHighlight, 7)
+		product.Active = false
+		product.Prices = []yeahapi.PromotionPrice{
+			{Amount: 2000000, Currency: yeahapi.CurrencyUZS},
+			{Amount: 160, Currency: yeahapi.CurrencyUSD},
+		}
+
+		updated, err := s.UpdateProduct(ctx, product)
+		if err != nil {
+			t.Fatal(err)
+		} else if updated.Active || updated.Price(yeahapi.CurrencyUSD).Amount != 160 {
+			t.Fatalf("unexpected product: %#v", updated)
+		}
+
+		// Inactive products can't be bought.
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		if _, err := s.Purchase(ctx, listing.OwnerID, listing.ID, product.ID, yeahapi.CurrencyUZS); !yeahapi.EIs(yeahapi.ENotFound, err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestPromotionService_ConfirmPayment(t *testing.T) {
+	s := postgres.NewPromotionService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		product := MustCreateProduct(t, ctx, s, yeahapi.PromotionTop, 3)
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+
+		first := MustPayPurchase(t, ctx, s, listing, product)
+		if first.StartsAt == nil || first.EndsAt.Sub(*first.StartsAt) != 3*24*time.Hour {
+			t.Fatalf("unexpected window: %v - %v", first.StartsAt, first.EndsAt)
+		}
+
+		// Windows of the same kind follow each other.
+		second := MustPayPurchase(t, ctx, s, listing, product)
+		if !second.StartsAt.Equal(*first.EndsAt) {
+			t.Fatalf("second window starts at %v, expected %v", second.StartsAt, first.EndsAt)
+		}
+
+		promotions, err := s.ListingPromotions(ctx, listing.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(promotions) != 2 {
+			t.Fatalf("unexpected promotions: %#v", promotions)
+		}
+
+		got, err := postgres.NewListingService(pool).Listing(ctx, listing.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(got.Promotions) != 1 || got.Promotions[0].Kind != yeahapi.PromotionTop {
+			t.Fatalf("unexpected listing promotions: %#v", got.Promotions)
+		}
+	})
+
+	t.Run("Idempotent", func(t *testing.T) {
+		ctx := context.Background()
+		product := MustCreateProduct(t, ctx, s, yeahapi.PromotionUrgent, 7)
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		purchase := MustPayPurchase(t, ctx, s, listing, product)
+
+		again, err := s.ConfirmPayment(ctx, yeahapi.PromotionPayment{
+			PurchaseID:    purchase.ID,
+			Provider:      purchase.Provider,
+			TransactionID: purchase.TransactionID,
+			Amount:        purchase.Amount,
+			Currency:      purchase.Currency,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		} else if !again.EndsAt.Equal(*purchase.EndsAt) {
+			t.Fatalf("window moved: %v, expected %v", again.EndsAt, purchase.EndsAt)
+		}
+	})
+
+	t.Run("ErrAmountMismatch", func(t *testing.T) {
+		ctx := context.Background()
+		product := MustCreateProduct(t, ctx, s, yeahapi.PromotionTop, 7)
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		purchase, err := s.Purchase(ctx, listing.OwnerID, listing.ID, product.ID, yeahapi.CurrencyUSD)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = s.ConfirmPayment(ctx, yeahapi.PromotionPayment{
+			PurchaseID:    purchase.ID,
+			Provider:      yeahapi.PaymentProviderClick,
+			TransactionID: uuid.Must(uuid.NewV4()).String(),
+			Amount:        purchase.Amount - 1,
+			Currency:      purchase.Currency,
+		})
+
+		if !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+}
+
+func TestPromotionService_Purchase(t *testing.T) {
+	s := postgres.NewPromotionService(pool)
+
+	t.Run("ErrNotOwner", func(t *testing.T) {
+		ctx := context.Background()
+		product := MustCreateProduct(t, ctx, s, yeahapi.PromotionTop, 3)
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		user := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+
+		if _, err := s.Purchase(ctx, user.ID, listing.ID, product.ID, yeahapi.CurrencyUZS); !yeahapi.EIs(yeahapi.EPermission, err) {
+			t.Fatalf("expected permission error, got %v", err)
+		}
+	})
+
+	t.Run("ErrNotActive", func(t *testing.T) {
+		ctx := context.Background()
+		product := MustCreateProduct(t, ctx, s, yeahapi.PromotionTop, 3)
+		listing := MustCreateListing(t, ctx, pool)
+
+		if _, err := s.Purchase(ctx, listing.OwnerID, listing.ID, product.ID, yeahapi.CurrencyUZS); !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+}
+
+func TestSearchService_Search_Promoted(t *testing.T) {
+	ctx := context.Background()
+	s := postgres.NewPromotionService(pool)
+	product := MustCreateProduct(t, ctx, s, yeahapi.PromotionTop, 7)
+
+	older := MustCreateActiveListing(t, ctx, pool, "Zither Hora")
+	newer := MustCreateActiveListing(t, ctx, pool, "Zither Hora")
+	MustMoveToCategory(t, ctx, pool, older, newer.CategoryID)
+	MustPayPurchase(t, ctx, s, older, product)
+
+	page, err := postgres.NewSearchService(pool).Search(ctx, yeahapi.ListingSearch{Query: "zither", CategoryID: newer.CategoryID, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.Hits) != 2 || page.Hits[0].ID != older.ID {
+		t.Fatalf("promoted listing isn't first: %#v", page.Hits)
+	}
+}
+
+func randCode() string {
+	return "test_" + uuid.Must(uuid.NewV4()).String()[:8]
+}
+
+func MustCreateProduct(tb testing.TB, ctx context.Context, s *postgres.PromotionService, kind yeahapi.PromotionKind, days int) *yeahapi.PromotionProduct {
+	tb.Helper()
+	product, err := s.CreateProduct(ctx, &yeahapi.PromotionProduct{
+		Code:         randCode(),
+		Kind:         kind,
+		DurationDays: days,
+		Active:       true,
+		Prices: []yeahapi.PromotionPrice{
+			{Amount: 1500000, Currency: yeahapi.CurrencyUZS},
+			{Amount: 120, Currency: yeahapi.CurrencyUSD},
+		},
+	})
+
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return product
+}
+
+// MustPayPurchase buys the product for the listing on behalf of its owner and
+// confirms the payment.
+func MustPayPurchase(tb testing.TB, ctx context.Context, s *postgres.PromotionService, listing *yeahapi.Listing, product *yeahapi.PromotionProduct) *yeahapi.PromotionPurchase {
+	tb.Helper()
+	purchase, err := s.Purchase(ctx, listing.OwnerID, listing.ID, product.ID, yeahapi.CurrencyUZS)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	paid, err := s.ConfirmPayment(ctx, yeahapi.PromotionPayment{
+		PurchaseID:    purchase.ID,
+		Provider:      yeahapi.PaymentProviderPayme,
+		TransactionID: uuid.Must(uuid.NewV4()).String(),
+		Amount:        purchase.Amount,
+		Currency:      purchase.Currency,
+	})
+
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return paid
+}
+
+func MustMoveToCategory(tb testing.TB, ctx context.Context, pool *pgxpool.Pool, listing *yeahapi.Listing, categoryID int) {
+	tb.Helper()
+	if _, err := pool.Exec(ctx, "update listings set category_id = $1 where id = $2", categoryID, listing.ID); err != nil {
+		tb.Fatal(err)
+	}
+	listing.CategoryID = categoryID
+}
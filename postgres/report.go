@@ -0,0 +1,282 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type ReportService struct {
+	pool *pgxpool.Pool
+}
+
+func NewReportService(pool *pgxpool.Pool) *ReportService {
+	return &ReportService{
+		pool: pool,
+	}
+}
+
+const reportColumns = `id, reporter_id, target_kind, target_id, reason_code, detail, status, coalesce(outcome, ''),
+	moderator_id, comment, resolved_at, created_at`
+
+func reportFields(r *yeahapi.Report) []interface{} {
+	return []interface{}{&r.ID, &r.ReporterID, &r.TargetKind, &r.TargetID, &r.ReasonCode, &r.Detail, &r.Status, &r.Outcome,
+		&r.ModeratorID, &r.Comment, &r.ResolvedAt, &r.CreatedAt}
+}
+
+func (s *ReportService) Reasons(ctx context.Context, lang string) ([]yeahapi.ReportReason, error) {
+	const op yeahapi.Op = "postgres/ReportService.Reasons"
+	reasons := make([]yeahapi.ReportReason, 0)
+
+	rows, err := s.pool.Query(ctx,
+		`select r.code, coalesce(rt.name, r.code) from report_reasons r
+		left join report_reasons_tr rt on rt.reason_code = r.code and rt.lang_code = $1
+		where r.active order by r.code`, lang)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var r yeahapi.ReportReason
+		if err := rows.Scan(&r.Code, &r.Name); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		reasons = append(reasons, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return reasons, nil
+}
+
+func (s *ReportService) CreateReport(ctx context.Context, report *yeahapi.Report) (*yeahapi.Report, error) {
+	const op yeahapi.Op = "postgres/ReportService.CreateReport"
+	if err := report.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := s.checkTarget(ctx, report); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	var active bool
+	err := s.pool.QueryRow(ctx, "select exists(select 1 from report_reasons where code = $1 and active)", report.ReasonCode).Scan(&active)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	} else if !active {
+		return nil, yeahapi.E(op, yeahapi.EInvalid, "Unknown report reason")
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	var created yeahapi.Report
+	err = tx.QueryRow(ctx,
+		`insert into reports (id, reporter_id, target_kind, target_id, reason_code, detail) values ($1, $2, $3, $4, $5, $6)
+		on conflict (reporter_id, target_kind, target_id) where status = 'OPEN' do nothing
+		returning `+reportColumns,
+		id, report.ReporterID, report.TargetKind, report.TargetID, report.ReasonCode, report.Detail).Scan(reportFields(&created)...)
+
+	if err == nil {
+		if err := enqueue(ctx, tx, yeahapi.NewReportCreatedEvent(&created)); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		return &created, nil
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, yeahapi.E(op, err)
+	}
+
+	// The reporter already has an open report of the target.
+	err = s.pool.QueryRow(ctx,
+		"select "+reportColumns+" from reports where reporter_id = $1 and target_kind = $2 and target_id = $3 and status = $4",
+		report.ReporterID, report.TargetKind, report.TargetID, yeahapi.ReportStatusOpen).Scan(reportFields(&created)...)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return &created, nil
+}
+
+// checkTarget makes sure the target of the report exists and isn't the
+// reporter's own.
+func (s *ReportService) checkTarget(ctx context.Context, report *yeahapi.Report) error {
+	const op yeahapi.Op = "postgres/ReportService.checkTarget"
+	switch report.TargetKind {
+	case yeahapi.ReportTargetListing:
+		var ownerID yeahapi.UserID
+		err := s.pool.QueryRow(ctx, "select owner_id from listings where id = $1 and status <> $2",
+			report.TargetID, yeahapi.ListingStatusDeleted).Scan(&ownerID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return yeahapi.E(op, yeahapi.ENotFound, "Listing not found")
+			}
+			return yeahapi.E(op, err)
+		}
+
+		if ownerID == report.ReporterID {
+			return yeahapi.E(op, yeahapi.EInvalid, "You can't report your own listing")
+		}
+	case yeahapi.ReportTargetUser:
+		if report.TargetID == report.ReporterID.UUID {
+			return yeahapi.E(op, yeahapi.EInvalid, "You can't report yourself")
+		}
+
+		var exists bool
+		if err := s.pool.QueryRow(ctx, "select exists(select 1 from users where id = $1)", report.TargetID).Scan(&exists); err != nil {
+			return yeahapi.E(op, err)
+		} else if !exists {
+			return yeahapi.E(op, yeahapi.ENotFound, "User not found")
+		}
+	case yeahapi.ReportTargetMessage:
+		// There are no messages to check against yet.
+		return yeahapi.E(op, yeahapi.ENotImplemented, "Messages can't be reported yet")
+	}
+	return nil
+}
+
+func (s *ReportService) OpenReports(ctx context.Context, kind yeahapi.ReportTargetKind, targetID uuid.UUID) (int, error) {
+	const op yeahapi.Op = "postgres/ReportService.OpenReports"
+	var count int
+	err := s.pool.QueryRow(ctx,
+		"select count(*) from reports where target_kind = $1 and target_id = $2 and status = $3",
+		kind, targetID, yeahapi.ReportStatusOpen).Scan(&count)
+	if err != nil {
+		return 0, yeahapi.E(op, err)
+	}
+	return count, nil
+}
+
+func (s *ReportService) Queue(ctx context.Context, limit int) ([]yeahapi.ReportedTarget, error) {
+	const op yeahapi.Op = "postgres/ReportService.Queue"
+	targets := make([]yeahapi.ReportedTarget, 0)
+
+	rows, err := s.pool.Query(ctx,
+		`select target_kind, target_id, count(*), array_agg(distinct reason_code), min(created_at) from reports
+		where status = $1 group by target_kind, target_id
+		order by count(*) desc, min(created_at) limit $2`, yeahapi.ReportStatusOpen, limit)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var t yeahapi.ReportedTarget
+		if err := rows.Scan(&t.TargetKind, &t.TargetID, &t.Reports, &t.ReasonCodes, &t.FirstReportedAt); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		targets = append(targets, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return targets, nil
+}
+
+func (s *ReportService) Reports(ctx context.Context, kind yeahapi.ReportTargetKind, targetID uuid.UUID) ([]yeahapi.Report, error) {
+	const op yeahapi.Op = "postgres/ReportService.Reports"
+	rows, err := s.pool.Query(ctx,
+		"select "+reportColumns+" from reports where target_kind = $1 and target_id = $2 order by id desc", kind, targetID)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	reports, err := scanReports(rows)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return reports, nil
+}
+
+func (s *ReportService) Resolve(ctx context.Context, resolution yeahapi.ReportResolution) ([]yeahapi.Report, error) {
+	const op yeahapi.Op = "postgres/ReportService.Resolve"
+	if err := resolution.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx,
+		`update reports set status = $4, outcome = $5, moderator_id = $6, comment = $7, resolved_at = now()
+		where target_kind = $1 and target_id = $2 and status = $3
+		returning `+reportColumns,
+		resolution.TargetKind, resolution.TargetID, yeahapi.ReportStatusOpen, yeahapi.ReportStatusResolved,
+		resolution.Outcome, resolution.ModeratorID, resolution.Comment)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	reports, err := scanReports(rows)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if len(reports) == 0 {
+		return nil, yeahapi.E(op, yeahapi.ENotFound, fmt.Sprintf("No open reports of %s %s", resolution.TargetKind, resolution.TargetID))
+	}
+
+	events := make([]yeahapi.CQRSMessage, len(reports))
+	for i := range reports {
+		events[i] = yeahapi.NewReportResolvedEvent(&reports[i])
+	}
+
+	if err := enqueue(ctx, tx, events...); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return reports, nil
+}
+
+func scanReports(rows pgx.Rows) ([]yeahapi.Report, error) {
+	reports := make([]yeahapi.Report, 0)
+	for rows.Next() {
+		var r yeahapi.Report
+		if err := rows.Scan(reportFields(&r)...); err != nil {
+			return nil, err
+		}
+		reports = append(reports, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
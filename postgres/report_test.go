@@ -0,0 +1,165 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestReportService_CreateReport(t *testing.T) {
+	s := postgres.NewReportService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		reporter := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+
+		report := MustCreateReport(t, ctx, s, reporter.ID, yeahapi.ReportTargetListing, listing.ID)
+		if report.Status != yeahapi.ReportStatusOpen {
+			t.Fatalf("unexpected status: %s", report.Status)
+		}
+		MustFindOutboxMessage(t, ctx, yeahapi.ReportCreated, listing.ID)
+
+		// Reporting again returns the open report.
+		again := MustCreateReport(t, ctx, s, reporter.ID, yeahapi.ReportTargetListing, listing.ID)
+		if again.ID != report.ID {
+			t.Fatalf("report wasn't deduplicated: %s != %s", again.ID, report.ID)
+		}
+
+		if count, err := s.OpenReports(ctx, yeahapi.ReportTargetListing, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if count != 1 {
+			t.Fatalf("unexpected open reports: %d", count)
+		}
+	})
+
+	t.Run("ErrOwnListing", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		_, err := s.CreateReport(ctx, &yeahapi.Report{
+			ReporterID: listing.OwnerID,
+			TargetKind: yeahapi.ReportTargetListing,
+			TargetID:   listing.ID,
+			ReasonCode: "FRAUD",
+		})
+
+		if !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+
+	t.Run("ErrUnknownUser", func(t *testing.T) {
+		ctx := context.Background()
+		reporter := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+		_, err := s.CreateReport(ctx, &yeahapi.Report{
+			ReporterID: reporter.ID,
+			TargetKind: yeahapi.ReportTargetUser,
+			TargetID:   uuid.Must(uuid.NewV7()),
+			ReasonCode: "SPAM",
+		})
+
+		if !yeahapi.EIs(yeahapi.ENotFound, err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("ErrUnknownReason", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		reporter := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+		_, err := s.CreateReport(ctx, &yeahapi.Report{
+			ReporterID: reporter.ID,
+			TargetKind: yeahapi.ReportTargetListing,
+			TargetID:   listing.ID,
+			ReasonCode: "NOPE",
+		})
+
+		if !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+}
+
+func TestReportService_Resolve(t *testing.T) {
+	s := postgres.NewReportService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		target := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+		moderator := MustCreateModerator(t, ctx, pool)
+		for i := 0; i < 2; i++ {
+			reporter := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+			MustCreateReport(t, ctx, s, reporter.ID, yeahapi.ReportTargetUser, target.ID.UUID)
+		}
+
+		queue, err := s.Queue(ctx, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var queued *yeahapi.ReportedTarget
+		for i := range queue {
+			if queue[i].TargetID == target.ID.UUID {
+				queued = &queue[i]
+			}
+		}
+		if queued == nil || queued.Reports != 2 {
+			t.Fatalf("unexpected queue: %#v", queue)
+		}
+
+		resolved, err := s.Resolve(ctx, yeahapi.ReportResolution{
+			TargetKind:  yeahapi.ReportTargetUser,
+			TargetID:    target.ID.UUID,
+			ModeratorID: moderator.ID,
+			Outcome:     yeahapi.ReportDismissed,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		} else if len(resolved) != 2 || resolved[0].Outcome != yeahapi.ReportDismissed {
+			t.Fatalf("unexpected reports: %#v", resolved)
+		}
+		MustFindOutboxMessage(t, ctx, yeahapi.ReportResolved, target.ID.UUID)
+
+		if count, err := s.OpenReports(ctx, yeahapi.ReportTargetUser, target.ID.UUID); err != nil {
+			t.Fatal(err)
+		} else if count != 0 {
+			t.Fatalf("unexpected open reports: %d", count)
+		}
+	})
+
+	t.Run("ErrNoReports", func(t *testing.T) {
+		ctx := context.Background()
+		moderator := MustCreateModerator(t, ctx, pool)
+		_, err := s.Resolve(ctx, yeahapi.ReportResolution{
+			TargetKind:  yeahapi.ReportTargetListing,
+			TargetID:    uuid.Must(uuid.NewV7()),
+			ModeratorID: moderator.ID,
+			Outcome:     yeahapi.ReportActionTaken,
+		})
+
+		if !yeahapi.EIs(yeahapi.ENotFound, err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func MustCreateReport(tb testing.TB, ctx context.Context, s *postgres.ReportService, reporterID yeahapi.UserID, kind yeahapi.ReportTargetKind, targetID uuid.UUID) *yeahapi.Report {
+	tb.Helper()
+	report, err := s.CreateReport(ctx, &yeahapi.Report{
+		ReporterID: reporterID,
+		TargetKind: kind,
+		TargetID:   targetID,
+		ReasonCode: "FRAUD",
+		Detail:     "Asked for a prepayment",
+	})
+
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return report
+}
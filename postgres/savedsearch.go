@@ -0,0 +1,327 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type SavedSearchService struct {
+	pool *pgxpool.Pool
+}
+
+func NewSavedSearchService(pool *pgxpool.Pool) *SavedSearchService {
+	return &SavedSearchService{
+		pool: pool,
+	}
+}
+
+const savedSearchColumns = `id, user_id, name, query, coalesce(category_id, 0), min_price, max_price,
+	coalesce(price_currency, ''), attrs, created_at`
+
+func savedSearchFields(s *yeahapi.SavedSearch) []interface{} {
+	return []interface{}{&s.ID, &s.UserID, &s.Name, &s.Query, &s.CategoryID, &s.MinPrice, &s.MaxPrice,
+		&s.Currency, &s.Attrs, &s.CreatedAt}
+}
+
+func (s *SavedSearchService) CreateSavedSearch(ctx context.Context, search *yeahapi.SavedSearch) (*yeahapi.SavedSearch, error) {
+	const op yeahapi.Op = "postgres/SavedSearchService.CreateSavedSearch"
+
+	if err := search.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	var count int
+	if err := s.pool.QueryRow(ctx, "select count(*) from saved_searches where user_id = $1", search.UserID).Scan(&count); err != nil {
+		return nil, yeahapi.E(op, err)
+	} else if count >= yeahapi.MaxSavedSearches {
+		return nil, yeahapi.E(op, yeahapi.EInvalid, fmt.Sprintf("You can't save more than %d searches", yeahapi.MaxSavedSearches))
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if search.Attrs == nil {
+		search.Attrs = make(map[string][]string)
+	}
+
+	search.ID = id
+	search.Query = strings.TrimSpace(search.Query)
+	err = s.pool.QueryRow(ctx,
+		`insert into saved_searches (id, user_id, name, query, category_id, min_price, max_price, price_currency, attrs)
+		values ($1, $2, $3, $4, nullif($5, 0), $6, $7, nullif($8, ''), $9) returning created_at`,
+		search.ID, search.UserID, search.Name, search.Query, search.CategoryID, search.MinPrice, search.MaxPrice,
+		search.Currency, search.Attrs,
+	).Scan(&search.CreatedAt)
+
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return search, nil
+}
+
+func (s *SavedSearchService) SavedSearches(ctx context.Context, userID yeahapi.UserID) ([]yeahapi.SavedSearch, error) {
+	const op yeahapi.Op = "postgres/SavedSearchService.SavedSearches"
+	searches := make([]yeahapi.SavedSearch, 0)
+
+	rows, err := s.pool.Query(ctx, "select "+savedSearchColumns+" from saved_searches where user_id = $1 order by id desc", userID)
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var search yeahapi.SavedSearch
+		if err := rows.Scan(savedSearchFields(&search)...); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		searches = append(searches, search)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return searches, nil
+}
+
+func (s *SavedSearchService) DeleteSavedSearch(ctx context.Context, userID yeahapi.UserID, id uuid.UUID) error {
+	const op yeahapi.Op = "postgres/SavedSearchService.DeleteSavedSearch"
+	tag, err := s.pool.Exec(ctx, "delete from saved_searches where id = $1 and user_id = $2", id, userID)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return yeahapi.E(op, yeahapi.ENotFound, "Saved search not found")
+	}
+
+	return nil
+}
+
+func (s *SavedSearchService) Frequency(ctx context.Context, userID yeahapi.UserID) (yeahapi.SavedSearchFrequency, error) {
+	const op yeahapi.Op = "postgres/SavedSearchService.Frequency"
+	var frequency yeahapi.SavedSearchFrequency
+	err := s.pool.QueryRow(ctx, "select frequency from saved_search_settings where user_id = $1", userID).Scan(&frequency)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return yeahapi.SavedSearchInstant, nil
+		}
+		return "", yeahapi.E(op, err)
+	}
+	return frequency, nil
+}
+
+func (s *SavedSearchService) SetFrequency(ctx context.Context, userID yeahapi.UserID, frequency yeahapi.SavedSearchFrequency) error {
+	const op yeahapi.Op = "postgres/SavedSearchService.SetFrequency"
+
+	if err := frequency.Ok(); err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	_, err := s.pool.Exec(ctx,
+		`insert into saved_search_settings (user_id, frequency) values ($1, $2)
+		on conflict (user_id) do update set frequency = excluded.frequency`, userID, frequency)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
+
+// MatchListing narrows saved searches down to the ones without a category or
+// with the category of the listing or one of its ancestors, so that only a
+// few of them are checked against the listing. The checks go out in a single
+// batch.
+func (s *SavedSearchService) MatchListing(ctx context.Context, listingID uuid.UUID) ([]yeahapi.SavedSearchMatch, error) {
+	const op yeahapi.Op = "postgres/SavedSearchService.MatchListing"
+
+	var ownerID yeahapi.UserID
+	var categoryID int
+	var status yeahapi.ListingStatus
+	err := s.pool.QueryRow(ctx, "select owner_id, category_id, status from listings where id = $1", listingID).Scan(&ownerID, &categoryID, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound)
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	matches := make([]yeahapi.SavedSearchMatch, 0)
+	if status != yeahapi.ListingStatusActive {
+		return matches, nil
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`select `+savedSearchColumns+` from saved_searches ss
+		where ss.user_id <> $1 and (ss.category_id is null or ss.category_id in (
+			with recursive c as (
+				select id, parent_id from categories where id = $2
+				union all
+				select p.id, p.parent_id from categories p join c on p.id = c.parent_id
+			) select id from c
+		)) and not exists (select 1 from saved_search_matches where saved_search_id = ss.id and listing_id = $3)`,
+		ownerID, categoryID, listingID)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	candidates := make([]yeahapi.SavedSearch, 0)
+	for rows.Next() {
+		var search yeahapi.SavedSearch
+		if err := rows.Scan(savedSearchFields(&search)...); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		candidates = append(candidates, search)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if len(candidates) == 0 {
+		return matches, nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, search := range candidates {
+		f, err := newSearchFilter(search.Search(1))
+		if err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		args := append(f.args, listingID)
+		batch.Queue(fmt.Sprintf("select exists (select 1 %s where %s and l.id = $%d)",
+			searchFrom, strings.Join(f.where, " and "), len(args)), args...)
+	}
+
+	results := s.pool.SendBatch(ctx, batch)
+	ids := make([]uuid.UUID, 0)
+	for _, search := range candidates {
+		var matched bool
+		if err := results.QueryRow().Scan(&matched); err != nil {
+			results.Close()
+			return nil, yeahapi.E(op, err)
+		}
+		if matched {
+			ids = append(ids, search.ID)
+			matches = append(matches, yeahapi.SavedSearchMatch{SavedSearchID: search.ID, Name: search.Name, ListingID: listingID})
+		}
+	}
+
+	if err := results.Close(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if len(ids) == 0 {
+		return matches, nil
+	}
+
+	_, err = s.pool.Exec(ctx,
+		`insert into saved_search_matches (saved_search_id, listing_id)
+		select unnest($1::uuid[]), $2 on conflict do nothing`, ids, listingID)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return matches, nil
+}
+
+// Digests reads the pending matches of users whose frequency allows it. A
+// daily or weekly batch goes out a day or a week after the previous one, or
+// after the oldest match when there was none. Matches of listings that aren't
+// active anymore are left out. Nothing is marked until MarkNotified.
+func (s *SavedSearchService) Digests(ctx context.Context, now time.Time) ([]yeahapi.SavedSearchDigest, error) {
+	const op yeahapi.Op = "postgres/SavedSearchService.Digests"
+	rows, err := s.pool.Query(ctx,
+		`with pending as (
+			select ss.user_id, min(m.created_at) as since
+			from saved_search_matches m
+			join saved_searches ss on ss.id = m.saved_search_id
+			where m.notified_at is null
+			group by ss.user_id
+		), due as (
+			select p.user_id from pending p
+			left join saved_search_settings st on st.user_id = p.user_id
+			where coalesce(st.frequency, 'INSTANT') = 'INSTANT'
+			or (st.frequency = 'DAILY' and coalesce(st.notified_at, p.since) <= $1::timestamptz - interval '1 day')
+			or (st.frequency = 'WEEKLY' and coalesce(st.notified_at, p.since) <= $1::timestamptz - interval '7 days')
+		)
+		select ss.user_id, m.saved_search_id, ss.name, m.listing_id, l.status = $2
+		from saved_search_matches m
+		join saved_searches ss on ss.id = m.saved_search_id
+		join listings l on l.id = m.listing_id
+		where m.notified_at is null and ss.user_id in (select user_id from due)
+		order by ss.user_id, m.saved_search_id, m.listing_id`, now, yeahapi.ListingStatusActive)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	digests := make([]yeahapi.SavedSearchDigest, 0)
+	for rows.Next() {
+		var userID yeahapi.UserID
+		var match yeahapi.SavedSearchMatch
+		var active bool
+		if err := rows.Scan(&userID, &match.SavedSearchID, &match.Name, &match.ListingID, &active); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+
+		// Users with nothing but inactive listings still get an empty
+		// digest so that their matches are marked.
+		if len(digests) == 0 || digests[len(digests)-1].UserID != userID {
+			digests = append(digests, yeahapi.SavedSearchDigest{UserID: userID, Matches: make([]yeahapi.SavedSearchMatch, 0)})
+		}
+
+		if active {
+			digest := &digests[len(digests)-1]
+			digest.Matches = append(digest.Matches, match)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return digests, nil
+}
+
+// MarkNotified marks the matches of the digest, along with pending matches of
+// the user's listings that aren't active anymore, as notified at now. Matches
+// recorded after the digest was read stay pending.
+func (s *SavedSearchService) MarkNotified(ctx context.Context, digest yeahapi.SavedSearchDigest, now time.Time) error {
+	const op yeahapi.Op = "postgres/SavedSearchService.MarkNotified"
+	searchIDs := make([]uuid.UUID, len(digest.Matches))
+	listingIDs := make([]uuid.UUID, len(digest.Matches))
+	for i, match := range digest.Matches {
+		searchIDs[i], listingIDs[i] = match.SavedSearchID, match.ListingID
+	}
+
+	_, err := s.pool.Exec(ctx,
+		`with notified as (
+			update saved_search_matches m set notified_at = $2
+			from saved_searches ss, listings l
+			where ss.id = m.saved_search_id and l.id = m.listing_id and ss.user_id = $1 and m.notified_at is null
+			and ((m.saved_search_id, m.listing_id) in (select unnest($3::uuid[]), unnest($4::uuid[])) or l.status <> $5)
+		)
+		insert into saved_search_settings (user_id, notified_at) values ($1, $2)
+		on conflict (user_id) do update set notified_at = excluded.notified_at`,
+		digest.UserID, now, searchIDs, listingIDs, yeahapi.ListingStatusActive)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
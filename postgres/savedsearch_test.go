@@ -0,0 +1,174 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestSavedSearchService_MatchListing(t *testing.T) {
+	s := postgres.NewSavedSearchService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Samsung Galaxy S23")
+		MustCreateSku(t, ctx, pool, listing.ID)
+		user := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+
+		max, tooCheap := 500, 100
+		matching := MustCreateSavedSearch(t, ctx, s, &yeahapi.SavedSearch{
+			UserID:     user.ID,
+			Name:       "Phones",
+			Query:      "galaxy",
+			CategoryID: listing.CategoryID,
+			MaxPrice:   &max,
+			Currency:   yeahapi.CurrencyUSD,
+			Attrs:      map[string][]string{"ram": {"8 GB", "12 GB"}},
+		})
+		MustCreateSavedSearch(t, ctx, s, &yeahapi.SavedSearch{
+			UserID:     user.ID,
+			Query:      "galaxy",
+			CategoryID: listing.CategoryID,
+			MaxPrice:   &tooCheap,
+			Currency:   yeahapi.CurrencyUSD,
+		})
+		MustCreateSavedSearch(t, ctx, s, &yeahapi.SavedSearch{UserID: user.ID, Query: "iphone"})
+		// Searches of the seller don't match their own listings.
+		MustCreateSavedSearch(t, ctx, s, &yeahapi.SavedSearch{UserID: listing.OwnerID, Query: "galaxy"})
+
+		matches, err := s.MatchListing(ctx, listing.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(matches) != 1 || matches[0].SavedSearchID != matching.ID {
+			t.Fatalf("unexpected matches: %#v", matches)
+		}
+
+		// Listings are matched once.
+		if matches, err := s.MatchListing(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if len(matches) != 0 {
+			t.Fatalf("unexpected matches: %#v", matches)
+		}
+	})
+
+	t.Run("NotActive", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		user := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+		MustCreateSavedSearch(t, ctx, s, &yeahapi.SavedSearch{UserID: user.ID, CategoryID: listing.CategoryID})
+
+		if matches, err := s.MatchListing(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		} else if len(matches) != 0 {
+			t.Fatalf("unexpected matches: %#v", matches)
+		}
+	})
+}
+
+func TestSavedSearchService_Digests(t *testing.T) {
+	s := postgres.NewSavedSearchService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Samsung Galaxy S23")
+		instant := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+		daily := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+		if err := s.SetFrequency(ctx, daily.ID, yeahapi.SavedSearchDaily); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, user := range []*yeahapi.User{instant, daily} {
+			MustCreateSavedSearch(t, ctx, s, &yeahapi.SavedSearch{UserID: user.ID, CategoryID: listing.CategoryID})
+		}
+
+		if _, err := s.MatchListing(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		now := time.Now()
+		if digests := MustDigests(t, ctx, s, now); len(digestMatches(digests, instant.ID)) != 1 || len(digestMatches(digests, daily.ID)) != 0 {
+			t.Fatalf("unexpected digests: %#v", digests)
+		}
+
+		// The daily batch goes out a day after the first match, once.
+		if digests := MustDigests(t, ctx, s, now.Add(25*time.Hour)); len(digestMatches(digests, daily.ID)) != 1 || len(digestMatches(digests, instant.ID)) != 0 {
+			t.Fatalf("unexpected digests: %#v", digests)
+		}
+
+		if digests := MustDigests(t, ctx, s, now.Add(50*time.Hour)); len(digestMatches(digests, daily.ID)) != 0 {
+			t.Fatalf("unexpected digests: %#v", digests)
+		}
+	})
+
+	t.Run("Unmarked", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Samsung Galaxy S24")
+		user := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+		MustCreateSavedSearch(t, ctx, s, &yeahapi.SavedSearch{UserID: user.ID, CategoryID: listing.CategoryID})
+		if _, err := s.MatchListing(ctx, listing.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		// A digest that failed to go out is read again.
+		now := time.Now()
+		for i := 0; i < 2; i++ {
+			digests, err := s.Digests(ctx, now)
+			if err != nil {
+				t.Fatal(err)
+			} else if len(digestMatches(digests, user.ID)) != 1 {
+				t.Fatalf("unexpected digests: %#v", digests)
+			}
+		}
+
+		if digests := MustDigests(t, ctx, s, now); len(digestMatches(digests, user.ID)) != 1 {
+			t.Fatalf("unexpected digests: %#v", digests)
+		} else if digests := MustDigests(t, ctx, s, now); len(digestMatches(digests, user.ID)) != 0 {
+			t.Fatalf("unexpected digests: %#v", digests)
+		}
+	})
+
+	t.Run("ErrUnknownFrequency", func(t *testing.T) {
+		ctx := context.Background()
+		user := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+		if err := s.SetFrequency(ctx, user.ID, "HOURLY"); !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+}
+
+func MustCreateSavedSearch(tb testing.TB, ctx context.Context, s *postgres.SavedSearchService, search *yeahapi.SavedSearch) *yeahapi.SavedSearch {
+	tb.Helper()
+	search, err := s.CreateSavedSearch(ctx, search)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return search
+}
+
+// MustDigests reads the digests due at now and marks them as notified.
+func MustDigests(tb testing.TB, ctx context.Context, s *postgres.SavedSearchService, now time.Time) []yeahapi.SavedSearchDigest {
+	tb.Helper()
+	digests, err := s.Digests(ctx, now)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for _, digest := range digests {
+		if err := s.MarkNotified(ctx, digest, now); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return digests
+}
+
+// digestMatches returns the matches in the digest of a user, if any.
+func digestMatches(digests []yeahapi.SavedSearchDigest, userID yeahapi.UserID) []yeahapi.SavedSearchMatch {
+	for _, digest := range digests {
+		if digest.UserID == userID {
+			return digest.Matches
+		}
+	}
+	return nil
+}
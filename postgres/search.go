@@ -0,0 +1,339 @@
+package postgres
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+// recencyHalfLife is how long it takes a listing to lose half of its rank.
+const recencyHalfLife = 30 * 24 * time.Hour
+
+// topBoost multiplies the rank of listings promoted to the top. Ranks are
+// boosted rather than promoted listings put first so that they still have to
+// match the query well.
+const topBoost = 3.0
+
+type SearchService struct {
+	pool *pgxpool.Pool
+}
+
+func NewSearchService(pool *pgxpool.Pool) *SearchService {
+	return &SearchService{
+		pool: pool,
+	}
+}
+
+// searchCursor pins the time ranks were computed at, so that the recency part
+// of the rank doesn't drift between pages.
+type searchCursor struct {
+	Rank float64   `json:"r"`
+	ID   uuid.UUID `json:"id"`
+	At   time.Time `json:"at"`
+}
+
+func (c searchCursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func parseSearchCursor(s string) (*searchCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, yeahapi.E(yeahapi.EInvalid, "Invalid cursor")
+	}
+
+	var c searchCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, yeahapi.E(yeahapi.EInvalid, "Invalid cursor")
+	}
+
+	return &c, nil
+}
+
+func (s *SearchService) Search(ctx context.Context, search yeahapi.ListingSearch) (*yeahapi.SearchPage, error) {
+	const op yeahapi.Op = "postgres/SearchService.Search"
+
+	if err := search.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	cursor := &searchCursor{At: time.Now().UTC()}
+	if search.Cursor != "" {
+		var err error
+		if cursor, err = parseSearchCursor(search.Cursor); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+	}
+
+	f, err := newSearchFilter(search)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	args, filter := f.args, strings.Join(f.where, " and ")
+	text, headline := "1.0", "t.escaped"
+	if f.query != "" {
+		text = "ts_rank_cd(d.document, q.query) + 0.5 * word_similarity(q.raw, lower(l.title))"
+		headline = "ts_headline('simple', t.escaped, q.query, 'StartSel=<b>, StopSel=</b>, HighlightAll=true')"
+	}
+
+	page := &yeahapi.SearchPage{Hits: make([]yeahapi.SearchHit, 0)}
+	if search.Cursor == "" {
+		facets, err := s.facets(ctx, searchFrom+" where "+filter, append(args, search.Lang))
+		if err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		page.Facets = facets
+	}
+
+	filters := len(args)
+	args = append(args, cursor.At, recencyHalfLife.Seconds(), search.Lang)
+	after := "true"
+	if !cursor.ID.IsNil() {
+		args = append(args, cursor.Rank, cursor.ID)
+		after = fmt.Sprintf("(r.rank, r.id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+
+	// Fetch one extra row to know whether there is a next page.
+	args = append(args, search.Limit+1)
+	// Titles are shown and highlighted in the requested language when the
+	// seller translated them.
+	rows, err := s.pool.Query(ctx, fmt.Sprintf(
+		`select * from (
+			select %s,
+			p.price, p.price_currency, coalesce(st.quantity = 0, false), %s as headline,
+			(%s)::float8 * power(0.5::float8, extract(epoch from ($%d::timestamptz - l.created_at))::float8 / $%d::float8)
+				* case when exists (
+					select 1 from promotion_purchases pp where pp.listing_id = l.id and pp.kind = '%s' and pp.status = '%s'
+					and pp.starts_at <= $%d and pp.ends_at > $%d
+				) then %g else 1 end as rank,
+			%s as distance
+			%s
+			left join lateral (%s) p on true
+			left join listing_stock st on st.listing_id = l.id
+			left join listings_tr tr on tr.listing_id = l.id and tr.lang_code = $%d
+			cross join lateral (
+				select replace(replace(replace(coalesce(tr.title, l.title), '&', '&amp;'), '<', '&lt;'), '>', '&gt;') as escaped
+			) t
+			where %s
+		) r where %s order by r.rank desc, r.id desc limit $%d`,
+		listingColumns, headline, text, filters+1, filters+2, yeahapi.PromotionTop, yeahapi.PromotionPurchasePaid, filters+1, filters+1, topBoost, f.distance, searchFrom, f.minPrice, filters+3, filter, after, len(args)), args...)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var h yeahapi.SearchHit
+		var price *int
+		var currency *yeahapi.Currency
+		if err := rows.Scan(append(listingFields(&h.Listing), &price, &currency, &h.SoldOut, &h.Headline, &h.Rank, &h.Distance)...); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+
+		if price != nil {
+			h.MinPrice = &yeahapi.ListingPrice{Amount: *price, Currency: *currency}
+		}
+
+		page.Hits = append(page.Hits, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	if len(page.Hits) > search.Limit {
+		page.Hits = page.Hits[:search.Limit]
+		last := page.Hits[search.Limit-1]
+		page.NextCursor = searchCursor{Rank: last.Rank, ID: last.ID, At: cursor.At}.String()
+	}
+
+	return page, nil
+}
+
+// searchFrom joins listings l with their search document d and the parsed
+// query q. The query text is $1.
+const searchFrom = `from listings l
+		join listing_search d on d.listing_id = l.id
+		cross join lateral (
+			select websearch_to_tsquery('english', $1) || websearch_to_tsquery('russian', $1) || websearch_to_tsquery('simple', $1) as query,
+			lower($1) as raw
+		) q`
+
+// searchFilter holds the conditions a listing has to meet to match a search,
+// over searchFrom, and their arguments. distance and minPrice are
+// expressions for the distance to the point searched near and a lateral
+// query for the cheapest sku price in the requested currency.
+type searchFilter struct {
+	query    string
+	where    []string
+	args     []interface{}
+	distance string
+	minPrice string
+}
+
+func newSearchFilter(search yeahapi.ListingSearch) (*searchFilter, error) {
+	query := strings.TrimSpace(search.Query)
+	args := []interface{}{query, yeahapi.ListingStatusActive}
+	where := []string{"l.status = $2"}
+
+	// Words are matched with every config the documents are built with. Titles
+	// that don't match as words are still found by trigram similarity, which
+	// covers typos and transliteration differences.
+	if query != "" {
+		where = append(where, "(d.document @@ q.query or q.raw <% lower(l.title))")
+	}
+
+	if search.CategoryID != 0 {
+		args = append(args, search.CategoryID)
+		where = append(where, fmt.Sprintf(`l.category_id in (
+			with recursive c as (
+				select id from categories where id = $%d
+				union all
+				select ch.id from categories ch join c on ch.parent_id = c.id
+			) select id from c
+		)`, len(args)))
+	}
+
+	if search.LocationID != 0 {
+		args = append(args, search.LocationID)
+		where = append(where, fmt.Sprintf(`l.location_id in (
+			with recursive c as (
+				select id from locations where id = $%d
+				union all
+				select ch.id from locations ch join c on ch.parent_id = c.id
+			) select id from c
+		)`, len(args)))
+	}
+
+	// Nearby listings are narrowed down to a box around the point first,
+	// which the index on coordinates can serve, and then by exact distance.
+	distance := "null::float8"
+	if search.Near != nil {
+		min, max := search.Near.Bounds(search.Radius)
+		args = append(args, search.Near.Lat, search.Near.Lng, search.Radius, min.Lat, max.Lat, min.Lng, max.Lng)
+		n := len(args)
+		distance = fmt.Sprintf("haversine_km($%d, $%d, l.lat, l.lng)", n-6, n-5)
+		where = append(where,
+			fmt.Sprintf("l.lat between $%d and $%d and l.lng between $%d and $%d", n-3, n-2, n-1, n),
+			fmt.Sprintf("%s <= $%d", distance, n-4))
+	}
+
+	// Prices are compared and shown in the requested currency. Skus priced in
+	// currencies that can't be converted don't match price filters.
+	var skus []string
+	price := minPrice
+	if search.Currency != "" {
+		args = append(args, search.Currency)
+		converted := fmt.Sprintf("convert_amount(e.price, e.price_currency, $%d)", len(args))
+		price = fmt.Sprintf(`select %s as price, $%d::varchar as price_currency from effective_sku_prices e
+			where e.listing_id = l.id and %s is not null order by 1 limit 1`, converted, len(args), converted)
+		if search.MinPrice != nil {
+			args = append(args, *search.MinPrice)
+			skus = append(skus, fmt.Sprintf("%s >= $%d", converted, len(args)))
+		}
+		if search.MaxPrice != nil {
+			args = append(args, *search.MaxPrice)
+			skus = append(skus, fmt.Sprintf("%s <= $%d", converted, len(args)))
+		}
+	}
+
+	// Attribute filters are containment checks so they can use the gin index
+	// on attrs.
+	for key, values := range search.Attrs {
+		var accepted []string
+		for _, value := range values {
+			b, err := json.Marshal(map[string]string{key: value})
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, string(b))
+			accepted = append(accepted, fmt.Sprintf("s.attrs @> $%d::jsonb", len(args)))
+		}
+		skus = append(skus, "("+strings.Join(accepted, " or ")+")")
+	}
+
+	if len(skus) > 0 {
+		where = append(where, "exists (select 1 from listing_skus s join effective_sku_prices e on e.sku_id = s.id where s.listing_id = l.id and "+strings.Join(skus, " and ")+")")
+	}
+
+	return &searchFilter{
+		query:    query,
+		where:    where,
+		args:     args,
+		distance: distance,
+		minPrice: price,
+	}, nil
+}
+
+// facets counts matching listings per attribute option. The language is the
+// last of args.
+func (s *SearchService) facets(ctx context.Context, from string, args []interface{}) ([]yeahapi.SearchFacet, error) {
+	const op yeahapi.Op = "postgres/SearchService.facets"
+	rows, err := s.pool.Query(ctx, fmt.Sprintf(
+		`with matched as (select l.id, l.category_id %s)
+		select a.key, coalesce(at.name, a.key), ao.id, ao.value, coalesce(aot.name, ao.value), count(distinct m.id)
+		from matched m
+		join attributes a on a.category_id = m.category_id
+		join attribute_options ao on ao.attribute_id = a.id
+		join listing_skus s on s.listing_id = m.id and s.attrs ->> a.key = ao.value
+		left join attributes_tr at on at.attribute_id = a.id and at.lang_code = $%d
+		left join attribute_options_tr aot on aot.attribute_option_id = ao.id and aot.lang_code = $%d
+		group by a.id, a.key, at.name, ao.id, ao.value, aot.name
+		order by a.id, ao.id`, from, len(args), len(args)), args...)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	facets := make([]yeahapi.SearchFacet, 0)
+	for rows.Next() {
+		var key, name string
+		var opt yeahapi.SearchFacetOption
+		if err := rows.Scan(&key, &name, &opt.ID, &opt.Value, &opt.Name, &opt.Count); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+
+		if len(facets) == 0 || facets[len(facets)-1].Key != key {
+			facets = append(facets, yeahapi.SearchFacet{Key: key, Name: name})
+		}
+
+		facet := &facets[len(facets)-1]
+		facet.Options = append(facet.Options, opt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return facets, nil
+}
+
+func (s *SearchService) Index(ctx context.Context, listingID uuid.UUID) error {
+	const op yeahapi.Op = "postgres/SearchService.Index"
+	tag, err := s.pool.Exec(ctx,
+		`insert into listing_search (listing_id, document)
+		select id, listing_search_document(id) from listings where id = $1
+		on conflict (listing_id) do update set document = excluded.document`,
+		listingID)
+
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return yeahapi.E(op, yeahapi.ENotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package postgres_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestSearchService_Search(t *testing.T) {
+	s := postgres.NewSearchService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Смартфоны Samsung Galaxy S23")
+		MustCreateSku(t, ctx, pool, listing.ID)
+
+		page, err := s.Search(ctx, yeahapi.ListingSearch{Query: "смартфон", CategoryID: listing.CategoryID, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(page.Hits) != 1 || page.Hits[0].ID != listing.ID {
+			t.Fatalf("unexpected page: %#v", page)
+		} else if !strings.Contains(page.Hits[0].Headline, "<b>") {
+			t.Fatalf("no highlight in %q", page.Hits[0].Headline)
+		}
+	})
+
+	t.Run("Typo", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Televizor Artel 43 dyuym")
+
+		page, err := s.Search(ctx, yeahapi.ListingSearch{Query: "televizr", CategoryID: listing.CategoryID, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(page.Hits) != 1 || page.Hits[0].ID != listing.ID {
+			t.Fatalf("unexpected page: %#v", page)
+		}
+	})
+
+	t.Run("Translation", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Velosiped Stels")
+		if _, err := postgres.NewListingService(pool).SetTranslation(ctx, &yeahapi.ListingTranslation{
+			ListingID:   listing.ID,
+			Lang:        "ru",
+			Title:       "Велосипед Stels",
+			Description: "<p>Горный, <b>26 дюймов</b></p>",
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		// The translation sent the listing back to moderation, approve it again.
+		if _, err := pool.Exec(ctx, "update listings set status = $1 where id = $2", yeahapi.ListingStatusActive, listing.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		// Translated descriptions are searchable and hits are shown in the
+		// requested language.
+		page, err := s.Search(ctx, yeahapi.ListingSearch{Query: "горный", CategoryID: listing.CategoryID, Lang: "ru", Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(page.Hits) != 1 || page.Hits[0].ID != listing.ID {
+			t.Fatalf("unexpected page: %#v", page)
+		} else if page.Hits[0].Title != "Велосипед Stels" || page.Hits[0].Lang != "ru" {
+			t.Fatalf("unexpected hit: %#v", page.Hits[0])
+		}
+	})
+
+	t.Run("PriceRange", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Laptop Lenovo ThinkPad")
+		MustCreateSku(t, ctx, pool, listing.ID)
+
+		min, max := 300, 500
+		page, err := s.Search(ctx, yeahapi.ListingSearch{
+			CategoryID: listing.CategoryID,
+			MinPrice:   &min,
+			MaxPrice:   &max,
+			Currency:   yeahapi.CurrencyUSD,
+			Limit:      10,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		} else if len(page.Hits) != 0 {
+			t.Fatalf("unexpected page: %#v", page)
+		}
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		ctx := context.Background()
+		first := MustCreateActiveListing(t, ctx, pool, "Velosiped Stels")
+		if _, err := pool.Exec(ctx, "insert into listings (id, title, owner_id, category_id, status) select gen_random_uuid(), title, owner_id, category_id, status from listings where id = $1", first.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		search := yeahapi.ListingSearch{Query: "velosiped", CategoryID: first.CategoryID, Limit: 1}
+		page, err := s.Search(ctx, search)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(page.Hits) != 1 || page.NextCursor == "" {
+			t.Fatalf("unexpected page: %#v", page)
+		}
+
+		seen := page.Hits[0].ID
+		search.Cursor = page.NextCursor
+		page, err = s.Search(ctx, search)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(page.Hits) != 1 || page.NextCursor != "" || page.Hits[0].ID == seen {
+			t.Fatalf("unexpected page: %#v", page)
+		}
+	})
+}
+
+func TestSearchService_Facets(t *testing.T) {
+	s := postgres.NewSearchService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "iPhone 15")
+		MustCreateSku(t, ctx, pool, listing.ID)
+
+		var optionID int
+		if err := pool.QueryRow(ctx,
+			`with a as (insert into attributes (key, category_id) values ('ram', $1) returning id)
+			insert into attribute_options (value, attribute_id) select '8 GB', id from a returning id`,
+			listing.CategoryID).Scan(&optionID); err != nil {
+			t.Fatal(err)
+		}
+
+		page, err := s.Search(ctx, yeahapi.ListingSearch{
+			CategoryID: listing.CategoryID,
+			Attrs:      map[string][]string{"ram": {"8 GB", "16 GB"}},
+			Lang:       "en",
+			Limit:      10,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(page.Hits) != 1 || page.Hits[0].ID != listing.ID {
+			t.Fatalf("unexpected page: %#v", page)
+		}
+
+		want := []yeahapi.SearchFacet{{
+			Key:     "ram",
+			Name:    "ram",
+			Options: []yeahapi.SearchFacetOption{{ID: optionID, Value: "8 GB", Name: "8 GB", Count: 1}},
+		}}
+
+		if !reflect.DeepEqual(page.Facets, want) {
+			t.Fatalf("mismatch: %#v != %#v", page.Facets, want)
+		}
+
+		page, err = s.Search(ctx, yeahapi.ListingSearch{
+			CategoryID: listing.CategoryID,
+			Attrs:      map[string][]string{"ram": {"16 GB"}},
+			Limit:      10,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		} else if len(page.Hits) != 0 {
+			t.Fatalf("unexpected page: %#v", page)
+		}
+	})
+}
+
+func MustCreateActiveListing(tb testing.TB, ctx context.Context, pool *pgxpool.Pool, title string) *yeahapi.Listing {
+	tb.Helper()
+	listing := MustCreateListing(tb, ctx, pool)
+	listing, err := postgres.NewListingService(pool).UpdateListing(ctx, listing.ID, yeahapi.ListingUpdate{
+		Title:     &title,
+		UpdatedAt: listing.UpdatedAt,
+	})
+
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	if _, err := pool.Exec(ctx, "update listings set status = $1 where id = $2", yeahapi.ListingStatusActive, listing.ID); err != nil {
+		tb.Fatal(err)
+	}
+
+	listing.Status = yeahapi.ListingStatusActive
+	return listing
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+// similarCandidates bounds how many of the newest listings around the
+// category of a listing are scored, so that big categories stay cheap.
+const similarCandidates = 1000
+
+type RecommendationService struct {
+	pool    *pgxpool.Pool
+	Weights yeahapi.SimilarWeights
+}
+
+func NewRecommendationService(pool *pgxpool.Pool) *RecommendationService {
+	return &RecommendationService{
+		pool:    pool,
+		Weights: yeahapi.DefaultSimilarWeights,
+	}
+}
+
+// similarQuery scores the candidates of listing $1: active listings at most
+// $3 steps away in the category tree, plus the ones viewed by the same
+// visitors. Distances are counted through the closest common ancestor.
+// Prices are compared in the currency of the listing, by the cheapest sku of
+// each.
+const similarQuery = `with recursive paths (category_id, ancestor_id, depth) as (
+		select id, id, 0 from categories
+		union all
+		select p.category_id, c.parent_id, p.depth + 1 from paths p
+		join categories c on c.id = p.ancestor_id where c.parent_id is not null and p.depth < 10
+	),
+	source as (select id, lower(title) as title, category_id from listings where id = $1),
+	source_price as (
+		select e.price, e.price_currency from effective_sku_prices e where e.listing_id = $1 and e.price > 0
+		order by convert_amount(e.price, e.price_currency,
+			(select price_currency from listing_skus where listing_id = $1 order by id limit 1)) nulls last, e.price
+		limit 1
+	),
+	source_attrs as (
+		select distinct a.key, a.value from listing_skus s cross join jsonb_each_text(s.attrs) a where s.listing_id = $1
+	),
+	distances as (
+		select t.category_id, min(s.depth + t.depth) as distance from paths s
+		join paths t on t.ancestor_id = s.ancestor_id
+		where s.category_id = (select category_id from source) group by t.category_id
+	),
+	coviews as (
+		select v.listing_id, count(distinct v.visitor) as views from listing_visitors s
+		join listing_visitors v on v.visitor = s.visitor and v.kind = s.kind and v.listing_id <> s.listing_id
+		where s.listing_id = $1 and s.kind = 'VIEW' and $9::float8 > 0 group by v.listing_id
+	),
+	candidates as (
+		(select l.id from listings l join distances d on d.category_id = l.category_id
+		where l.status = $2 and l.id <> $1 and d.distance <= $3 order by l.id desc limit $4)
+		union
+		select listing_id from coviews
+	),
+	scored as (
+		select l.id, (
+			$5::float8 * coalesce(1 / (1 + d.distance)::float8, 0) +
+			$6::float8 * coalesce((
+				select count(*) from (
+					select distinct a.key, a.value from listing_skus ls cross join jsonb_each_text(ls.attrs) a where ls.listing_id = l.id
+				) ca join source_attrs sa using (key, value)
+			)::float8 / nullif((select count(*) from source_attrs), 0), 0) +
+			$7::float8 * coalesce(case when cp.price > 0 then greatest(0, 1 - abs(ln(cp.price::float8 / sp.price)) / ln(2)) end, 0) +
+			$8::float8 * similarity(lower(l.title), (select title from source)) +
+			$9::float8 * coalesce(cv.views::float8 / (select max(views) from coviews), 0)
+		) / ($5::float8 + $6::float8 + $7::float8 + $8::float8 + $9::float8) as score
+		from candidates c join listings l on l.id = c.id and l.status = $2 and l.id <> $1
+		left join distances d on d.category_id = l.category_id
+		left join coviews cv on cv.listing_id = l.id
+		left join source_price sp on true
+		left join lateral (
+			select min(convert_amount(e.price, e.price_currency, sp.price_currency)) as price
+			from effective_sku_prices e where e.listing_id = l.id
+		) cp on true
+	)`
+
+func (s *RecommendationService) SimilarListings(ctx context.Context, listingID uuid.UUID, lang string, limit int) ([]yeahapi.SimilarListing, error) {
+	const op yeahapi.Op = "postgres/RecommendationService.SimilarListings"
+	if limit <= 0 || limit > yeahapi.MaxSimilarListings {
+		return nil, yeahapi.E(op, yeahapi.EInvalid, "Limit is out of range")
+	}
+
+	w := s.Weights
+	if w.Category+w.Attrs+w.Price+w.Title+w.CoViews <= 0 {
+		return nil, yeahapi.E(op, yeahapi.EInvalid, "At least one similarity weight must be positive")
+	}
+
+	var exists bool
+	err := s.pool.QueryRow(ctx, "select true from listings where id = $1 and status <> $2", listingID, yeahapi.ListingStatusDeleted).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, yeahapi.E(op, yeahapi.ENotFound, "Listing not found")
+		}
+		return nil, yeahapi.E(op, err)
+	}
+
+	rows, err := s.pool.Query(ctx, similarQuery+`
+		select `+listingColumns+`, p.price, p.price_currency, coalesce(st.quantity = 0, false), sc.score
+		from scored sc join listings l on l.id = sc.id
+		left join lateral (`+minPrice+`) p on true
+		left join listing_stock st on st.listing_id = l.id
+		left join listings_tr tr on tr.listing_id = l.id and tr.lang_code = $10
+		where sc.score > 0 order by sc.score desc, l.id desc limit $11`,
+		listingID, yeahapi.ListingStatusActive, yeahapi.MaxCategoryDistance, similarCandidates,
+		w.Category, w.Attrs, w.Price, w.Title, w.CoViews, lang, limit)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	listings := make([]yeahapi.SimilarListing, 0)
+	for rows.Next() {
+		var l yeahapi.SimilarListing
+		var price *int
+		var currency *yeahapi.Currency
+		if err := rows.Scan(append(listingFields(&l.Listing), &price, &currency, &l.SoldOut, &l.Score)...); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+
+		if price != nil {
+			l.MinPrice = &yeahapi.ListingPrice{Amount: *price, Currency: *currency}
+		}
+
+		listings = append(listings, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return listings, nil
+}
@@ -0,0 +1,117 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestRecommendationService_SimilarListings(t *testing.T) {
+	s := postgres.NewRecommendationService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		root := MustCreateCategory(t, ctx, pool, &yeahapi.Category{})
+		phones := MustCreateCategory(t, ctx, pool, &yeahapi.Category{ParentID: &root.ID})
+		tablets := MustCreateCategory(t, ctx, pool, &yeahapi.Category{ParentID: &root.ID})
+		other := MustCreateCategory(t, ctx, pool, &yeahapi.Category{})
+
+		listing := MustCreateActiveListing(t, ctx, pool, "Apple iPhone 13 128GB")
+		MustMoveToCategory(t, ctx, pool, listing, phones.ID)
+		same := MustCreateActiveListing(t, ctx, pool, "Apple iPhone 13 256GB")
+		MustMoveToCategory(t, ctx, pool, same, phones.ID)
+		sibling := MustCreateActiveListing(t, ctx, pool, "Apple iPad mini")
+		MustMoveToCategory(t, ctx, pool, sibling, tablets.ID)
+		unrelated := MustCreateActiveListing(t, ctx, pool, "Apple iPhone 13 case")
+		MustMoveToCategory(t, ctx, pool, unrelated, other.ID)
+
+		listings, err := s.SimilarListings(ctx, listing.ID, "en", yeahapi.MaxSimilarListings)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		found := make(map[uuid.UUID]int)
+		for i, l := range listings {
+			found[l.ID] = i
+		}
+
+		if _, ok := found[listing.ID]; ok {
+			t.Fatal("listing is similar to itself")
+		} else if _, ok := found[unrelated.ID]; ok {
+			t.Fatal("listing from an unrelated category found")
+		}
+
+		i, ok := found[same.ID]
+		if !ok {
+			t.Fatal("listing from the same category not found")
+		}
+		j, ok := found[sibling.ID]
+		if !ok {
+			t.Fatal("listing from a sibling category not found")
+		} else if i > j {
+			t.Fatalf("expected listing from the same category first, got %d and %d", i, j)
+		}
+	})
+
+	t.Run("MixedCurrencies", func(t *testing.T) {
+		ctx := context.Background()
+		if _, err := postgres.NewCurrencyService(pool).SaveRates(ctx, []yeahapi.ExchangeRate{
+			{Base: yeahapi.CurrencyUSD, Quote: yeahapi.CurrencyUZS, Rate: "12345.67", Date: time.Now(), Source: "test"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		category := MustCreateCategory(t, ctx, pool, &yeahapi.Category{})
+		listing := MustCreateActiveListing(t, ctx, pool, "Samsung Galaxy A54")
+		MustMoveToCategory(t, ctx, pool, listing, category.ID)
+		other := MustCreateActiveListing(t, ctx, pool, "Samsung Galaxy A34")
+		MustMoveToCategory(t, ctx, pool, other, category.ID)
+		MustCreateSku(t, ctx, pool, other.ID)
+		// 1000 UZS is less than the 2.99 USD of the other sku, though the amount
+		// is bigger.
+		if _, err := postgres.NewListingService(pool).CreateSku(ctx, &yeahapi.ListingSku{
+			ListingID:     other.ID,
+			Price:         100000,
+			PriceCurrency: yeahapi.CurrencyUZS,
+			Attrs:         yeahapi.ListingAttrs{"ram": "4 GB"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		listings, err := s.SimilarListings(ctx, listing.ID, "en", yeahapi.MaxSimilarListings)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := yeahapi.ListingPrice{Amount: 100000, Currency: yeahapi.CurrencyUZS}
+		for _, l := range listings {
+			if l.ID != other.ID {
+				continue
+			}
+			if l.MinPrice == nil || *l.MinPrice != want {
+				t.Fatalf("unexpected min price: %#v", l.MinPrice)
+			}
+			return
+		}
+		t.Fatal("listing from the same category not found")
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		ctx := context.Background()
+		if _, err := s.SimilarListings(ctx, uuid.Must(uuid.NewV7()), "en", 10); !yeahapi.EIs(yeahapi.ENotFound, err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("ErrLimit", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		if _, err := s.SimilarListings(ctx, listing.ID, "en", yeahapi.MaxSimilarListings+1); !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type SitemapService struct {
+	pool *pgxpool.Pool
+}
+
+func NewSitemapService(pool *pgxpool.Pool) *SitemapService {
+	return &SitemapService{
+		pool: pool,
+	}
+}
+
+// ListingPages walks the active listings a page at a time, each page picking
+// up after the last listing of the previous one.
+func (s *SitemapService) ListingPages(ctx context.Context, size int) ([]yeahapi.SitemapPage, error) {
+	const op yeahapi.Op = "postgres/SitemapService.ListingPages"
+	if size <= 0 {
+		return nil, yeahapi.E(op, yeahapi.EInvalid, "Page size must be positive")
+	}
+
+	pages := make([]yeahapi.SitemapPage, 0)
+	for after := uuid.Nil; ; {
+		var (
+			p     yeahapi.SitemapPage
+			count int
+		)
+		err := s.pool.QueryRow(ctx,
+			`select (array_agg(id order by id))[1], (array_agg(id order by id desc))[1], max(updated_at), count(*) from (
+				select id, coalesce(updated_at, created_at) updated_at from listings
+				where status = $1 and id > $2 order by id limit $3
+			) l having count(*) > 0`, yeahapi.ListingStatusActive, after, size).Scan(&p.First, &after, &p.LastModified, &count)
+
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return pages, nil
+			}
+			return nil, yeahapi.E(op, err)
+		}
+
+		pages = append(pages, p)
+		if count < size {
+			return pages, nil
+		}
+	}
+}
+
+func (s *SitemapService) Listings(ctx context.Context, lang string, first uuid.UUID, size int) ([]yeahapi.SitemapListing, error) {
+	const op yeahapi.Op = "postgres/SitemapService.Listings"
+	if size <= 0 {
+		return nil, yeahapi.E(op, yeahapi.EInvalid, "Page size must be positive")
+	}
+
+	listings := make([]yeahapi.SitemapListing, 0)
+	rows, err := s.pool.Query(ctx,
+		`select l.id, coalesce(tr.title, l.title), coalesce(l.updated_at, l.created_at) from listings l
+		left join listings_tr tr on tr.listing_id = l.id and tr.lang_code = $2
+		where l.status = $1 and l.id >= $3 order by l.id limit $4`, yeahapi.ListingStatusActive, lang, first, size)
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	for rows.Next() {
+		var l yeahapi.SitemapListing
+		if err := rows.Scan(&l.ID, &l.Title, &l.UpdatedAt); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		listings = append(listings, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return listings, nil
+}
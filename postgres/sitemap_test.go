@@ -0,0 +1,88 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestSitemapService_Listings(t *testing.T) {
+	s := postgres.NewSitemapService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		draft := MustCreateListing(t, ctx, pool)
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+
+		pages, err := s.ListingPages(ctx, 2)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(pages) == 0 {
+			t.Fatal("expected sitemap pages")
+		}
+
+		// The listing is the newest one, so it is on the last page.
+		last := pages[len(pages)-1]
+		listings, err := s.Listings(ctx, "en", last.First, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		found := listings[len(listings)-1]
+		if found.ID != listing.ID || found.Title != "Bicycle" {
+			t.Fatalf("unexpected last listing: %#v", found)
+		} else if last.LastModified.Before(found.UpdatedAt) {
+			t.Fatalf("page modified at %s before its listing at %s", last.LastModified, found.UpdatedAt)
+		}
+
+		for _, l := range listings {
+			if l.ID == draft.ID {
+				t.Fatal("draft listing in sitemap")
+			}
+		}
+	})
+
+	t.Run("Translated", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		if _, err := postgres.NewListingService(pool).SetTranslation(ctx, &yeahapi.ListingTranslation{
+			ListingID: listing.ID,
+			Lang:      "ru",
+			Title:     "Велосипед",
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		// The translation sent the listing back to moderation, approve it again.
+		if _, err := pool.Exec(ctx, "update listings set status = $1 where id = $2", yeahapi.ListingStatusActive, listing.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		pages, err := s.ListingPages(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// With a listing per page, the newest listing starts the last page.
+		last := pages[len(pages)-1]
+		if last.First != listing.ID {
+			t.Fatalf("unexpected last page: %#v", last)
+		}
+
+		listings, err := s.Listings(ctx, "ru", last.First, 1)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(listings) != 1 || listings[0].ID != listing.ID || listings[0].Title != "Велосипед" {
+			t.Fatalf("unexpected listings: %#v", listings)
+		}
+	})
+
+	t.Run("ErrPageSize", func(t *testing.T) {
+		if _, err := s.Listings(context.Background(), "en", uuid.Nil, 0); !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+// visitorDays is how many days visitors are remembered for. Hits for older
+// days are no longer deduplicated, but hits are only ever recorded for the
+// current day, give or take a flush.
+const visitorDays = 2
+
+type StatsService struct {
+	pool *pgxpool.Pool
+}
+
+func NewStatsService(pool *pgxpool.Pool) *StatsService {
+	return &StatsService{
+		pool: pool,
+	}
+}
+
+// RecordHits remembers visitors and counts only the ones that weren't
+// remembered yet, in a single statement. Hits of listings that are gone are
+// dropped.
+func (s *StatsService) RecordHits(ctx context.Context, hits []yeahapi.ListingHit) error {
+	const op yeahapi.Op = "postgres/StatsService.RecordHits"
+
+	if len(hits) == 0 {
+		return nil
+	}
+
+	listingIDs := make([]uuid.UUID, len(hits))
+	kinds := make([]string, len(hits))
+	days := make([]string, len(hits))
+	visitors := make([]string, len(hits))
+	for i, hit := range hits {
+		listingIDs[i] = hit.ListingID
+		kinds[i] = string(hit.Kind)
+		days[i] = hit.Day.UTC().Format("2006-01-02")
+		visitors[i] = hit.VisitorID
+	}
+
+	_, err := s.pool.Exec(ctx,
+		`with counted as (
+			insert into listing_visitors (listing_id, kind, day, visitor)
+			select h.listing_id, h.kind, h.day, h.visitor
+			from unnest($1::uuid[], $2::varchar[], $3::date[], $4::varchar[]) as h (listing_id, kind, day, visitor)
+			join listings l on l.id = h.listing_id
+			on conflict do nothing
+			returning listing_id, kind, day
+		)
+		insert into listing_stats (listing_id, day, impressions, views, contact_reveals)
+		select listing_id, day,
+			count(*) filter (where kind = 'IMPRESSION'),
+			count(*) filter (where kind = 'VIEW'),
+			count(*) filter (where kind = 'CONTACT_REVEAL')
+		from counted group by listing_id, day
+		on conflict (listing_id, day) do update set
+			impressions = listing_stats.impressions + excluded.impressions,
+			views = listing_stats.views + excluded.views,
+			contact_reveals = listing_stats.contact_reveals + excluded.contact_reveals`,
+		listingIDs, kinds, days, visitors)
+
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	_, err = s.pool.Exec(ctx, "delete from listing_visitors where day < current_date - $1::int", visitorDays)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	return nil
+}
+
+func (s *StatsService) ListingStats(ctx context.Context, filter yeahapi.StatsFilter) ([]yeahapi.ListingStats, error) {
+	const op yeahapi.Op = "postgres/StatsService.ListingStats"
+
+	if err := filter.Ok(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`select d.day, coalesce(s.impressions, 0), coalesce(s.views, 0), coalesce(f.count, 0), coalesce(s.contact_reveals, 0)
+		from (select generate_series($2::date, $3::date, interval '1 day')::date as day) d
+		left join listing_stats s on s.listing_id = $1 and s.day = d.day
+		left join (
+			select (created_at at time zone 'UTC')::date as day, count(*) from favorites
+			where listing_id = $1 group by 1
+		) f on f.day = d.day
+		order by d.day`,
+		filter.ListingID, filter.From.UTC().Format("2006-01-02"), filter.To.UTC().Format("2006-01-02"))
+
+	defer rows.Close()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	stats := make([]yeahapi.ListingStats, 0)
+	for rows.Next() {
+		var day yeahapi.ListingStats
+		if err := rows.Scan(&day.Day, &day.Impressions, &day.Views, &day.Favorites, &day.ContactReveals); err != nil {
+			return nil, yeahapi.E(op, err)
+		}
+		stats = append(stats, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return stats, nil
+}
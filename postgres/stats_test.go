@@ -0,0 +1,70 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/postgres"
+)
+
+func TestStatsService_RecordHits(t *testing.T) {
+	s := postgres.NewStatsService(pool)
+
+	t.Run("OK", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateActiveListing(t, ctx, pool, "Bicycle")
+		user := MustCreateUser(t, ctx, pool, &yeahapi.User{Email: randEmail(), FirstName: "John", LastName: "Doe"})
+		if err := postgres.NewFavoriteService(pool).AddFavorite(ctx, user.ID, listing.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		now := time.Now()
+		yesterday := now.AddDate(0, 0, -1)
+		err := s.RecordHits(ctx, []yeahapi.ListingHit{
+			yeahapi.NewListingHit(listing.ID, yeahapi.HitView, "a", yesterday),
+			yeahapi.NewListingHit(listing.ID, yeahapi.HitView, "a", now),
+			yeahapi.NewListingHit(listing.ID, yeahapi.HitView, "b", now),
+			yeahapi.NewListingHit(listing.ID, yeahapi.HitImpression, "a", now),
+			yeahapi.NewListingHit(listing.ID, yeahapi.HitContactReveal, "b", now),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Visitors are counted once a day.
+		if err := s.RecordHits(ctx, []yeahapi.ListingHit{yeahapi.NewListingHit(listing.ID, yeahapi.HitView, "a", now)}); err != nil {
+			t.Fatal(err)
+		}
+
+		stats, err := s.ListingStats(ctx, yeahapi.StatsFilter{ListingID: listing.ID, From: yesterday.AddDate(0, 0, -1), To: now})
+		if err != nil {
+			t.Fatal(err)
+		} else if len(stats) != 3 {
+			t.Fatalf("unexpected stats: %#v", stats)
+		}
+
+		want := []yeahapi.ListingStats{
+			{},
+			{Views: 1},
+			{Impressions: 1, Views: 2, Favorites: 1, ContactReveals: 1},
+		}
+		for i, day := range stats {
+			day.Day = time.Time{}
+			if day != want[i] {
+				t.Fatalf("unexpected stats for day %d: %#v", i, day)
+			}
+		}
+	})
+
+	t.Run("ErrTooLong", func(t *testing.T) {
+		ctx := context.Background()
+		listing := MustCreateListing(t, ctx, pool)
+		now := time.Now()
+		_, err := s.ListingStats(ctx, yeahapi.StatsFilter{ListingID: listing.ID, From: now.AddDate(-1, 0, 0), To: now})
+		if !yeahapi.EIs(yeahapi.EInvalid, err) {
+			t.Fatalf("expected invalid error, got %v", err)
+		}
+	})
+}
@@ -140,6 +140,15 @@ func (s *UserService) LinkAccount(ctx context.Context, account *yeahapi.Account)
 	return nil
 }
 
+func (s *UserService) IsAdmin(ctx context.Context, id yeahapi.UserID) (bool, error) {
+	const op yeahapi.Op = "postgres/UserService.IsAdmin"
+	var ok bool
+	if err := s.pool.QueryRow(ctx, "select exists(select 1 from admins where user_id = $1)", id).Scan(&ok); err != nil {
+		return false, yeahapi.E(op, err)
+	}
+	return ok, nil
+}
+
 func linkAccount(ctx context.Context, tx pgx.Tx, account *yeahapi.Account) error {
 	const op yeahapi.Op = "postgres/UserService.linkAccount"
 	if err := account.Ok(); err != nil {
@@ -0,0 +1,109 @@
+package yeahapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// PriceDrop is a sku price that has just come into effect and is lower than
+// the one before it.
+type PriceDrop struct {
+	ListingID      uuid.UUID
+	SkuID          uuid.UUID
+	Amount         int
+	PreviousAmount int
+	Currency       Currency
+	// StartDate identifies the price that dropped among the sku prices.
+	StartDate time.Time
+}
+
+// PriceService manages scheduled sku prices. The price in effect is the one
+// with the latest start date that has passed.
+type PriceService interface {
+	// SchedulePrice sets the sku price from price.StartDate on. With until
+	// set, the price that would have been in effect at until is restored
+	// then, which makes a sale window.
+	SchedulePrice(ctx context.Context, price *ListingSkuPrice, until *time.Time) error
+	PriceHistory(ctx context.Context, skuID uuid.UUID) ([]ListingSkuPrice, error)
+	// AnnouncePrices returns the price drops that have come into effect and
+	// weren't announced yet. Other prices coming into effect are marked as
+	// announced right away.
+	AnnouncePrices(ctx context.Context) ([]PriceDrop, error)
+	// MarkAnnounced marks a price drop as announced, so it isn't returned by
+	// AnnouncePrices again.
+	MarkAnnounced(ctx context.Context, drop PriceDrop) error
+}
+
+func (p *ListingSkuPrice) Ok() error {
+	if p.SkuID.IsNil() {
+		return E(EInvalid, "SKU id is required")
+	} else if p.Amount < 0 {
+		return E(EInvalid, "Price can't be negative")
+	} else if p.Currency == "" {
+		return E(EInvalid, "Currency is required")
+	} else if p.StartDate.IsZero() {
+		return E(EInvalid, "Start date is required")
+	}
+	return nil
+}
+
+// PriceWatcher periodically announces prices coming into effect and
+// publishes price drops. Only one replica does it at a time.
+type PriceWatcher struct {
+	Interval time.Duration
+
+	priceService PriceService
+	locker       Locker
+	cqrsService  CQRSService
+}
+
+func NewPriceWatcher(priceService PriceService, locker Locker, cqrsService CQRSService) *PriceWatcher {
+	return &PriceWatcher{
+		Interval:     time.Minute,
+		priceService: priceService,
+		locker:       locker,
+		cqrsService:  cqrsService,
+	}
+}
+
+// Run blocks until ctx is done.
+func (w *PriceWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.locker.TryLock(ctx, "prices.announce", w.announce); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+func (w *PriceWatcher) announce(ctx context.Context) error {
+	const op Op = "PriceWatcher.announce"
+	drops, err := w.priceService.AnnouncePrices(ctx)
+	if err != nil {
+		return E(op, err)
+	}
+
+	// Drops left unannounced after a failure are tried again on the next
+	// tick.
+	for _, drop := range drops {
+		if err := w.cqrsService.Publish(ctx, NewListingPriceDroppedEvent(drop)); err != nil {
+			return E(op, err)
+		}
+
+		if err := w.priceService.MarkAnnounced(ctx, drop); err != nil {
+			return E(op, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package yeahapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type PromotionKind string
+
+const (
+	// PromotionTop ranks the listing higher in search results.
+	PromotionTop PromotionKind = "TOP"
+	// PromotionHighlight shows the listing highlighted in lists.
+	PromotionHighlight PromotionKind = "HIGHLIGHT"
+	// PromotionUrgent shows an urgent badge on the listing.
+	PromotionUrgent PromotionKind = "URGENT"
+)
+
+func (k PromotionKind) Ok() error {
+	switch k {
+	case PromotionTop, PromotionHighlight, PromotionUrgent:
+		return nil
+	}
+	return E(EInvalid, "Unknown promotion kind")
+}
+
+// promotionCurrencies are the currencies every promotion product is priced
+// in.
+var promotionCurrencies = []Currency{CurrencyUZS, CurrencyUSD}
+
+type PromotionPrice struct {
+	Amount   int      `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// PromotionProduct is something sellers can buy for their listings: a
+// promotion of Kind that lasts DurationDays. Inactive products are kept for
+// past purchases but can't be bought anymore.
+type PromotionProduct struct {
+	ID           int              `json:"id"`
+	Code         string           `json:"code"`
+	Kind         PromotionKind    `json:"kind"`
+	DurationDays int              `json:"duration_days"`
+	Active       bool             `json:"active"`
+	Prices       []PromotionPrice `json:"prices"`
+	CreatedAt    time.Time        `json:"created_at"`
+}
+
+func (p *PromotionProduct) Ok() error {
+	if p.Code == "" || len(p.Code) > 50 {
+		return E(EInvalid, "Code is required and must be at most 50 characters")
+	} else if err := p.Kind.Ok(); err != nil {
+		return err
+	} else if p.DurationDays <= 0 || p.DurationDays > 90 {
+		return E(EInvalid, "Duration must be between 1 and 90 days")
+	}
+
+	priced := make(map[Currency]bool)
+	for _, price := range p.Prices {
+		if price.Amount <= 0 {
+			return E(EInvalid, "Prices must be positive")
+		} else if priced[price.Currency] {
+			return E(EInvalid, "Product can only have one price per currency")
+		}
+		priced[price.Currency] = true
+	}
+
+	if len(priced) != len(promotionCurrencies) {
+		return E(EInvalid, "Product must be priced in UZS and USD only")
+	}
+	for _, c := range promotionCurrencies {
+		if !priced[c] {
+			return E(EInvalid, "Product must be priced in UZS and USD only")
+		}
+	}
+	return nil
+}
+
+// Price returns the price of the product in currency, nil when there is none.
+func (p *PromotionProduct) Price(currency Currency) *PromotionPrice {
+	for i := range p.Prices {
+		if p.Prices[i].Currency == currency {
+			return &p.Prices[i]
+		}
+	}
+	return nil
+}
+
+type PaymentProvider string
+
+const (
+	PaymentProviderClick PaymentProvider = "CLICK"
+	PaymentProviderPayme PaymentProvider = "PAYME"
+)
+
+type PromotionPurchaseStatus string
+
+const (
+	PromotionPurchasePending  PromotionPurchaseStatus = "PENDING"
+	PromotionPurchasePaid     PromotionPurchaseStatus = "PAID"
+	PromotionPurchaseCanceled PromotionPurchaseStatus = "CANCELED"
+)
+
+// PromotionPurchase is created pending with the price and duration of the
+// product at the time, and becomes paid once its payment is confirmed. Paid
+// purchases of the same kind for a listing follow each other, so StartsAt is
+// when the previous one ends if it hadn't ended yet.
+type PromotionPurchase struct {
+	ID            uuid.UUID               `json:"id"`
+	ListingID     uuid.UUID               `json:"listing_id"`
+	UserID        UserID                  `json:"user_id"`
+	ProductID     int                     `json:"product_id"`
+	Kind          PromotionKind           `json:"kind"`
+	DurationDays  int                     `json:"duration_days"`
+	Amount        int                     `json:"amount"`
+	Currency      Currency                `json:"currency"`
+	Status        PromotionPurchaseStatus `json:"status"`
+	Provider      PaymentProvider         `json:"provider,omitempty"`
+	TransactionID string                  `json:"transaction_id,omitempty"`
+	PaidAt        *time.Time              `json:"paid_at"`
+	StartsAt      *time.Time              `json:"starts_at"`
+	EndsAt        *time.Time              `json:"ends_at"`
+	CreatedAt     time.Time               `json:"created_at"`
+}
+
+// PromotionPayment is a payment for a purchase as reported by a payment
+// provider. A transaction pays for one purchase only.
+type PromotionPayment struct {
+	PurchaseID    uuid.UUID       `json:"purchase_id"`
+	Provider      PaymentProvider `json:"provider"`
+	TransactionID string          `json:"transaction_id"`
+	Amount        int             `json:"amount"`
+	Currency      Currency        `json:"currency"`
+}
+
+func (p PromotionPayment) Ok() error {
+	if p.PurchaseID.IsNil() {
+		return E(EInvalid, "Purchase id is required")
+	} else if p.Provider != PaymentProviderClick && p.Provider != PaymentProviderPayme {
+		return E(EInvalid, "Unsupported payment provider")
+	} else if p.TransactionID == "" || len(p.TransactionID) > 255 {
+		return E(EInvalid, "Transaction id is required")
+	}
+	return nil
+}
+
+// ListingPromotion is a window during which a listing is promoted.
+type ListingPromotion struct {
+	Kind     PromotionKind `json:"kind"`
+	StartsAt time.Time     `json:"starts_at"`
+	EndsAt   time.Time     `json:"ends_at"`
+}
+
+type PromotionService interface {
+	CreateProduct(ctx context.Context, product *PromotionProduct) (*PromotionProduct, error)
+	// UpdateProduct replaces the prices, duration and whether the product is
+	// active. Pending purchases keep their price.
+	UpdateProduct(ctx context.Context, product *PromotionProduct) (*PromotionProduct, error)
+	Products(ctx context.Context, activeOnly bool) ([]PromotionProduct, error)
+	// Purchase creates a pending purchase of a product for an active listing
+	// of userID, priced in currency.
+	Purchase(ctx context.Context, userID UserID, listingID uuid.UUID, productID int, currency Currency) (*PromotionPurchase, error)
+	// ConfirmPayment marks the purchase paid and schedules its promotion.
+	// Confirming the same transaction again returns the purchase as it is.
+	ConfirmPayment(ctx context.Context, payment PromotionPayment) (*PromotionPurchase, error)
+	Purchases(ctx context.Context, listingID uuid.UUID) ([]PromotionPurchase, error)
+	// ListingPromotions returns the paid promotion windows of a listing that
+	// didn't end yet, including the ones that are yet to start.
+	ListingPromotions(ctx context.Context, listingID uuid.UUID) ([]ListingPromotion, error)
+}
@@ -0,0 +1,189 @@
+package yeahapi
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// DefaultReportThreshold is how many users have to report an active listing
+// before it goes back to moderation.
+const DefaultReportThreshold = 3
+
+type ReportTargetKind string
+
+const (
+	ReportTargetListing ReportTargetKind = "LISTING"
+	ReportTargetUser    ReportTargetKind = "USER"
+	ReportTargetMessage ReportTargetKind = "MESSAGE"
+)
+
+func (k ReportTargetKind) Ok() error {
+	switch k {
+	case ReportTargetListing, ReportTargetUser, ReportTargetMessage:
+		return nil
+	}
+	return E(EInvalid, "Unknown report target")
+}
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen     ReportStatus = "OPEN"
+	ReportStatusResolved ReportStatus = "RESOLVED"
+)
+
+type ReportOutcome string
+
+const (
+	// ReportActionTaken means the target was found breaking the rules and
+	// was dealt with.
+	ReportActionTaken ReportOutcome = "ACTION_TAKEN"
+	// ReportDismissed means nothing wrong was found.
+	ReportDismissed ReportOutcome = "DISMISSED"
+)
+
+func (o ReportOutcome) Ok() error {
+	switch o {
+	case ReportActionTaken, ReportDismissed:
+		return nil
+	}
+	return E(EInvalid, "Unknown report outcome")
+}
+
+type ReportReason struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Report is a complaint of a user about a listing, another user or a
+// message. A user has at most one open report per target, reporting it again
+// returns the open report.
+type Report struct {
+	ID          uuid.UUID        `json:"id"`
+	ReporterID  UserID           `json:"reporter_id"`
+	TargetKind  ReportTargetKind `json:"target_kind"`
+	TargetID    uuid.UUID        `json:"target_id"`
+	ReasonCode  string           `json:"reason_code"`
+	Detail      string           `json:"detail"`
+	Status      ReportStatus     `json:"status"`
+	Outcome     ReportOutcome    `json:"outcome,omitempty"`
+	ModeratorID *UserID          `json:"moderator_id,omitempty"`
+	Comment     string           `json:"comment,omitempty"`
+	ResolvedAt  *time.Time       `json:"resolved_at"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+func (r *Report) Ok() error {
+	if err := r.TargetKind.Ok(); err != nil {
+		return err
+	} else if r.TargetID.IsNil() {
+		return E(EInvalid, "Target id is required")
+	} else if r.ReasonCode == "" {
+		return E(EInvalid, "Reason is required")
+	} else if utf8.RuneCountInString(r.Detail) > 1000 {
+		return E(EInvalid, "Detail must be at most 1000 characters")
+	}
+	return nil
+}
+
+// ReportedTarget is an entry of the moderators' report queue: a target with
+// open reports, counted per reporter.
+type ReportedTarget struct {
+	TargetKind      ReportTargetKind `json:"target_kind"`
+	TargetID        uuid.UUID        `json:"target_id"`
+	Reports         int              `json:"reports"`
+	ReasonCodes     []string         `json:"reason_codes"`
+	FirstReportedAt time.Time        `json:"first_reported_at"`
+}
+
+// ReportResolution resolves every open report of a target at once.
+type ReportResolution struct {
+	TargetKind  ReportTargetKind `json:"target_kind"`
+	TargetID    uuid.UUID        `json:"target_id"`
+	ModeratorID UserID           `json:"-"`
+	Outcome     ReportOutcome    `json:"outcome"`
+	Comment     string           `json:"comment"`
+}
+
+func (r ReportResolution) Ok() error {
+	if err := r.TargetKind.Ok(); err != nil {
+		return err
+	} else if r.TargetID.IsNil() {
+		return E(EInvalid, "Target id is required")
+	} else if err := r.Outcome.Ok(); err != nil {
+		return err
+	} else if utf8.RuneCountInString(r.Comment) > 1000 {
+		return E(EInvalid, "Comment must be at most 1000 characters")
+	}
+	return nil
+}
+
+type ReportService interface {
+	Reasons(ctx context.Context, lang string) ([]ReportReason, error)
+	// CreateReport returns the reporter's open report of the target if there
+	// is one already. A ReportCreatedEvent is published once a new report
+	// commits.
+	CreateReport(ctx context.Context, report *Report) (*Report, error)
+	// OpenReports counts the users with an open report of the target.
+	OpenReports(ctx context.Context, kind ReportTargetKind, targetID uuid.UUID) (int, error)
+	// Queue returns reported targets, the most reported first.
+	Queue(ctx context.Context, limit int) ([]ReportedTarget, error)
+	Reports(ctx context.Context, kind ReportTargetKind, targetID uuid.UUID) ([]Report, error)
+	// Resolve returns the reports it resolved. A ReportResolvedEvent for each
+	// of them is published once they commit.
+	Resolve(ctx context.Context, resolution ReportResolution) ([]Report, error)
+}
+
+// ReportEscalator sends active listings back to moderation once enough users
+// reported them.
+type ReportEscalator struct {
+	Threshold int
+
+	reportService  ReportService
+	listingService ListingService
+}
+
+func NewReportEscalator(reportService ReportService, listingService ListingService) *ReportEscalator {
+	return &ReportEscalator{
+		Threshold:      DefaultReportThreshold,
+		reportService:  reportService,
+		listingService: listingService,
+	}
+}
+
+func (e *ReportEscalator) ReportCreated(m jetstream.Msg) error {
+	const op Op = "ReportEscalator.ReportCreated"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var event ReportCreatedEvent
+	if err := json.Unmarshal(m.Data(), &event); err != nil {
+		return E(op, err)
+	}
+
+	if event.TargetKind != ReportTargetListing {
+		return nil
+	}
+
+	reports, err := e.reportService.OpenReports(ctx, event.TargetKind, event.TargetID)
+	if err != nil {
+		return E(op, err)
+	} else if reports < e.Threshold {
+		return nil
+	}
+
+	if _, err := e.listingService.UpdateStatus(ctx, event.TargetID, ListingStatusActive, ListingStatusModeration); err != nil {
+		// The listing isn't active anymore, there is nothing to take down.
+		if EIs(EConflict, err) || EIs(ENotFound, err) {
+			return nil
+		}
+		return E(op, err)
+	}
+
+	return nil
+}
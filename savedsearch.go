@@ -0,0 +1,192 @@
+package yeahapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// MaxSavedSearches is how many searches a user may save.
+const MaxSavedSearches = 50
+
+// SavedSearchFrequency is how often a user is told about new listings
+// matching their saved searches. Matches are batched in between.
+type SavedSearchFrequency string
+
+const (
+	SavedSearchInstant SavedSearchFrequency = "INSTANT"
+	SavedSearchDaily   SavedSearchFrequency = "DAILY"
+	SavedSearchWeekly  SavedSearchFrequency = "WEEKLY"
+)
+
+func (f SavedSearchFrequency) Ok() error {
+	switch f {
+	case SavedSearchInstant, SavedSearchDaily, SavedSearchWeekly:
+		return nil
+	}
+	return E(EInvalid, "Unknown frequency")
+}
+
+// SavedSearch is a search a user wants to hear about new matches of.
+type SavedSearch struct {
+	ID         uuid.UUID           `json:"id"`
+	UserID     UserID              `json:"user_id"`
+	Name       string              `json:"name"`
+	Query      string              `json:"q"`
+	CategoryID int                 `json:"category_id"`
+	MinPrice   *int                `json:"min_price"`
+	MaxPrice   *int                `json:"max_price"`
+	Currency   Currency            `json:"currency"`
+	Attrs      map[string][]string `json:"attrs"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+func (s *SavedSearch) Ok() error {
+	if s.UserID.IsNil() {
+		return E(EInvalid, "User id is required")
+	} else if len(s.Name) > 255 {
+		return E(EInvalid, "Name is too long")
+	}
+	return s.Search(1).Ok()
+}
+
+// Search returns the listing search a saved search stands for.
+func (s *SavedSearch) Search(limit int) ListingSearch {
+	return ListingSearch{
+		Query:      s.Query,
+		CategoryID: s.CategoryID,
+		MinPrice:   s.MinPrice,
+		MaxPrice:   s.MaxPrice,
+		Currency:   s.Currency,
+		Attrs:      s.Attrs,
+		Limit:      limit,
+	}
+}
+
+// SavedSearchMatch is a listing that matched a saved search after it was
+// saved.
+type SavedSearchMatch struct {
+	SavedSearchID uuid.UUID `json:"saved_search_id"`
+	Name          string    `json:"name"`
+	ListingID     uuid.UUID `json:"listing_id"`
+}
+
+// SavedSearchDigest is a batch of matches a user is due to be told about.
+type SavedSearchDigest struct {
+	UserID  UserID
+	Matches []SavedSearchMatch
+}
+
+type SavedSearchService interface {
+	CreateSavedSearch(ctx context.Context, search *SavedSearch) (*SavedSearch, error)
+	SavedSearches(ctx context.Context, userID UserID) ([]SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, userID UserID, id uuid.UUID) error
+	Frequency(ctx context.Context, userID UserID) (SavedSearchFrequency, error)
+	SetFrequency(ctx context.Context, userID UserID, frequency SavedSearchFrequency) error
+	// MatchListing records the saved searches of other users an active
+	// listing matches. Listings are matched once per saved search.
+	MatchListing(ctx context.Context, listingID uuid.UUID) ([]SavedSearchMatch, error)
+	// Digests returns the matches of users that are due to be told about
+	// them at now, going by their frequency.
+	Digests(ctx context.Context, now time.Time) ([]SavedSearchDigest, error)
+	// MarkNotified records that the user was told about the digest at now.
+	MarkNotified(ctx context.Context, digest SavedSearchDigest, now time.Time) error
+}
+
+// SavedSearchMatcher matches listings against saved searches as they are
+// published.
+type SavedSearchMatcher struct {
+	savedSearchService SavedSearchService
+}
+
+func NewSavedSearchMatcher(savedSearchService SavedSearchService) *SavedSearchMatcher {
+	return &SavedSearchMatcher{
+		savedSearchService: savedSearchService,
+	}
+}
+
+func (m *SavedSearchMatcher) ListingPublished(msg jetstream.Msg) error {
+	const op Op = "SavedSearchMatcher.ListingPublished"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	var event ListingStatusChangedEvent
+	if err := json.Unmarshal(msg.Data(), &event); err != nil {
+		return E(op, err)
+	}
+
+	if _, err := m.savedSearchService.MatchListing(ctx, event.ListingID); err != nil {
+		if EIs(ENotFound, err) {
+			return nil
+		}
+		return E(op, err)
+	}
+
+	return nil
+}
+
+// SavedSearchNotifier periodically publishes the matches users are due to be
+// told about. Only one replica does it at a time.
+type SavedSearchNotifier struct {
+	Interval time.Duration
+
+	savedSearchService SavedSearchService
+	locker             Locker
+	cqrsService        CQRSService
+}
+
+func NewSavedSearchNotifier(savedSearchService SavedSearchService, locker Locker, cqrsService CQRSService) *SavedSearchNotifier {
+	return &SavedSearchNotifier{
+		Interval:           time.Minute,
+		savedSearchService: savedSearchService,
+		locker:             locker,
+		cqrsService:        cqrsService,
+	}
+}
+
+// Run blocks until ctx is done.
+func (n *SavedSearchNotifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(n.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := n.locker.TryLock(ctx, "savedsearches.notify", n.notify); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+func (n *SavedSearchNotifier) notify(ctx context.Context) error {
+	const op Op = "SavedSearchNotifier.notify"
+	now := time.Now()
+	digests, err := n.savedSearchService.Digests(ctx, now)
+	if err != nil {
+		return E(op, err)
+	}
+
+	// A digest that fails to go out stays pending and is tried again on the
+	// next tick, without holding up the digests of other users.
+	for _, digest := range digests {
+		if len(digest.Matches) > 0 {
+			if err := n.cqrsService.Publish(ctx, NewSavedSearchMatchedEvent(digest)); err != nil {
+				fmt.Println(E(op, err))
+				continue
+			}
+		}
+
+		if err := n.savedSearchService.MarkNotified(ctx, digest, now); err != nil {
+			fmt.Println(E(op, err))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package yeahapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// ListingSearch is a full-text query over active listings. With Currency
+// set, prices are converted into it and price bounds apply to the converted
+// sku prices. Attrs maps attribute keys to accepted option
+// values; a listing matches when one of its skus has an accepted value for
+// every key. LocationID matches listings anywhere inside of a region,
+// district or city. With Near set, only listings with a point within Radius
+// kilometers of it match. Cursor is opaque and comes from a previous page of
+// the same query.
+type ListingSearch struct {
+	Query      string
+	CategoryID int
+	LocationID int
+	Near       *Point
+	Radius     float64
+	MinPrice   *int
+	MaxPrice   *int
+	Currency   Currency
+	Attrs      map[string][]string
+	Lang       string
+	Cursor     string
+	Limit      int
+}
+
+// SearchHit is a listing matching a search. Headline is the listing title
+// with matched words wrapped in <b> tags and the rest of it HTML-escaped.
+type SearchHit struct {
+	Listing
+	Headline string  `json:"headline"`
+	Rank     float64 `json:"rank"`
+	// Distance is how far the listing is from the point searched near, in
+	// kilometers.
+	Distance *float64 `json:"distance,omitempty"`
+}
+
+// SearchFacet counts listings of the whole result set, not just the page,
+// per option of an attribute.
+type SearchFacet struct {
+	Key     string              `json:"key"`
+	Name    string              `json:"name"`
+	Options []SearchFacetOption `json:"options"`
+}
+
+type SearchFacetOption struct {
+	ID    int    `json:"id"`
+	Value string `json:"value"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// SearchPage carries facets on the first page only.
+type SearchPage struct {
+	Hits       []SearchHit   `json:"hits"`
+	Facets     []SearchFacet `json:"facets,omitempty"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+type SearchService interface {
+	Search(ctx context.Context, search ListingSearch) (*SearchPage, error)
+	Index(ctx context.Context, listingID uuid.UUID) error
+}
+
+func (s ListingSearch) Ok() error {
+	if s.Limit <= 0 {
+		return E(EInvalid, "Limit must be positive")
+	} else if len(s.Query) > 256 {
+		return E(EInvalid, "Search query is too long")
+	} else if (s.MinPrice != nil || s.MaxPrice != nil) && s.Currency == "" {
+		return E(EInvalid, "Currency is required to filter by price")
+	} else if s.MinPrice != nil && s.MaxPrice != nil && *s.MinPrice > *s.MaxPrice {
+		return E(EInvalid, "Minimum price can't be greater than maximum price")
+	} else if len(s.Attrs) > 20 {
+		return E(EInvalid, "Too many attribute filters")
+	} else if (s.Near == nil) != (s.Radius == 0) {
+		return E(EInvalid, "Point and radius are required to search nearby")
+	} else if s.Radius < 0 || s.Radius > MaxSearchRadius {
+		return E(EInvalid, fmt.Sprintf("Radius must be between 0 and %d km", MaxSearchRadius))
+	} else if s.Near != nil {
+		if err := s.Near.Ok(); err != nil {
+			return err
+		}
+	}
+
+	for key, values := range s.Attrs {
+		if key == "" || len(values) == 0 {
+			return E(EInvalid, "Attribute filters need a key and at least one value")
+		}
+	}
+	return nil
+}
+
+// SearchIndexer makes approved listings searchable and publishes them.
+type SearchIndexer struct {
+	searchService  SearchService
+	listingService ListingService
+}
+
+func NewSearchIndexer(searchService SearchService, listingService ListingService) *SearchIndexer {
+	return &SearchIndexer{
+		searchService:  searchService,
+		listingService: listingService,
+	}
+}
+
+// ListingIndexing indexes a listing that entered INDEXING and moves it to
+// ACTIVE.
+func (i *SearchIndexer) ListingIndexing(m jetstream.Msg) error {
+	const op Op = "SearchIndexer.ListingIndexing"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	var event ListingStatusChangedEvent
+	if err := json.Unmarshal(m.Data(), &event); err != nil {
+		return E(op, err)
+	}
+
+	if err := i.searchService.Index(ctx, event.ListingID); err != nil {
+		if EIs(ENotFound, err) {
+			return nil
+		}
+		return E(op, err)
+	}
+
+	if _, err := i.listingService.UpdateStatus(ctx, event.ListingID, ListingStatusIndexing, ListingStatusActive); err != nil {
+		if EIs(EConflict, err) || EIs(ENotFound, err) {
+			return nil
+		}
+		return E(op, err)
+	}
+
+	return nil
+}
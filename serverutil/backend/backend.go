@@ -16,6 +16,8 @@ import (
 	"github.com/pelletier/go-toml/v2"
 	yeahapi "github.com/yeahuz/yeah-api"
 	"github.com/yeahuz/yeah-api/aws"
+	"github.com/yeahuz/yeah-api/cbu"
+	"github.com/yeahuz/yeah-api/disk"
 	"github.com/yeahuz/yeah-api/eskiz"
 	"github.com/yeahuz/yeah-api/inmem"
 	"github.com/yeahuz/yeah-api/nats"
@@ -89,7 +91,26 @@ func (m *Main) Run(ctx context.Context) (err error) {
 	kvService := postgres.NewKVService(m.Pool)
 	localizerService := yeahapi.NewLocalizerService("en")
 	clientService := postgres.NewClientService(m.Pool, argonHasher)
-	categoryService := postgres.NewCategoryService(m.Pool)
+	categoryService := inmem.NewCategoryCache(postgres.NewCategoryService(m.Pool))
+	moderationService := postgres.NewModerationService(m.Pool)
+	notificationService := postgres.NewNotificationService(m.Pool)
+	searchService := postgres.NewSearchService(m.Pool)
+	priceService := postgres.NewPriceService(m.Pool)
+	currencyService := postgres.NewCurrencyService(m.Pool)
+	inventoryService := postgres.NewInventoryService(m.Pool)
+	mediaService := postgres.NewMediaService(m.Pool)
+	locationService := postgres.NewLocationService(m.Pool)
+	favoriteService := postgres.NewFavoriteService(m.Pool)
+	savedSearchService := postgres.NewSavedSearchService(m.Pool)
+	statsService := postgres.NewStatsService(m.Pool)
+	expiryService := postgres.NewExpiryService(m.Pool)
+	promotionService := postgres.NewPromotionService(m.Pool)
+	reportService := postgres.NewReportService(m.Pool)
+	recommendationService := postgres.NewRecommendationService(m.Pool)
+	if m.Config.Similar.CoViews {
+		recommendationService.Weights.CoViews = 1
+	}
+	hitAggregator := inmem.NewHitAggregator(statsService)
 
 	cqrsService, err := nats.NewCQRSService(ctx, yeahapi.CQRSConfig{
 		NatsURL:       m.Config.Nats.URL,
@@ -106,11 +127,59 @@ func (m *Main) Run(ctx context.Context) (err error) {
 		awsconf.WithRegion("eu-north-1"),
 	)
 
+	var blobStore yeahapi.BlobStore
+	switch m.Config.Media.Store {
+	case "s3":
+		blobStore = aws.NewBlobStore(awsconfig, m.Config.Media.Endpoint, m.Config.Media.Bucket, m.Config.Media.BaseURL)
+	case "disk", "":
+		blobStore = disk.NewBlobStore(m.Config.Media.Dir, m.Config.Media.BaseURL)
+	default:
+		return fmt.Errorf("unknown media store: %s", m.Config.Media.Store)
+	}
+
 	emailService := aws.NewEmailService(awsconfig, cqrsService)
 	smsService := eskiz.NewSmsService(m.Config.Eskiz.Email, m.Config.Eskiz.Password, m.Config.Eskiz.BaseURL, cqrsService)
 
 	cqrsService.Handle("auth.sendEmailCode", emailService.SendEmailCode)
 	cqrsService.Handle("auth.sendPhoneCode", smsService.SendSmsCode)
+	contentChecker := yeahapi.NewContentChecker(listingService, moderationService)
+	contentChecker.RegisterText(
+		inmem.NewContactsCheck(),
+		postgres.NewBannedWordsCheck(m.Pool),
+	)
+	contentChecker.Register(postgres.NewPriceOutlierCheck(m.Pool))
+	contentChecker.RegisterPhoto(postgres.NewDuplicateCheck(m.Pool))
+
+	cqrsService.Handle(yeahapi.ListingModerationSubmitted, contentChecker.ListingSubmitted)
+	cqrsService.Handle(yeahapi.MediaProcessed, contentChecker.MediaProcessed)
+	cqrsService.Handle(yeahapi.ListingRejected, notificationService.ListingRejected)
+	searchIndexer := yeahapi.NewSearchIndexer(searchService, listingService)
+	cqrsService.Handle(yeahapi.ListingIndexingStarted, searchIndexer.ListingIndexing)
+	mediaProcessor := yeahapi.NewMediaProcessor(mediaService, blobStore)
+	cqrsService.Handle(yeahapi.MediaUploaded, mediaProcessor.MediaUploaded)
+	favoriteAlerter := yeahapi.NewFavoriteAlerter(favoriteService)
+	cqrsService.Handle(yeahapi.ListingPriceDropped, favoriteAlerter.PriceDropped)
+	cqrsService.Handle(yeahapi.ListingArchived, favoriteAlerter.ListingArchived)
+	cqrsService.Handle(yeahapi.ListingSoldOut, favoriteAlerter.SoldOut)
+	cqrsService.Handle(yeahapi.FavoriteAlerted, notificationService.FavoriteAlert)
+	savedSearchMatcher := yeahapi.NewSavedSearchMatcher(savedSearchService)
+	cqrsService.Handle(yeahapi.ListingPublished, savedSearchMatcher.ListingPublished)
+	cqrsService.Handle(yeahapi.SavedSearchMatched, notificationService.SavedSearchMatched)
+	cqrsService.Handle(yeahapi.ListingExpiring, notificationService.ListingExpiring)
+	reportEscalator := yeahapi.NewReportEscalator(reportService, listingService)
+	if m.Config.Reports.Threshold > 0 {
+		reportEscalator.Threshold = m.Config.Reports.Threshold
+	}
+	cqrsService.Handle(yeahapi.ReportCreated, reportEscalator.ReportCreated)
+	cqrsService.Handle(yeahapi.ReportResolved, notificationService.ReportResolved)
+
+	go yeahapi.NewPriceWatcher(priceService, postgres.NewLocker(m.Pool), cqrsService).Run(ctx)
+	go yeahapi.NewRateImporter(cbu.NewRateProvider(cbu.DefaultURL), currencyService).Run(ctx)
+	go yeahapi.NewSavedSearchNotifier(savedSearchService, postgres.NewLocker(m.Pool), cqrsService).Run(ctx)
+	go hitAggregator.Run(ctx)
+	go yeahapi.NewExpiryScheduler(expiryService, postgres.NewLocker(m.Pool)).Run(ctx)
+	go yeahapi.NewReservationSweeper(inventoryService, postgres.NewLocker(m.Pool)).Run(ctx)
+	go yeahapi.NewOutboxRelay(postgres.NewOutboxService(m.Pool), postgres.NewLocker(m.Pool), cqrsService).Run(ctx)
 
 	m.Server.Addr = m.Config.HTTP.Addr
 
@@ -122,6 +191,23 @@ func (m *Main) Run(ctx context.Context) (err error) {
 	m.Server.CQRSService = cqrsService
 	m.Server.KVService = kvService
 	m.Server.CategoryService = categoryService
+	m.Server.ModerationService = moderationService
+	m.Server.NotificationService = notificationService
+	m.Server.SearchService = searchService
+	m.Server.PriceService = priceService
+	m.Server.CurrencyService = currencyService
+	m.Server.InventoryService = inventoryService
+	m.Server.MediaService = mediaService
+	m.Server.BlobStore = blobStore
+	m.Server.LocationService = locationService
+	m.Server.FavoriteService = favoriteService
+	m.Server.SavedSearchService = savedSearchService
+	m.Server.StatsService = statsService
+	m.Server.HitRecorder = hitAggregator
+	m.Server.ExpiryService = expiryService
+	m.Server.PromotionService = promotionService
+	m.Server.ReportService = reportService
+	m.Server.RecommendationService = inmem.NewSimilarCache(recommendationService)
 
 	return m.Server.Open()
 }
@@ -201,6 +287,29 @@ type Config struct {
 	Signing struct {
 		Key64 string `toml:"key64"`
 	} `toml:"signing"`
+
+	// Media configures where uploaded photos are kept: in Dir with the disk
+	// store or in Bucket with the s3 store. BaseURL is where they are
+	// downloaded from, e.g. https://api.needs.uz/blobs for the disk store.
+	Media struct {
+		Store    string `toml:"store"`
+		Dir      string `toml:"dir"`
+		Bucket   string `toml:"bucket"`
+		Endpoint string `toml:"endpoint"`
+		BaseURL  string `toml:"base-url"`
+	} `toml:"media"`
+
+	// Reports configures how many users have to report an active listing
+	// before it goes back to moderation.
+	Reports struct {
+		Threshold int `toml:"threshold"`
+	} `toml:"reports"`
+
+	// Similar turns on the co-view signal of similar listings, which is worth
+	// it once listings get enough views.
+	Similar struct {
+		CoViews bool `toml:"co-views"`
+	} `toml:"similar"`
 }
 
 func ReadConfigFile(filename string) (*Config, error) {
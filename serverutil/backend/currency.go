@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+func (s *Server) registerCurrencyRoutes() {
+	s.mux.Handle("/currencies.getCurrencies", get(s.clientOnly(s.handleGetCurrencies())))
+	s.mux.Handle("/currencies.getExchangeRate", post(s.clientOnly(s.handleGetExchangeRate())))
+}
+
+func (s *Server) handleGetCurrencies() Handler {
+	const op yeahapi.Op = "http/currencies.handleGetCurrencies"
+	type response struct {
+		T          string                 `json:"_"`
+		Currencies []yeahapi.CurrencyInfo `json:"currencies"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		currencies, err := s.CurrencyService.Currencies(ctx)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"currencies.currencies", currencies})
+	}
+}
+
+func (s *Server) handleGetExchangeRate() Handler {
+	const op yeahapi.Op = "http/currencies.handleGetExchangeRate"
+	type request struct {
+		Base  yeahapi.Currency `json:"base"`
+		Quote yeahapi.Currency `json:"quote"`
+	}
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.ExchangeRate
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		rate, err := s.CurrencyService.ExchangeRate(ctx, req.Base, req.Quote)
+		if err != nil {
+			if yeahapi.EIs(yeahapi.ENotFound, err) {
+				return yeahapi.E(op, err, fmt.Sprintf("No exchange rate from %s to %s", req.Base, req.Quote))
+			}
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"currencies.exchangeRate", rate})
+	}
+}
+
+// displayPrices sets display prices of skus converted into currency. Skus
+// without a rate to convert with are left without one.
+func (s *Server) displayPrices(ctx context.Context, skus []yeahapi.ListingSku, currency yeahapi.Currency) error {
+	const op yeahapi.Op = "http/currencies.displayPrices"
+	if currency == "" || len(skus) == 0 {
+		return nil
+	}
+
+	currencies, err := s.CurrencyService.Currencies(ctx)
+	if err != nil {
+		return yeahapi.E(op, err)
+	}
+
+	known := make(map[yeahapi.Currency]*yeahapi.CurrencyInfo, len(currencies))
+	for i := range currencies {
+		known[currencies[i].Code] = &currencies[i]
+	}
+
+	to, ok := known[currency]
+	if !ok {
+		return yeahapi.E(op, yeahapi.EInvalid, fmt.Sprintf("Unknown currency: %s", currency))
+	}
+
+	rates := make(map[yeahapi.Currency]*yeahapi.ExchangeRate)
+	for i := range skus {
+		from, ok := known[skus[i].PriceCurrency]
+		if !ok {
+			continue
+		}
+
+		rate, ok := rates[from.Code]
+		if !ok {
+			if rate, err = s.CurrencyService.ExchangeRate(ctx, from.Code, to.Code); err != nil && !yeahapi.EIs(yeahapi.ENotFound, err) {
+				return yeahapi.E(op, err)
+			}
+			rates[from.Code] = rate
+		}
+
+		if rate == nil {
+			continue
+		}
+
+		amount, err := yeahapi.ConvertAmount(skus[i].Price, rate, from, to)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		skus[i].DisplayPrice = &yeahapi.ListingPrice{Amount: amount, Currency: to.Code}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+func (s *Server) registerFavoriteRoutes() {
+	s.mux.Handle("/favorites.add", post(s.userOnly(s.handleAddFavorite())))
+	s.mux.Handle("/favorites.remove", post(s.userOnly(s.handleRemoveFavorite())))
+	s.mux.Handle("/favorites.getFavorites", post(s.userOnly(s.handleGetFavorites())))
+}
+
+func (s *Server) handleAddFavorite() Handler {
+	const op yeahapi.Op = "http/favorites.handleAddFavorite"
+	type request struct {
+		ListingID uuid.UUID `json:"listing_id"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		if err := s.FavoriteService.AddFavorite(ctx, session.UserID, req.ListingID); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, nil)
+	}
+}
+
+func (s *Server) handleRemoveFavorite() Handler {
+	const op yeahapi.Op = "http/favorites.handleRemoveFavorite"
+	type request struct {
+		ListingID uuid.UUID `json:"listing_id"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		if err := s.FavoriteService.RemoveFavorite(ctx, session.UserID, req.ListingID); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, nil)
+	}
+}
+
+func (s *Server) handleGetFavorites() Handler {
+	const op yeahapi.Op = "http/favorites.handleGetFavorites"
+	type request struct {
+		Cursor uuid.UUID `json:"cursor"`
+		Limit  int       `json:"limit"`
+	}
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.FavoritePage
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		page, err := s.FavoriteService.Favorites(ctx, yeahapi.FavoriteFilter{
+			UserID: session.UserID,
+			Lang:   lang(r),
+			After:  req.Cursor,
+			Limit:  pageLimit(req.Limit),
+		})
+
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"favorites.favorites", page})
+	}
+}
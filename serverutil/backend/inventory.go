@@ -0,0 +1,217 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+func (s *Server) registerInventoryRoutes() {
+	s.mux.Handle("/listings.adjustStock", post(s.userOnly(s.handleAdjustStock())))
+	s.mux.Handle("/listings.getStockAdjustments", post(s.userOnly(s.handleGetStockAdjustments())))
+	s.mux.Handle("/listings.reserveStock", post(s.userOnly(s.handleReserveStock())))
+	s.mux.Handle("/listings.releaseStock", post(s.userOnly(s.handleReleaseStock())))
+}
+
+type adjustStockData struct {
+	SkuID  uuid.UUID           `json:"sku_id"`
+	Delta  int                 `json:"delta"`
+	Reason yeahapi.StockReason `json:"reason"`
+	Note   string              `json:"note"`
+}
+
+func (d adjustStockData) Ok() error {
+	if d.SkuID.IsNil() {
+		return yeahapi.E(yeahapi.EInvalid, "SKU id is required")
+	}
+	if d.Delta == 0 {
+		return yeahapi.E(yeahapi.EInvalid, "Stock change is required")
+	}
+	if d.Reason != yeahapi.StockReasonRestock && d.Reason != yeahapi.StockReasonCorrection {
+		return yeahapi.E(yeahapi.EInvalid, "Reason must be either RESTOCK or CORRECTION")
+	}
+	return nil
+}
+
+func (s *Server) handleAdjustStock() Handler {
+	const op yeahapi.Op = "http/listings.handleAdjustStock"
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.StockAdjustment
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req adjustStockData
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		if _, err := s.ownSku(ctx, req.SkuID); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		adjustment, err := s.InventoryService.AdjustStock(ctx, req.SkuID, req.Delta, req.Reason, req.Note)
+		if err != nil {
+			return yeahapi.E(op, stockError(err, "Couldn't adjust stock. Please, try again"))
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.stockAdjustment", adjustment})
+	}
+}
+
+func (s *Server) handleGetStockAdjustments() Handler {
+	const op yeahapi.Op = "http/listings.handleGetStockAdjustments"
+	type request struct {
+		SkuID uuid.UUID `json:"sku_id"`
+	}
+	type response struct {
+		T           string                    `json:"_"`
+		Adjustments []yeahapi.StockAdjustment `json:"adjustments"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		if _, err := s.ownSku(ctx, req.SkuID); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		adjustments, err := s.InventoryService.StockAdjustments(ctx, req.SkuID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.stockAdjustments", adjustments})
+	}
+}
+
+type reserveStockData struct {
+	SkuID    uuid.UUID `json:"sku_id"`
+	Quantity int       `json:"quantity"`
+}
+
+func (d reserveStockData) Ok() error {
+	if d.SkuID.IsNil() {
+		return yeahapi.E(yeahapi.EInvalid, "SKU id is required")
+	}
+	if d.Quantity <= 0 {
+		return yeahapi.E(yeahapi.EInvalid, "Quantity must be positive")
+	}
+	return nil
+}
+
+func (s *Server) handleReserveStock() Handler {
+	const op yeahapi.Op = "http/listings.handleReserveStock"
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.StockReservation
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req reserveStockData
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		reservation, _, err := s.InventoryService.Reserve(ctx, req.SkuID, session.UserID, req.Quantity)
+		if err != nil {
+			if yeahapi.EIs(yeahapi.ENotFound, err) {
+				return yeahapi.E(op, err, fmt.Sprintf("SKU with id %s not found", req.SkuID))
+			}
+			return yeahapi.E(op, stockError(err, "Couldn't reserve stock. Please, try again"))
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.stockReservation", reservation})
+	}
+}
+
+func (s *Server) handleReleaseStock() Handler {
+	const op yeahapi.Op = "http/listings.handleReleaseStock"
+	type request struct {
+		ReservationID uuid.UUID `json:"reservation_id"`
+	}
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.StockReservation
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		reservation, err := s.InventoryService.Reservation(ctx, req.ReservationID)
+		if err != nil {
+			if yeahapi.EIs(yeahapi.ENotFound, err) {
+				return yeahapi.E(op, err, fmt.Sprintf("Reservation with id %s not found", req.ReservationID))
+			}
+			return yeahapi.E(op, err, "Something went wrong on our end. Please, try again later")
+		}
+
+		// Either the buyer or the seller may release a reservation.
+		session := yeahapi.SessionFromContext(ctx)
+		if reservation.UserID != session.UserID {
+			if _, err := s.ownSku(ctx, reservation.SkuID); err != nil {
+				return yeahapi.E(op, err)
+			}
+		}
+
+		reservation, _, err = s.InventoryService.Release(ctx, reservation.ID)
+		if err != nil {
+			return yeahapi.E(op, stockError(err, "Couldn't release stock. Please, try again"))
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.stockReservation", reservation})
+	}
+}
+
+// ownSku fetches the sku and makes sure its listing belongs to the session
+// user.
+func (s *Server) ownSku(ctx context.Context, id uuid.UUID) (*yeahapi.ListingSku, error) {
+	const op yeahapi.Op = "http/listings.ownSku"
+	sku, err := s.ListingService.Sku(ctx, id)
+	if err != nil {
+		if yeahapi.EIs(yeahapi.ENotFound, err) {
+			return nil, yeahapi.E(op, err, fmt.Sprintf("SKU with id %s not found", id))
+		}
+		return nil, yeahapi.E(op, err, "Something went wrong on our end. Please, try again later")
+	}
+
+	if _, err := s.ownListing(ctx, sku.ListingID); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return sku, nil
+}
+
+// stockError keeps the message of errors the user can act on, like running
+// out of stock, and replaces it with message otherwise.
+func stockError(err error, message string) error {
+	switch yeahapi.ErrorKind(err) {
+	case yeahapi.EInvalid, yeahapi.EConflict, yeahapi.ENotFound, yeahapi.EPermission:
+		return err
+	}
+	return yeahapi.E(err, message)
+}
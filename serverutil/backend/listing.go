@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -14,16 +15,35 @@ import (
 func (s *Server) registerListingRoutes() {
 	s.mux.Handle("/listings.createListing", post(s.userOnly(s.handleCreateListing())))
 	s.mux.Handle("/listings.getListing", post(s.userOnly(s.handleGetListing())))
+	s.mux.Handle("/listings.getMyListings", post(s.userOnly(s.handleGetMyListings())))
+	s.mux.Handle("/listings.getUserListings", post(s.clientOnly(s.handleGetUserListings())))
+	s.mux.Handle("/listings.getSimilar", post(s.clientOnly(s.handleGetSimilarListings())))
+	s.mux.Handle("/listings.editListing", post(s.userOnly(s.handleEditListing())))
 	s.mux.Handle("/listings.deleteListing", post(s.userOnly(s.handleDeleteListing())))
+	s.mux.Handle("/listings.submitForModeration", post(s.userOnly(s.handleListingTransition(yeahapi.ListingStatusModeration))))
+	s.mux.Handle("/listings.publish", post(s.userOnly(s.handleListingTransition(yeahapi.ListingStatusActive))))
+	s.mux.Handle("/listings.archive", post(s.userOnly(s.handleListingTransition(yeahapi.ListingStatusArchived))))
+	s.mux.Handle("/listings.renew", post(s.userOnly(s.handleRenewListing())))
 	s.mux.Handle("/listings.createSku", post(s.userOnly(s.handleCreateSku())))
+	s.mux.Handle("/listings.generateVariations", post(s.userOnly(s.handleGenerateVariations())))
+	s.mux.Handle("/listings.editSku", post(s.userOnly(s.handleEditSku())))
 	s.mux.Handle("/listings.deleteSku", post(s.userOnly(s.handleDeleteSku())))
 	s.mux.Handle("/listings.getSkus", post(s.userOnly(s.handleGetSkus())))
 	s.mux.Handle("/listings.getSku", post(s.userOnly(s.handleGetSku())))
+	s.mux.Handle("/listings.setTranslation", post(s.userOnly(s.handleSetTranslation())))
+	s.mux.Handle("/listings.deleteTranslation", post(s.userOnly(s.handleDeleteTranslation())))
+	s.mux.Handle("/listings.getTranslations", post(s.userOnly(s.handleGetTranslations())))
 }
 
 type createListingData struct {
-	Title      string `json:"title"`
-	CategoryID int    `json:"category_id"`
+	Title       string                   `json:"title"`
+	Description string                   `json:"description"`
+	Condition   yeahapi.ListingCondition `json:"condition"`
+	Brand       string                   `json:"brand"`
+	Lang        string                   `json:"lang"`
+	LocationID  int                      `json:"location_id"`
+	Point       *yeahapi.Point           `json:"point"`
+	CategoryID  int                      `json:"category_id"`
 }
 
 func (d createListingData) Ok() error {
@@ -33,7 +53,10 @@ func (d createListingData) Ok() error {
 	if d.CategoryID == 0 {
 		return yeahapi.E(yeahapi.EInvalid, "Category is required")
 	}
-	return nil
+	if err := d.Condition.Ok(); err != nil {
+		return err
+	}
+	return yeahapi.ListingLocation{LocationID: d.LocationID, Point: d.Point}.Ok()
 }
 
 func (s *Server) handleCreateListing() Handler {
@@ -54,12 +77,22 @@ func (s *Server) handleCreateListing() Handler {
 
 		session := yeahapi.SessionFromContext(ctx)
 		listing, err := s.ListingService.CreateListing(ctx, &yeahapi.Listing{
-			CategoryID: req.CategoryID,
-			Title:      req.Title,
-			OwnerID:    session.UserID,
+			CategoryID:  req.CategoryID,
+			Title:       req.Title,
+			Description: req.Description,
+			Condition:   req.Condition,
+			Brand:       req.Brand,
+			Lang:        req.Lang,
+			LocationID:  req.LocationID,
+			Point:       req.Point,
+			OwnerID:     session.UserID,
+			Status:      yeahapi.ListingStatusDraft,
 		})
 
 		if err != nil {
+			if yeahapi.EIs(yeahapi.EInvalid, err) {
+				return yeahapi.E(op, err)
+			}
 			return yeahapi.E(op, err, "Couldn't create listing. Please, try again")
 		}
 
@@ -86,15 +119,291 @@ func (s *Server) handleGetListing() Handler {
 		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
 		defer cancel()
 
-		listing, err := s.ListingService.Listing(ctx, req.ID)
+		listing, err := s.ListingService.LocalizedListing(ctx, req.ID, lang(r))
 		if err != nil {
 			return yeahapi.E(op, err)
 		}
 
+		s.recordHits(r, yeahapi.HitView, *listing)
 		return JSON(w, r, http.StatusOK, response{"listings.listing", listing})
 	}
 }
 
+type editListingData struct {
+	ListingID   uuid.UUID                `json:"listing_id"`
+	UpdatedAt   time.Time                `json:"updated_at"`
+	UpdateMask  []string                 `json:"update_mask"`
+	Title       string                   `json:"title"`
+	Description string                   `json:"description"`
+	Condition   yeahapi.ListingCondition `json:"condition"`
+	Brand       string                   `json:"brand"`
+	Lang        string                   `json:"lang"`
+	LocationID  int                      `json:"location_id"`
+	Point       *yeahapi.Point           `json:"point"`
+	CategoryID  int                      `json:"category_id"`
+}
+
+func (d editListingData) Ok() error {
+	if d.ListingID.IsNil() {
+		return yeahapi.E(yeahapi.EInvalid, "Listing id is required")
+	}
+	if d.UpdatedAt.IsZero() {
+		return yeahapi.E(yeahapi.EInvalid, "Updated at is required")
+	}
+	if len(d.UpdateMask) == 0 {
+		return yeahapi.E(yeahapi.EInvalid, "Update mask is required")
+	}
+	for _, path := range d.UpdateMask {
+		switch path {
+		case "title", "description", "condition", "brand", "lang", "location", "category_id":
+		default:
+			return yeahapi.E(yeahapi.EInvalid, fmt.Sprintf("Unknown update mask path: %s", path))
+		}
+	}
+	return nil
+}
+
+func (d editListingData) update() yeahapi.ListingUpdate {
+	upd := yeahapi.ListingUpdate{UpdatedAt: d.UpdatedAt}
+	for _, path := range d.UpdateMask {
+		switch path {
+		case "title":
+			upd.Title = &d.Title
+		case "description":
+			upd.Description = &d.Description
+		case "condition":
+			upd.Condition = &d.Condition
+		case "brand":
+			upd.Brand = &d.Brand
+		case "lang":
+			upd.Lang = &d.Lang
+		case "location":
+			upd.Location = &yeahapi.ListingLocation{LocationID: d.LocationID, Point: d.Point}
+		case "category_id":
+			upd.CategoryID = &d.CategoryID
+		}
+	}
+	return upd
+}
+
+func (s *Server) handleEditListing() Handler {
+	const op yeahapi.Op = "http/listings.handleEditListing"
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.Listing
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req editListingData
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		listing, err := s.ownListing(ctx, req.ListingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		// Approved listings are reviewed again after edits, so they can't be
+		// changed into something else once live.
+		upd := req.update()
+		upd.Resubmit = true
+		listing, err = s.ListingService.UpdateListing(ctx, req.ListingID, upd)
+		if err != nil {
+			if yeahapi.EIs(yeahapi.EConflict, err) {
+				return yeahapi.E(op, err, "Listing has been modified since you loaded it. Please, reload and try again")
+			}
+			if yeahapi.EIs(yeahapi.EInvalid, err) {
+				return yeahapi.E(op, err)
+			}
+			return yeahapi.E(op, err, "Couldn't update listing. Please, try again")
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.listing", listing})
+	}
+}
+
+// ownListing fetches the listing and makes sure it belongs to the session user.
+func (s *Server) ownListing(ctx context.Context, id uuid.UUID) (*yeahapi.Listing, error) {
+	const op yeahapi.Op = "http/listings.ownListing"
+	listing, err := s.ListingService.Listing(ctx, id)
+	if err != nil {
+		if yeahapi.EIs(yeahapi.ENotFound, err) {
+			return nil, yeahapi.E(op, err, fmt.Sprintf("Listing with id %s not found", id))
+		}
+		return nil, yeahapi.E(op, err, "Something went wrong on our end. Please, try again later")
+	}
+
+	session := yeahapi.SessionFromContext(ctx)
+	if listing.OwnerID != session.UserID {
+		return nil, yeahapi.E(op, yeahapi.EPermission, "You don't have access to this listing")
+	}
+
+	return listing, nil
+}
+
+type listingsData struct {
+	Statuses   []yeahapi.ListingStatus `json:"statuses"`
+	CategoryID int                     `json:"category_id"`
+	Cursor     uuid.UUID               `json:"cursor"`
+	Limit      int                     `json:"limit"`
+}
+
+func (d listingsData) Ok() error {
+	for _, status := range d.Statuses {
+		if _, ok := listingStatuses[status]; !ok {
+			return yeahapi.E(yeahapi.EInvalid, fmt.Sprintf("Unknown listing status: %s", status))
+		}
+	}
+	return nil
+}
+
+var listingStatuses = map[yeahapi.ListingStatus]struct{}{
+	yeahapi.ListingStatusActive:     {},
+	yeahapi.ListingStatusModeration: {},
+	yeahapi.ListingStatusIndexing:   {},
+	yeahapi.ListingStatusArchived:   {},
+	yeahapi.ListingStatusDraft:      {},
+	yeahapi.ListingStatusDeleted:    {},
+}
+
+func (s *Server) handleGetMyListings() Handler {
+	const op yeahapi.Op = "http/listings.handleGetMyListings"
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.ListingPage
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req listingsData
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		statuses := req.Statuses
+		if len(statuses) == 0 {
+			statuses = []yeahapi.ListingStatus{
+				yeahapi.ListingStatusActive,
+				yeahapi.ListingStatusModeration,
+				yeahapi.ListingStatusIndexing,
+				yeahapi.ListingStatusArchived,
+				yeahapi.ListingStatusDraft,
+			}
+		}
+
+		session := yeahapi.SessionFromContext(ctx)
+		page, err := s.ListingService.Listings(ctx, yeahapi.ListingFilter{
+			OwnerID:    session.UserID,
+			Statuses:   statuses,
+			CategoryID: req.CategoryID,
+			After:      req.Cursor,
+			Limit:      pageLimit(req.Limit),
+		})
+
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.listings", page})
+	}
+}
+
+func (s *Server) handleGetUserListings() Handler {
+	const op yeahapi.Op = "http/listings.handleGetUserListings"
+	type request struct {
+		UserID     yeahapi.UserID `json:"user_id"`
+		CategoryID int            `json:"category_id"`
+		Cursor     uuid.UUID      `json:"cursor"`
+		Limit      int            `json:"limit"`
+	}
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.ListingPage
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		if req.UserID.IsNil() {
+			return yeahapi.E(op, yeahapi.EInvalid, "User id is required")
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		page, err := s.ListingService.Listings(ctx, yeahapi.ListingFilter{
+			OwnerID:    req.UserID,
+			Statuses:   []yeahapi.ListingStatus{yeahapi.ListingStatusActive},
+			CategoryID: req.CategoryID,
+			Lang:       lang(r),
+			After:      req.Cursor,
+			Limit:      pageLimit(req.Limit),
+		})
+
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		s.recordHits(r, yeahapi.HitImpression, page.Listings...)
+		return JSON(w, r, http.StatusOK, response{"listings.listings", page})
+	}
+}
+
+// handleGetSimilarListings recommends listings similar to the one being
+// viewed. They count as impressions.
+func (s *Server) handleGetSimilarListings() Handler {
+	const op yeahapi.Op = "http/listings.handleGetSimilarListings"
+	type request struct {
+		ListingID uuid.UUID `json:"listing_id"`
+		Limit     int       `json:"limit"`
+	}
+	type response struct {
+		T        string                   `json:"_"`
+		Listings []yeahapi.SimilarListing `json:"listings"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		if req.ListingID.IsNil() {
+			return yeahapi.E(op, yeahapi.EInvalid, "Listing id is required")
+		}
+
+		limit := pageLimit(req.Limit)
+		if limit > yeahapi.MaxSimilarListings {
+			limit = yeahapi.MaxSimilarListings
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		similar, err := s.RecommendationService.SimilarListings(ctx, req.ListingID, lang(r), limit)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		listings := make([]yeahapi.Listing, len(similar))
+		for i := range similar {
+			listings[i] = similar[i].Listing
+		}
+		s.recordHits(r, yeahapi.HitImpression, listings...)
+
+		return JSON(w, r, http.StatusOK, response{"listings.similar", similar})
+	}
+}
+
 func (s *Server) handleDeleteListing() Handler {
 	const op yeahapi.Op = "http/listings.handleDeleteListing"
 	type request struct {
@@ -110,7 +419,12 @@ func (s *Server) handleDeleteListing() Handler {
 		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
 		defer cancel()
 
-		if err := s.ListingService.DeleteListing(ctx, req.ID); err != nil {
+		listing, err := s.ownListing(ctx, req.ID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		if _, err := s.moveListing(ctx, listing, yeahapi.ListingStatusDeleted); err != nil {
 			return yeahapi.E(op, err)
 		}
 
@@ -118,21 +432,140 @@ func (s *Server) handleDeleteListing() Handler {
 	}
 }
 
+func (s *Server) handleListingTransition(to yeahapi.ListingStatus) Handler {
+	const op yeahapi.Op = "http/listings.handleListingTransition"
+	type request struct {
+		ID uuid.UUID `json:"listing_id"`
+	}
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.Listing
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		listing, err := s.ownListing(ctx, req.ID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		listing, err = s.moveListing(ctx, listing, to)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.listing", listing})
+	}
+}
+
+// handleRenewListing extends an active listing, or publishes an archived one
+// again, which starts its lifetime over as well.
+func (s *Server) handleRenewListing() Handler {
+	const op yeahapi.Op = "http/listings.handleRenewListing"
+	type request struct {
+		ID uuid.UUID `json:"listing_id"`
+	}
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.Listing
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		listing, err := s.ownListing(ctx, req.ID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		switch listing.Status {
+		case yeahapi.ListingStatusActive:
+			listing, err = s.ExpiryService.RenewListing(ctx, listing.ID)
+		case yeahapi.ListingStatusArchived:
+			if _, err = s.moveListing(ctx, listing, yeahapi.ListingStatusActive); err == nil {
+				listing, err = s.ListingService.Listing(ctx, listing.ID)
+			}
+		default:
+			err = yeahapi.E(yeahapi.EInvalid, "Only active and archived listings can be renewed")
+		}
+
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.listing", listing})
+	}
+}
+
+// moveListing moves the listing to the given status. Downstream consumers
+// learn about the transition from the event stored along with it.
+func (s *Server) moveListing(ctx context.Context, listing *yeahapi.Listing, to yeahapi.ListingStatus) (*yeahapi.Listing, error) {
+	const op yeahapi.Op = "http/listings.moveListing"
+	from := listing.Status
+	listing, err := s.ListingService.UpdateStatus(ctx, listing.ID, from, to)
+	if err != nil {
+		if yeahapi.EIs(yeahapi.EInvalid, err) {
+			return nil, yeahapi.E(op, err)
+		}
+		if yeahapi.EIs(yeahapi.EConflict, err) {
+			return nil, yeahapi.E(op, err, "Listing status has changed since you loaded it. Please, reload and try again")
+		}
+		return nil, yeahapi.E(op, err, "Couldn't update listing status. Please, try again")
+	}
+
+	return listing, nil
+}
+
 type createSkuData struct {
 	ListingID uuid.UUID            `json:"listing_id"`
 	UnitPrice int                  `json:"unit_price"`
 	Currency  yeahapi.Currency     `json:"currency"`
 	CustomSku string               `json:"custom_sku"`
 	Attrs     yeahapi.ListingAttrs `json:"attrs"`
+	Quantity  *int                 `json:"quantity"`
 }
 
 func (d createSkuData) Ok() error {
-	if d.Currency == "" {
-		return yeahapi.E(yeahapi.EInvalid, "Currency is required")
+	if err := quantityOk(d.Quantity); err != nil {
+		return err
 	}
+	return currencyOk(d.Currency)
+}
+
+// quantity returns the stock a new sku starts with. Skus are single items
+// unless told otherwise.
+func quantity(q *int) int {
+	if q == nil {
+		return 1
+	}
+	return *q
+}
 
-	if d.Currency != yeahapi.CurrencyUSD {
-		return yeahapi.E(yeahapi.EInvalid, "Invalid currency. Use USD instead")
+func quantityOk(q *int) error {
+	if q != nil && *q < 0 {
+		return yeahapi.E(yeahapi.EInvalid, "Quantity can't be negative")
+	}
+	return nil
+}
+
+// currencyOk only checks a currency is given. Whether we know it is up to the
+// database.
+func currencyOk(currency yeahapi.Currency) error {
+	if currency == "" {
+		return yeahapi.E(yeahapi.EInvalid, "Currency is required")
 	}
 	return nil
 }
@@ -153,12 +586,23 @@ func (s *Server) handleCreateSku() Handler {
 		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
 		defer cancel()
 
+		listing, err := s.ownListing(ctx, req.ListingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		attrs, err := s.validAttrs(ctx, lang(r), listing.CategoryID, req.Attrs)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
 		sku, err := s.ListingService.CreateSku(ctx, &yeahapi.ListingSku{
 			ListingID:     req.ListingID,
 			Price:         req.UnitPrice,
 			PriceCurrency: req.Currency,
 			CustomSku:     req.CustomSku,
-			Attrs:         req.Attrs,
+			Attrs:         attrs,
+			Quantity:      quantity(req.Quantity),
 		})
 
 		if err != nil {
@@ -169,6 +613,246 @@ func (s *Server) handleCreateSku() Handler {
 	}
 }
 
+type variationData struct {
+	Attrs     yeahapi.ListingAttrs `json:"attrs"`
+	UnitPrice *int                 `json:"unit_price"`
+	CustomSku string               `json:"custom_sku"`
+	Quantity  *int                 `json:"quantity"`
+}
+
+type generateVariationsData struct {
+	ListingID  uuid.UUID                `json:"listing_id"`
+	Options    map[string][]interface{} `json:"options"`
+	Attrs      yeahapi.ListingAttrs     `json:"attrs"`
+	UnitPrice  int                      `json:"unit_price"`
+	Currency   yeahapi.Currency         `json:"currency"`
+	Quantity   *int                     `json:"quantity"`
+	Variations []variationData          `json:"variations"`
+}
+
+func (d generateVariationsData) Ok() error {
+	if d.ListingID.IsNil() {
+		return yeahapi.E(yeahapi.EInvalid, "Listing id is required")
+	}
+	if err := quantityOk(d.Quantity); err != nil {
+		return err
+	}
+	for _, v := range d.Variations {
+		if err := quantityOk(v.Quantity); err != nil {
+			return err
+		}
+	}
+	return currencyOk(d.Currency)
+}
+
+// skus turns the option matrix into skus. Variations given in the request
+// override the price, custom sku and quantity of the matching combination.
+func (d generateVariationsData) skus(schema []*yeahapi.CategoryAttribute) ([]string, []yeahapi.ListingSku, error) {
+	combinations, err := yeahapi.Variations(schema, d.Attrs, d.Options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := yeahapi.VariationKeys(d.Options)
+	overrides := make(map[string]variationData, len(d.Variations))
+	for _, v := range d.Variations {
+		options := make(map[string][]interface{}, len(v.Attrs))
+		for key, value := range v.Attrs {
+			options[key] = []interface{}{value}
+		}
+
+		attrs, err := yeahapi.Variations(schema, d.Attrs, options)
+		if err != nil {
+			return nil, nil, err
+		}
+		overrides[yeahapi.VariationKey(attrs[0], keys)] = v
+	}
+
+	skus := make([]yeahapi.ListingSku, len(combinations))
+	for i, attrs := range combinations {
+		sku := yeahapi.ListingSku{
+			Attrs:         attrs,
+			Price:         d.UnitPrice,
+			PriceCurrency: d.Currency,
+			CustomSku:     yeahapi.VariationSku(attrs, keys),
+			Quantity:      quantity(d.Quantity),
+		}
+
+		if v, ok := overrides[yeahapi.VariationKey(attrs, keys)]; ok {
+			if v.UnitPrice != nil {
+				sku.Price = *v.UnitPrice
+			}
+			if v.CustomSku != "" {
+				sku.CustomSku = v.CustomSku
+			}
+			if v.Quantity != nil {
+				sku.Quantity = *v.Quantity
+			}
+		}
+
+		skus[i] = sku
+	}
+
+	return keys, skus, nil
+}
+
+func (s *Server) handleGenerateVariations() Handler {
+	const op yeahapi.Op = "http/listings.handleGenerateVariations"
+	type response struct {
+		T    string               `json:"_"`
+		Skus []yeahapi.ListingSku `json:"skus"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req generateVariationsData
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		listing, err := s.ownListing(ctx, req.ListingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		schema, err := s.CategoryService.Attributes(ctx, strconv.Itoa(listing.CategoryID), lang(r))
+		if err != nil {
+			return yeahapi.E(op, err, "Something went wrong on our end. Please, try again later")
+		}
+
+		keys, skus, err := req.skus(schema)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		created, err := s.ListingService.CreateVariations(ctx, listing.ID, keys, skus)
+		if err != nil {
+			return yeahapi.E(op, err, "Couldn't create variations. Please, try again")
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.skus", created})
+	}
+}
+
+// validAttrs validates sku attrs against the attributes of the category,
+// naming attributes in lang in field errors.
+func (s *Server) validAttrs(ctx context.Context, lang string, categoryID int, attrs yeahapi.ListingAttrs) (yeahapi.ListingAttrs, error) {
+	const op yeahapi.Op = "http/listings.validAttrs"
+	schema, err := s.CategoryService.Attributes(ctx, strconv.Itoa(categoryID), lang)
+	if err != nil {
+		return nil, yeahapi.E(op, err, "Something went wrong on our end. Please, try again later")
+	}
+
+	attrs, err = yeahapi.ValidateAttrs(attrs, schema)
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return attrs, nil
+}
+
+type editSkuData struct {
+	SkuID      uuid.UUID            `json:"sku_id"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+	UpdateMask []string             `json:"update_mask"`
+	UnitPrice  int                  `json:"unit_price"`
+	Currency   yeahapi.Currency     `json:"currency"`
+	CustomSku  string               `json:"custom_sku"`
+	Attrs      yeahapi.ListingAttrs `json:"attrs"`
+}
+
+func (d editSkuData) Ok() error {
+	if d.SkuID.IsNil() {
+		return yeahapi.E(yeahapi.EInvalid, "SKU id is required")
+	}
+	if d.UpdatedAt.IsZero() {
+		return yeahapi.E(yeahapi.EInvalid, "Updated at is required")
+	}
+	if len(d.UpdateMask) == 0 {
+		return yeahapi.E(yeahapi.EInvalid, "Update mask is required")
+	}
+	for _, path := range d.UpdateMask {
+		switch path {
+		case "custom_sku", "attrs":
+		case "price":
+			if err := currencyOk(d.Currency); err != nil {
+				return err
+			}
+		default:
+			return yeahapi.E(yeahapi.EInvalid, fmt.Sprintf("Unknown update mask path: %s", path))
+		}
+	}
+	return nil
+}
+
+func (d editSkuData) update() yeahapi.ListingSkuUpdate {
+	upd := yeahapi.ListingSkuUpdate{UpdatedAt: d.UpdatedAt}
+	for _, path := range d.UpdateMask {
+		switch path {
+		case "custom_sku":
+			upd.CustomSku = &d.CustomSku
+		case "attrs":
+			upd.Attrs = &d.Attrs
+		case "price":
+			upd.Price = &d.UnitPrice
+			upd.PriceCurrency = &d.Currency
+		}
+	}
+	return upd
+}
+
+func (s *Server) handleEditSku() Handler {
+	const op yeahapi.Op = "http/listings.handleEditSku"
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.ListingSku
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req editSkuData
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		sku, err := s.ListingService.Sku(ctx, req.SkuID)
+		if err != nil {
+			if yeahapi.EIs(yeahapi.ENotFound, err) {
+				return yeahapi.E(op, err, fmt.Sprintf("SKU with id %s not found", req.SkuID))
+			}
+			return yeahapi.E(op, err, "Something went wrong on our end. Please, try again later")
+		}
+
+		listing, err := s.ownListing(ctx, sku.ListingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		upd := req.update()
+		if upd.Attrs != nil {
+			attrs, err := s.validAttrs(ctx, lang(r), listing.CategoryID, *upd.Attrs)
+			if err != nil {
+				return yeahapi.E(op, err)
+			}
+			upd.Attrs = &attrs
+		}
+
+		sku, err = s.ListingService.UpdateSku(ctx, req.SkuID, upd)
+		if err != nil {
+			if yeahapi.EIs(yeahapi.EConflict, err) {
+				return yeahapi.E(op, err, "SKU has been modified since you loaded it. Please, reload and try again")
+			}
+			return yeahapi.E(op, err, "Couldn't update SKU. Please, try again")
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.sku", sku})
+	}
+}
+
 func (s *Server) handleDeleteSku() Handler {
 	const op yeahapi.Op = "http/listings.handleDeleteSku"
 	type request struct {
@@ -196,7 +880,8 @@ func (s *Server) handleDeleteSku() Handler {
 func (s *Server) handleGetSkus() Handler {
 	const op yeahapi.Op = "http/listings.handleGetSkus"
 	type request struct {
-		ID uuid.UUID `json:"listing_id"`
+		ID       uuid.UUID        `json:"listing_id"`
+		Currency yeahapi.Currency `json:"currency"`
 	}
 	type response struct {
 		T    string               `json:"_"`
@@ -217,6 +902,10 @@ func (s *Server) handleGetSkus() Handler {
 			return yeahapi.E(op, err)
 		}
 
+		if err := s.displayPrices(ctx, skus, req.Currency); err != nil {
+			return yeahapi.E(op, err)
+		}
+
 		return JSON(w, r, http.StatusOK, response{"listing.skus", skus})
 	}
 }
@@ -224,7 +913,8 @@ func (s *Server) handleGetSkus() Handler {
 func (s *Server) handleGetSku() Handler {
 	const op yeahapi.Op = "http/listings.handleGetSku"
 	type request struct {
-		ID uuid.UUID `json:"sku_id"`
+		ID       uuid.UUID        `json:"sku_id"`
+		Currency yeahapi.Currency `json:"currency"`
 	}
 	type response struct {
 		T   string              `json:"_"`
@@ -249,6 +939,135 @@ func (s *Server) handleGetSku() Handler {
 			return yeahapi.E(op, err, "Something went wrong on our end. Please, try again later")
 		}
 
-		return JSON(w, r, http.StatusOK, response{"listings.sku", sku})
+		skus := []yeahapi.ListingSku{*sku}
+		if err := s.displayPrices(ctx, skus, req.Currency); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.sku", &skus[0]})
+	}
+}
+
+type setTranslationData struct {
+	ListingID   uuid.UUID `json:"listing_id"`
+	Lang        string    `json:"lang"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+}
+
+func (d setTranslationData) Ok() error {
+	if d.ListingID.IsNil() {
+		return yeahapi.E(yeahapi.EInvalid, "Listing id is required")
+	}
+	if d.Lang == "" {
+		return yeahapi.E(yeahapi.EInvalid, "Language is required")
+	}
+	if d.Title == "" {
+		return yeahapi.E(yeahapi.EInvalid, "Title is required")
+	}
+	return nil
+}
+
+func (s *Server) handleSetTranslation() Handler {
+	const op yeahapi.Op = "http/listings.handleSetTranslation"
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.ListingTranslation
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req setTranslationData
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		if _, err := s.ownListing(ctx, req.ListingID); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		tr, err := s.ListingService.SetTranslation(ctx, &yeahapi.ListingTranslation{
+			ListingID:   req.ListingID,
+			Lang:        req.Lang,
+			Title:       req.Title,
+			Description: req.Description,
+		})
+
+		if err != nil {
+			if yeahapi.EIs(yeahapi.EInvalid, err) {
+				return yeahapi.E(op, err)
+			}
+			return yeahapi.E(op, err, "Couldn't save translation. Please, try again")
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.translation", tr})
+	}
+}
+
+func (s *Server) handleDeleteTranslation() Handler {
+	const op yeahapi.Op = "http/listings.handleDeleteTranslation"
+	type request struct {
+		ListingID uuid.UUID `json:"listing_id"`
+		Lang      string    `json:"lang"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		if _, err := s.ownListing(ctx, req.ListingID); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		if err := s.ListingService.DeleteTranslation(ctx, req.ListingID, req.Lang); err != nil {
+			if yeahapi.EIs(yeahapi.ENotFound, err) {
+				return yeahapi.E(op, err, fmt.Sprintf("Listing has no %s translation", req.Lang))
+			}
+			if yeahapi.EIs(yeahapi.EInvalid, err) {
+				return yeahapi.E(op, err)
+			}
+			return yeahapi.E(op, err, "Couldn't delete translation. Please, try again")
+		}
+
+		return JSON(w, r, http.StatusOK, nil)
+	}
+}
+
+func (s *Server) handleGetTranslations() Handler {
+	const op yeahapi.Op = "http/listings.handleGetTranslations"
+	type request struct {
+		ListingID uuid.UUID `json:"listing_id"`
+	}
+	type response struct {
+		T            string                       `json:"_"`
+		Translations []yeahapi.ListingTranslation `json:"translations"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		if _, err := s.ownListing(ctx, req.ListingID); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		translations, err := s.ListingService.Translations(ctx, req.ListingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.translations", translations})
 	}
 }
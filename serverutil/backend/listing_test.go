@@ -0,0 +1,56 @@
+package backend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type listingService struct {
+	yeahapi.ListingService
+	listing *yeahapi.Listing
+	update  *yeahapi.ListingUpdate
+}
+
+func (s *listingService) Listing(ctx context.Context, id uuid.UUID) (*yeahapi.Listing, error) {
+	l := *s.listing
+	return &l, nil
+}
+
+func (s *listingService) UpdateListing(ctx context.Context, id uuid.UUID, update yeahapi.ListingUpdate) (*yeahapi.Listing, error) {
+	s.update = &update
+	if update.Title != nil {
+		s.listing.Title = *update.Title
+	}
+	l := *s.listing
+	return &l, nil
+}
+
+func TestServer_handleEditListing(t *testing.T) {
+	t.Run("Resubmit", func(t *testing.T) {
+		owner := yeahapi.UserID{UUID: uuid.Must(uuid.NewV7())}
+		listing := &yeahapi.Listing{ID: uuid.Must(uuid.NewV7()), OwnerID: owner, Title: "Bicycle", Status: yeahapi.ListingStatusActive}
+		ls := &listingService{listing: listing}
+		s := &Server{ListingService: ls}
+
+		body := `{"listing_id": "` + listing.ID.String() + `", "updated_at": "` + time.Now().Format(time.RFC3339) + `",
+			"update_mask": ["title"], "title": "Pistol"}`
+		r := httptest.NewRequest(http.MethodPost, "/listings.edit", strings.NewReader(body))
+		r = r.WithContext(yeahapi.NewContextWithSession(r.Context(), &yeahapi.Session{UserID: owner}))
+		if err := s.handleEditListing()(httptest.NewRecorder(), r); err != nil {
+			t.Fatal(err)
+		}
+
+		// Sending the listing back to moderation is up to the update itself,
+		// so that edits never go live without it.
+		if ls.update == nil || !ls.update.Resubmit || ls.listing.Title != "Pistol" {
+			t.Fatalf("unexpected update: %#v", ls.update)
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package backend
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+func (s *Server) registerLocationRoutes() {
+	s.mux.Handle("/locations.getLocations", get(s.clientOnly(s.handleGetLocations())))
+}
+
+func (s *Server) handleGetLocations() Handler {
+	const op yeahapi.Op = "http/location.handleGetLocations"
+	type response struct {
+		T         string             `json:"_"`
+		Locations []yeahapi.Location `json:"locations"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		locations, err := s.LocationService.Locations(ctx, lang(r))
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"locations.locations", locations})
+	}
+}
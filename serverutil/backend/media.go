@@ -0,0 +1,361 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+// maxUploadSize caps a whole upload request, multipart overhead included.
+const maxUploadSize = yeahapi.MaxListingMedia*yeahapi.MaxMediaSize + 1<<20
+
+func (s *Server) registerMediaRoutes() {
+	s.mux.Handle("/media.upload", post(s.userOnly(s.handleUploadMedia())))
+	s.mux.Handle("/media.getListingMedia", post(s.clientOnly(s.handleGetListingMedia())))
+	s.mux.Handle("/media.reorder", post(s.userOnly(s.handleReorderMedia())))
+	s.mux.Handle("/media.setPrimary", post(s.userOnly(s.handleSetPrimaryMedia())))
+	s.mux.Handle("/media.delete", post(s.userOnly(s.handleDeleteMedia())))
+	s.mux.Handle("/blobs/", get(s.handleGetBlob()))
+}
+
+// handleUploadMedia takes a multipart form with a listing_id field and one or
+// more photos in files fields. All photos are checked before any is stored.
+func (s *Server) handleUploadMedia() Handler {
+	const op yeahapi.Op = "http/media.handleUploadMedia"
+	type response struct {
+		T     string          `json:"_"`
+		Media []yeahapi.Media `json:"media"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+		defer r.Body.Close()
+		if err := r.ParseMultipartForm(yeahapi.MaxMediaSize); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return yeahapi.E(op, yeahapi.ETooLarge, "Upload is too large")
+			}
+			return yeahapi.E(op, yeahapi.EInvalid, "Invalid multipart form")
+		}
+
+		defer r.MultipartForm.RemoveAll()
+
+		listingID, err := uuid.FromString(r.FormValue("listing_id"))
+		if err != nil {
+			return yeahapi.E(op, yeahapi.EInvalid, "Listing id is required")
+		}
+
+		files := r.MultipartForm.File["files"]
+		if len(files) == 0 {
+			return yeahapi.E(op, yeahapi.EInvalid, "At least one photo is required")
+		} else if len(files) > yeahapi.MaxListingMedia {
+			return yeahapi.E(op, yeahapi.EInvalid, fmt.Sprintf("At most %d photos can be uploaded at once", yeahapi.MaxListingMedia))
+		}
+
+		photos := make([][]byte, len(files))
+		types := make([]string, len(files))
+		for i, file := range files {
+			if photos[i], types[i], err = readPhoto(file); err != nil {
+				return yeahapi.E(op, err)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*30)
+		defer cancel()
+
+		listing, err := s.ownListing(ctx, listingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		session := yeahapi.SessionFromContext(ctx)
+		created := make([]yeahapi.Media, 0, len(photos))
+		for i, photo := range photos {
+			media, err := s.storeMedia(ctx, &yeahapi.Media{
+				ListingID:   listing.ID,
+				OwnerID:     session.UserID,
+				ContentType: types[i],
+				Size:        int64(len(photo)),
+			}, photo)
+
+			if err != nil {
+				return yeahapi.E(op, err)
+			}
+
+			created = append(created, *media)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"media.media", s.mediaURLs(created)})
+	}
+}
+
+// readPhoto reads an uploaded photo and strips its metadata.
+func readPhoto(file *multipart.FileHeader) ([]byte, string, error) {
+	const op yeahapi.Op = "http/media.readPhoto"
+	if file.Size > yeahapi.MaxMediaSize {
+		return nil, "", yeahapi.E(op, yeahapi.ETooLarge, fmt.Sprintf("%s is larger than %d MB", file.Filename, yeahapi.MaxMediaSize>>20))
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, "", yeahapi.E(op, err)
+	}
+
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, yeahapi.MaxMediaSize+1))
+	if err != nil {
+		return nil, "", yeahapi.E(op, err)
+	} else if len(data) > yeahapi.MaxMediaSize {
+		return nil, "", yeahapi.E(op, yeahapi.ETooLarge, fmt.Sprintf("%s is larger than %d MB", file.Filename, yeahapi.MaxMediaSize>>20))
+	}
+
+	data, contentType, err := yeahapi.SanitizeImage(data)
+	if err != nil {
+		return nil, "", yeahapi.E(op, err, fmt.Sprintf("%s: %s", file.Filename, yeahapi.ErrorMessage(err)))
+	}
+
+	return data, contentType, nil
+}
+
+// storeMedia puts the photo into the blob store and then records it. The blob
+// is removed again if it can't be recorded.
+func (s *Server) storeMedia(ctx context.Context, media *yeahapi.Media, photo []byte) (*yeahapi.Media, error) {
+	const op yeahapi.Op = "http/media.storeMedia"
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	media.ID = id
+	media.Key = yeahapi.MediaKey(media)
+	if err := s.BlobStore.Put(ctx, media.Key, bytes.NewReader(photo), media.Size, media.ContentType); err != nil {
+		return nil, yeahapi.E(op, err, "Couldn't upload photo. Please, try again")
+	}
+
+	created, err := s.MediaService.CreateMedia(ctx, media)
+	if err != nil {
+		if err := s.BlobStore.Delete(context.Background(), media.Key); err != nil {
+			fmt.Println(err)
+		}
+		if yeahapi.EIs(yeahapi.EInvalid, err) {
+			return nil, yeahapi.E(op, err)
+		}
+		return nil, yeahapi.E(op, err, "Couldn't upload photo. Please, try again")
+	}
+
+	return created, nil
+}
+
+func (s *Server) mediaURLs(media []yeahapi.Media) []yeahapi.Media {
+	for i := range media {
+		media[i].URL = s.BlobStore.URL(media[i].Key)
+		for j, variant := range media[i].Variants {
+			media[i].Variants[j].URL = s.BlobStore.URL(yeahapi.VariantKey(&media[i], variant.Name))
+		}
+	}
+	return media
+}
+
+func (s *Server) handleGetListingMedia() Handler {
+	const op yeahapi.Op = "http/media.handleGetListingMedia"
+	type request struct {
+		ListingID uuid.UUID `json:"listing_id"`
+	}
+	type response struct {
+		T     string          `json:"_"`
+		Media []yeahapi.Media `json:"media"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		media, err := s.MediaService.ListingMedia(ctx, req.ListingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"media.media", s.mediaURLs(media)})
+	}
+}
+
+type reorderMediaData struct {
+	ListingID uuid.UUID   `json:"listing_id"`
+	MediaIDs  []uuid.UUID `json:"media_ids"`
+}
+
+func (d reorderMediaData) Ok() error {
+	if d.ListingID.IsNil() {
+		return yeahapi.E(yeahapi.EInvalid, "Listing id is required")
+	}
+	if len(d.MediaIDs) == 0 {
+		return yeahapi.E(yeahapi.EInvalid, "Media ids are required")
+	}
+	return nil
+}
+
+func (s *Server) handleReorderMedia() Handler {
+	const op yeahapi.Op = "http/media.handleReorderMedia"
+	type response struct {
+		T     string          `json:"_"`
+		Media []yeahapi.Media `json:"media"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req reorderMediaData
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		if _, err := s.ownListing(ctx, req.ListingID); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		media, err := s.MediaService.ReorderMedia(ctx, req.ListingID, req.MediaIDs)
+		if err != nil {
+			if yeahapi.EIs(yeahapi.EInvalid, err) {
+				return yeahapi.E(op, err)
+			}
+			return yeahapi.E(op, err, "Couldn't reorder photos. Please, try again")
+		}
+
+		return JSON(w, r, http.StatusOK, response{"media.media", s.mediaURLs(media)})
+	}
+}
+
+func (s *Server) handleSetPrimaryMedia() Handler {
+	const op yeahapi.Op = "http/media.handleSetPrimaryMedia"
+	type request struct {
+		MediaID uuid.UUID `json:"media_id"`
+	}
+	type response struct {
+		T     string          `json:"_"`
+		Media []yeahapi.Media `json:"media"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		media, err := s.ownMedia(ctx, req.MediaID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		if err := s.MediaService.SetPrimaryMedia(ctx, media.ID); err != nil {
+			return yeahapi.E(op, err, "Couldn't update photo. Please, try again")
+		}
+
+		listingMedia, err := s.MediaService.ListingMedia(ctx, media.ListingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"media.media", s.mediaURLs(listingMedia)})
+	}
+}
+
+func (s *Server) handleDeleteMedia() Handler {
+	const op yeahapi.Op = "http/media.handleDeleteMedia"
+	type request struct {
+		MediaID uuid.UUID `json:"media_id"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		if _, err := s.ownMedia(ctx, req.MediaID); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		media, err := s.MediaService.DeleteMedia(ctx, req.MediaID)
+		if err != nil {
+			return yeahapi.E(op, err, "Couldn't delete photo. Please, try again")
+		}
+
+		// The photo is gone from the listing either way. A blob left behind
+		// only takes up space.
+		if err := s.BlobStore.Delete(ctx, media.Key); err != nil {
+			fmt.Println(err)
+		}
+
+		for _, variant := range media.Variants {
+			if err := s.BlobStore.Delete(ctx, yeahapi.VariantKey(media, variant.Name)); err != nil {
+				fmt.Println(err)
+			}
+		}
+
+		return JSON(w, r, http.StatusOK, nil)
+	}
+}
+
+// ownMedia fetches the photo and makes sure its listing belongs to the
+// session user.
+func (s *Server) ownMedia(ctx context.Context, id uuid.UUID) (*yeahapi.Media, error) {
+	const op yeahapi.Op = "http/media.ownMedia"
+	media, err := s.MediaService.Media(ctx, id)
+	if err != nil {
+		if yeahapi.EIs(yeahapi.ENotFound, err) {
+			return nil, yeahapi.E(op, err, fmt.Sprintf("Photo with id %s not found", id))
+		}
+		return nil, yeahapi.E(op, err, "Something went wrong on our end. Please, try again later")
+	}
+
+	if _, err := s.ownListing(ctx, media.ListingID); err != nil {
+		return nil, yeahapi.E(op, err)
+	}
+
+	return media, nil
+}
+
+// handleGetBlob serves blobs for stores that aren't served by something
+// else, such as the disk store.
+func (s *Server) handleGetBlob() Handler {
+	const op yeahapi.Op = "http/media.handleGetBlob"
+	return func(w http.ResponseWriter, r *http.Request) error {
+		blob, err := s.BlobStore.Get(r.Context(), strings.TrimPrefix(r.URL.Path, "/blobs/"))
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		defer blob.Body.Close()
+
+		w.Header().Set("Content-Type", blob.ContentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(blob.Size, 10))
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		io.Copy(w, blob.Body)
+		return nil
+	}
+}
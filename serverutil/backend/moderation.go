@@ -0,0 +1,270 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+func (s *Server) registerModerationRoutes() {
+	s.mux.Handle("/moderation.getReasons", get(s.clientOnly(s.handleGetModerationReasons())))
+	s.mux.Handle("/moderation.getQueue", post(s.moderatorOnly(s.handleGetModerationQueue())))
+	s.mux.Handle("/moderation.claim", post(s.moderatorOnly(s.handleClaimModeration())))
+	s.mux.Handle("/moderation.release", post(s.moderatorOnly(s.handleReleaseModeration())))
+	s.mux.Handle("/moderation.approve", post(s.moderatorOnly(s.handleDecideModeration(yeahapi.ModerationApproved))))
+	s.mux.Handle("/moderation.reject", post(s.moderatorOnly(s.handleDecideModeration(yeahapi.ModerationRejected))))
+	s.mux.Handle("/moderation.getDecisions", post(s.userOnly(s.handleGetModerationDecisions())))
+	s.mux.Handle("/moderation.getCheckResults", post(s.moderatorOnly(s.handleGetCheckResults())))
+}
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		return maxPageLimit
+	}
+	return limit
+}
+
+func (s *Server) handleGetModerationReasons() Handler {
+	const op yeahapi.Op = "http/moderation.handleGetModerationReasons"
+	type response struct {
+		T       string                     `json:"_"`
+		Reasons []yeahapi.ModerationReason `json:"reasons"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		reasons, err := s.ModerationService.Reasons(ctx, lang(r))
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"moderation.reasons", reasons})
+	}
+}
+
+func (s *Server) handleGetModerationQueue() Handler {
+	const op yeahapi.Op = "http/moderation.handleGetModerationQueue"
+	type request struct {
+		Limit int `json:"limit"`
+	}
+	type response struct {
+		T     string                   `json:"_"`
+		Items []yeahapi.ModerationItem `json:"items"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		items, err := s.ModerationService.Queue(ctx, pageLimit(req.Limit))
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"moderation.queue", items})
+	}
+}
+
+func (s *Server) handleClaimModeration() Handler {
+	const op yeahapi.Op = "http/moderation.handleClaimModeration"
+	type response struct {
+		T            string                  `json:"_"`
+		Item         *yeahapi.ModerationItem `json:"item"`
+		Listing      *yeahapi.Listing        `json:"listing"`
+		Skus         []yeahapi.ListingSku    `json:"skus"`
+		CheckResults []yeahapi.CheckResult   `json:"check_results"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		item, err := s.ModerationService.Claim(ctx, session.UserID, yeahapi.ModerationLease)
+		if err != nil {
+			if yeahapi.EIs(yeahapi.ENotFound, err) {
+				return yeahapi.E(op, err, "Moderation queue is empty")
+			}
+			return yeahapi.E(op, err, "Something went wrong on our end. Please, try again later")
+		}
+
+		listing, err := s.ListingService.Listing(ctx, item.ListingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		skus, err := s.ListingService.Skus(ctx, item.ListingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		results, err := s.ModerationService.CheckResults(ctx, item.ListingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"moderation.claimedItem", item, listing, skus, results})
+	}
+}
+
+func (s *Server) handleReleaseModeration() Handler {
+	const op yeahapi.Op = "http/moderation.handleReleaseModeration"
+	type request struct {
+		ID uuid.UUID `json:"listing_id"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		if err := s.ModerationService.Release(ctx, req.ID, session.UserID); err != nil {
+			if yeahapi.EIs(yeahapi.ENotFound, err) {
+				return yeahapi.E(op, err, "You haven't claimed this listing")
+			}
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, nil)
+	}
+}
+
+type decisionData struct {
+	ListingID  uuid.UUID `json:"listing_id"`
+	ReasonCode string    `json:"reason_code"`
+	Comment    string    `json:"comment"`
+}
+
+func (d decisionData) Ok() error {
+	if d.ListingID.IsNil() {
+		return yeahapi.E(yeahapi.EInvalid, "Listing id is required")
+	}
+	return nil
+}
+
+func (s *Server) handleDecideModeration(kind yeahapi.ModerationDecisionKind) Handler {
+	const op yeahapi.Op = "http/moderation.handleDecideModeration"
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.ModerationDecision
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req decisionData
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		decision, err := s.ModerationService.Decide(ctx, &yeahapi.ModerationDecision{
+			ListingID:   req.ListingID,
+			ModeratorID: session.UserID,
+			Kind:        kind,
+			ReasonCode:  req.ReasonCode,
+			Comment:     req.Comment,
+		})
+
+		if err != nil {
+			if yeahapi.EIs(yeahapi.EConflict, err) {
+				return yeahapi.E(op, err, "Your claim on this listing has expired. Please, claim it again")
+			}
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"moderation.decision", decision})
+	}
+}
+
+func (s *Server) handleGetModerationDecisions() Handler {
+	const op yeahapi.Op = "http/moderation.handleGetModerationDecisions"
+	type request struct {
+		ID uuid.UUID `json:"listing_id"`
+	}
+	type response struct {
+		T         string                       `json:"_"`
+		Decisions []yeahapi.ModerationDecision `json:"decisions"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		if _, err := s.ownListing(ctx, req.ID); err != nil {
+			if !yeahapi.EIs(yeahapi.EPermission, err) {
+				return yeahapi.E(op, err)
+			}
+
+			session := yeahapi.SessionFromContext(ctx)
+			if ok, err := s.ModerationService.IsModerator(ctx, session.UserID); err != nil {
+				return yeahapi.E(op, err)
+			} else if !ok {
+				return yeahapi.E(op, yeahapi.EPermission, "You don't have access to this listing")
+			}
+		}
+
+		decisions, err := s.ModerationService.Decisions(ctx, req.ID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"moderation.decisions", decisions})
+	}
+}
+
+func (s *Server) handleGetCheckResults() Handler {
+	const op yeahapi.Op = "http/moderation.handleGetCheckResults"
+	type request struct {
+		ID uuid.UUID `json:"listing_id"`
+	}
+	type response struct {
+		T       string                `json:"_"`
+		Results []yeahapi.CheckResult `json:"results"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		results, err := s.ModerationService.CheckResults(ctx, req.ID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"moderation.checkResults", results})
+	}
+}
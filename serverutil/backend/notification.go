@@ -0,0 +1,69 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+func (s *Server) registerNotificationRoutes() {
+	s.mux.Handle("/notifications.getNotifications", post(s.userOnly(s.handleGetNotifications())))
+	s.mux.Handle("/notifications.markRead", post(s.userOnly(s.handleMarkNotificationsRead())))
+}
+
+func (s *Server) handleGetNotifications() Handler {
+	const op yeahapi.Op = "http/notifications.handleGetNotifications"
+	type request struct {
+		Limit int `json:"limit"`
+	}
+	type response struct {
+		T             string                 `json:"_"`
+		Notifications []yeahapi.Notification `json:"notifications"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		notifications, err := s.NotificationService.Notifications(ctx, session.UserID, pageLimit(req.Limit))
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"notifications.notifications", notifications})
+	}
+}
+
+func (s *Server) handleMarkNotificationsRead() Handler {
+	const op yeahapi.Op = "http/notifications.handleMarkNotificationsRead"
+	type request struct {
+		IDs []uuid.UUID `json:"notification_ids"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		if err := s.NotificationService.MarkRead(ctx, session.UserID, req.IDs); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, nil)
+	}
+}
@@ -0,0 +1,117 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+func (s *Server) registerPriceRoutes() {
+	s.mux.Handle("/listings.schedulePrice", post(s.userOnly(s.handleSchedulePrice())))
+	s.mux.Handle("/listings.getPriceHistory", post(s.clientOnly(s.handleGetPriceHistory())))
+}
+
+type schedulePriceData struct {
+	SkuID     uuid.UUID        `json:"sku_id"`
+	UnitPrice int              `json:"unit_price"`
+	Currency  yeahapi.Currency `json:"currency"`
+	StartDate time.Time        `json:"start_date"`
+	EndDate   *time.Time       `json:"end_date"`
+}
+
+func (d schedulePriceData) Ok() error {
+	if d.SkuID.IsNil() {
+		return yeahapi.E(yeahapi.EInvalid, "SKU id is required")
+	}
+	if d.StartDate.IsZero() {
+		return yeahapi.E(yeahapi.EInvalid, "Start date is required")
+	}
+	if d.StartDate.Before(time.Now().Add(-time.Minute)) {
+		return yeahapi.E(yeahapi.EInvalid, "Start date can't be in the past")
+	}
+	if d.EndDate != nil && !d.EndDate.After(d.StartDate) {
+		return yeahapi.E(yeahapi.EInvalid, "Sale must end after it starts")
+	}
+	return currencyOk(d.Currency)
+}
+
+func (s *Server) handleSchedulePrice() Handler {
+	const op yeahapi.Op = "http/listings.handleSchedulePrice"
+	type response struct {
+		T      string                    `json:"_"`
+		Prices []yeahapi.ListingSkuPrice `json:"prices"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req schedulePriceData
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		sku, err := s.ListingService.Sku(ctx, req.SkuID)
+		if err != nil {
+			if yeahapi.EIs(yeahapi.ENotFound, err) {
+				return yeahapi.E(op, err, fmt.Sprintf("SKU with id %s not found", req.SkuID))
+			}
+			return yeahapi.E(op, err, "Something went wrong on our end. Please, try again later")
+		}
+
+		if _, err := s.ownListing(ctx, sku.ListingID); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		err = s.PriceService.SchedulePrice(ctx, &yeahapi.ListingSkuPrice{
+			SkuID:     sku.ID,
+			Amount:    req.UnitPrice,
+			Currency:  req.Currency,
+			StartDate: req.StartDate,
+		}, req.EndDate)
+
+		if err != nil {
+			return yeahapi.E(op, err, "Couldn't schedule price. Please, try again")
+		}
+
+		prices, err := s.PriceService.PriceHistory(ctx, sku.ID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.priceHistory", prices})
+	}
+}
+
+func (s *Server) handleGetPriceHistory() Handler {
+	const op yeahapi.Op = "http/listings.handleGetPriceHistory"
+	type request struct {
+		SkuID uuid.UUID `json:"sku_id"`
+	}
+	type response struct {
+		T      string                    `json:"_"`
+		Prices []yeahapi.ListingSkuPrice `json:"prices"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		prices, err := s.PriceService.PriceHistory(ctx, req.SkuID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.priceHistory", prices})
+	}
+}
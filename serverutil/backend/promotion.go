@@ -0,0 +1,186 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+func (s *Server) registerPromotionRoutes() {
+	s.mux.Handle("/promotions.getProducts", get(s.clientOnly(s.handleGetPromotionProducts(true))))
+	s.mux.Handle("/promotions.purchase", post(s.userOnly(s.handlePurchasePromotion())))
+	s.mux.Handle("/promotions.getPurchases", post(s.userOnly(s.handleGetPromotionPurchases())))
+
+	s.mux.Handle("/promotions.getAllProducts", get(s.adminOnly(s.handleGetPromotionProducts(false))))
+	s.mux.Handle("/promotions.createProduct", post(s.adminOnly(s.handleCreatePromotionProduct())))
+	s.mux.Handle("/promotions.updateProduct", post(s.adminOnly(s.handleUpdatePromotionProduct())))
+	// Payments are confirmed by admins until payment providers call us back.
+	s.mux.Handle("/promotions.confirmPayment", post(s.adminOnly(s.handleConfirmPromotionPayment())))
+}
+
+func (s *Server) handleGetPromotionProducts(activeOnly bool) Handler {
+	const op yeahapi.Op = "http/promotions.handleGetPromotionProducts"
+	type response struct {
+		T        string                     `json:"_"`
+		Products []yeahapi.PromotionProduct `json:"products"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		products, err := s.PromotionService.Products(ctx, activeOnly)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"promotions.products", products})
+	}
+}
+
+func (s *Server) handleCreatePromotionProduct() Handler {
+	const op yeahapi.Op = "http/promotions.handleCreatePromotionProduct"
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.PromotionProduct
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req yeahapi.PromotionProduct
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		product, err := s.PromotionService.CreateProduct(ctx, &req)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"promotions.product", product})
+	}
+}
+
+func (s *Server) handleUpdatePromotionProduct() Handler {
+	const op yeahapi.Op = "http/promotions.handleUpdatePromotionProduct"
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.PromotionProduct
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req yeahapi.PromotionProduct
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		product, err := s.PromotionService.UpdateProduct(ctx, &req)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"promotions.product", product})
+	}
+}
+
+func (s *Server) handlePurchasePromotion() Handler {
+	const op yeahapi.Op = "http/promotions.handlePurchasePromotion"
+	type request struct {
+		ListingID uuid.UUID        `json:"listing_id"`
+		ProductID int              `json:"product_id"`
+		Currency  yeahapi.Currency `json:"currency"`
+	}
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.PromotionPurchase
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		purchase, err := s.PromotionService.Purchase(ctx, session.UserID, req.ListingID, req.ProductID, req.Currency)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"promotions.purchase", purchase})
+	}
+}
+
+func (s *Server) handleGetPromotionPurchases() Handler {
+	const op yeahapi.Op = "http/promotions.handleGetPromotionPurchases"
+	type request struct {
+		ListingID uuid.UUID `json:"listing_id"`
+	}
+	type response struct {
+		T          string                      `json:"_"`
+		Purchases  []yeahapi.PromotionPurchase `json:"purchases"`
+		Promotions []yeahapi.ListingPromotion  `json:"promotions"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		if _, err := s.ownListing(ctx, req.ListingID); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		purchases, err := s.PromotionService.Purchases(ctx, req.ListingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		promotions, err := s.PromotionService.ListingPromotions(ctx, req.ListingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"promotions.purchases", purchases, promotions})
+	}
+}
+
+func (s *Server) handleConfirmPromotionPayment() Handler {
+	const op yeahapi.Op = "http/promotions.handleConfirmPromotionPayment"
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.PromotionPurchase
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req yeahapi.PromotionPayment
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		purchase, err := s.PromotionService.ConfirmPayment(ctx, req)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"promotions.purchase", purchase})
+	}
+}
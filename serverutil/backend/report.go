@@ -0,0 +1,162 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+func (s *Server) registerReportRoutes() {
+	s.mux.Handle("/reports.getReasons", get(s.clientOnly(s.handleGetReportReasons())))
+	s.mux.Handle("/reports.create", post(s.userOnly(s.handleCreateReport())))
+	s.mux.Handle("/reports.getQueue", post(s.moderatorOnly(s.handleGetReportQueue())))
+	s.mux.Handle("/reports.getReports", post(s.moderatorOnly(s.handleGetReports())))
+	s.mux.Handle("/reports.resolve", post(s.moderatorOnly(s.handleResolveReports())))
+}
+
+func (s *Server) handleGetReportReasons() Handler {
+	const op yeahapi.Op = "http/reports.handleGetReportReasons"
+	type response struct {
+		T       string                 `json:"_"`
+		Reasons []yeahapi.ReportReason `json:"reasons"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		reasons, err := s.ReportService.Reasons(ctx, lang(r))
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"reports.reasons", reasons})
+	}
+}
+
+func (s *Server) handleCreateReport() Handler {
+	const op yeahapi.Op = "http/reports.handleCreateReport"
+	type request struct {
+		TargetKind yeahapi.ReportTargetKind `json:"target_kind"`
+		TargetID   uuid.UUID                `json:"target_id"`
+		ReasonCode string                   `json:"reason_code"`
+		Detail     string                   `json:"detail"`
+	}
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.Report
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		report, err := s.ReportService.CreateReport(ctx, &yeahapi.Report{
+			ReporterID: session.UserID,
+			TargetKind: req.TargetKind,
+			TargetID:   req.TargetID,
+			ReasonCode: req.ReasonCode,
+			Detail:     req.Detail,
+		})
+
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"reports.report", report})
+	}
+}
+
+func (s *Server) handleGetReportQueue() Handler {
+	const op yeahapi.Op = "http/reports.handleGetReportQueue"
+	type request struct {
+		Limit int `json:"limit"`
+	}
+	type response struct {
+		T       string                   `json:"_"`
+		Targets []yeahapi.ReportedTarget `json:"targets"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		targets, err := s.ReportService.Queue(ctx, pageLimit(req.Limit))
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"reports.queue", targets})
+	}
+}
+
+func (s *Server) handleGetReports() Handler {
+	const op yeahapi.Op = "http/reports.handleGetReports"
+	type request struct {
+		TargetKind yeahapi.ReportTargetKind `json:"target_kind"`
+		TargetID   uuid.UUID                `json:"target_id"`
+	}
+	type response struct {
+		T       string           `json:"_"`
+		Reports []yeahapi.Report `json:"reports"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		reports, err := s.ReportService.Reports(ctx, req.TargetKind, req.TargetID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"reports.reports", reports})
+	}
+}
+
+// handleResolveReports resolves every open report of a target and lets each
+// reporter know the outcome.
+func (s *Server) handleResolveReports() Handler {
+	const op yeahapi.Op = "http/reports.handleResolveReports"
+	type response struct {
+		T       string           `json:"_"`
+		Reports []yeahapi.Report `json:"reports"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req yeahapi.ReportResolution
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		req.ModeratorID = yeahapi.SessionFromContext(ctx).UserID
+		reports, err := s.ReportService.Resolve(ctx, req)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"reports.reports", reports})
+	}
+}
@@ -0,0 +1,137 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+func (s *Server) registerSavedSearchRoutes() {
+	s.mux.Handle("/savedSearches.create", post(s.userOnly(s.handleCreateSavedSearch())))
+	s.mux.Handle("/savedSearches.delete", post(s.userOnly(s.handleDeleteSavedSearch())))
+	s.mux.Handle("/savedSearches.getSavedSearches", post(s.userOnly(s.handleGetSavedSearches())))
+	s.mux.Handle("/savedSearches.setFrequency", post(s.userOnly(s.handleSetSavedSearchFrequency())))
+}
+
+func (s *Server) handleCreateSavedSearch() Handler {
+	const op yeahapi.Op = "http/savedSearches.handleCreateSavedSearch"
+	type request struct {
+		Name       string              `json:"name"`
+		Query      string              `json:"q"`
+		CategoryID int                 `json:"category_id"`
+		MinPrice   *int                `json:"min_price"`
+		MaxPrice   *int                `json:"max_price"`
+		Currency   yeahapi.Currency    `json:"currency"`
+		Attrs      map[string][]string `json:"attrs"`
+	}
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.SavedSearch
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		search, err := s.SavedSearchService.CreateSavedSearch(ctx, &yeahapi.SavedSearch{
+			UserID:     session.UserID,
+			Name:       req.Name,
+			Query:      req.Query,
+			CategoryID: req.CategoryID,
+			MinPrice:   req.MinPrice,
+			MaxPrice:   req.MaxPrice,
+			Currency:   req.Currency,
+			Attrs:      req.Attrs,
+		})
+
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"savedSearches.savedSearch", search})
+	}
+}
+
+func (s *Server) handleDeleteSavedSearch() Handler {
+	const op yeahapi.Op = "http/savedSearches.handleDeleteSavedSearch"
+	type request struct {
+		ID uuid.UUID `json:"saved_search_id"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		if err := s.SavedSearchService.DeleteSavedSearch(ctx, session.UserID, req.ID); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, nil)
+	}
+}
+
+func (s *Server) handleGetSavedSearches() Handler {
+	const op yeahapi.Op = "http/savedSearches.handleGetSavedSearches"
+	type response struct {
+		T             string                       `json:"_"`
+		SavedSearches []yeahapi.SavedSearch        `json:"saved_searches"`
+		Frequency     yeahapi.SavedSearchFrequency `json:"frequency"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		searches, err := s.SavedSearchService.SavedSearches(ctx, session.UserID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		frequency, err := s.SavedSearchService.Frequency(ctx, session.UserID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"savedSearches.savedSearches", searches, frequency})
+	}
+}
+
+func (s *Server) handleSetSavedSearchFrequency() Handler {
+	const op yeahapi.Op = "http/savedSearches.handleSetSavedSearchFrequency"
+	type request struct {
+		Frequency yeahapi.SavedSearchFrequency `json:"frequency"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		session := yeahapi.SessionFromContext(ctx)
+		if err := s.SavedSearchService.SetFrequency(ctx, session.UserID, req.Frequency); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, nil)
+	}
+}
@@ -0,0 +1,80 @@
+package backend
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+func (s *Server) registerSearchRoutes() {
+	s.mux.Handle("/listings.search", post(s.clientOnly(s.handleSearchListings())))
+}
+
+type searchData struct {
+	Query      string              `json:"q"`
+	CategoryID int                 `json:"category_id"`
+	LocationID int                 `json:"location_id"`
+	Near       *yeahapi.Point      `json:"near"`
+	Radius     float64             `json:"radius"`
+	MinPrice   *int                `json:"min_price"`
+	MaxPrice   *int                `json:"max_price"`
+	Currency   yeahapi.Currency    `json:"currency"`
+	Attrs      map[string][]string `json:"attrs"`
+	Cursor     string              `json:"cursor"`
+	Limit      int                 `json:"limit"`
+}
+
+func (d searchData) Ok() error {
+	return d.search().Ok()
+}
+
+func (d searchData) search() yeahapi.ListingSearch {
+	return yeahapi.ListingSearch{
+		Query:      d.Query,
+		CategoryID: d.CategoryID,
+		LocationID: d.LocationID,
+		Near:       d.Near,
+		Radius:     d.Radius,
+		MinPrice:   d.MinPrice,
+		MaxPrice:   d.MaxPrice,
+		Currency:   d.Currency,
+		Attrs:      d.Attrs,
+		Cursor:     d.Cursor,
+		Limit:      pageLimit(d.Limit),
+	}
+}
+
+func (s *Server) handleSearchListings() Handler {
+	const op yeahapi.Op = "http/search.handleSearchListings"
+	type response struct {
+		T string `json:"_"`
+		*yeahapi.SearchPage
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req searchData
+		defer r.Body.Close()
+		if err := decode(r, &req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		search := req.search()
+		search.Lang = lang(r)
+		page, err := s.SearchService.Search(ctx, search)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		listings := make([]yeahapi.Listing, len(page.Hits))
+		for i, hit := range page.Hits {
+			listings[i] = hit.Listing
+		}
+		s.recordHits(r, yeahapi.HitImpression, listings...)
+
+		return JSON(w, r, http.StatusOK, response{"listings.searchResults", page})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/gofrs/uuid"
 	yeahapi "github.com/yeahuz/yeah-api"
+	"golang.org/x/text/language"
 )
 
 const ShutdownTimeout = 1 * time.Second
@@ -22,20 +23,38 @@ type Server struct {
 	ln     net.Listener
 	Addr   string
 
-	AuthService       yeahapi.AuthService
-	UserService       yeahapi.UserService
-	CQRSService       yeahapi.CQRSService
-	CredentialService yeahapi.CredentialService
-	LocalizerService  yeahapi.LocalizerService
-	ClientService     yeahapi.ClientService
-	ListingService    yeahapi.ListingService
-	KVService         yeahapi.KVService
-	CategoryService   yeahapi.CategoryService
+	AuthService           yeahapi.AuthService
+	UserService           yeahapi.UserService
+	CQRSService           yeahapi.CQRSService
+	CredentialService     yeahapi.CredentialService
+	LocalizerService      yeahapi.LocalizerService
+	ClientService         yeahapi.ClientService
+	ListingService        yeahapi.ListingService
+	KVService             yeahapi.KVService
+	CategoryService       yeahapi.CategoryService
+	ModerationService     yeahapi.ModerationService
+	NotificationService   yeahapi.NotificationService
+	SearchService         yeahapi.SearchService
+	PriceService          yeahapi.PriceService
+	CurrencyService       yeahapi.CurrencyService
+	InventoryService      yeahapi.InventoryService
+	MediaService          yeahapi.MediaService
+	BlobStore             yeahapi.BlobStore
+	LocationService       yeahapi.LocationService
+	FavoriteService       yeahapi.FavoriteService
+	SavedSearchService    yeahapi.SavedSearchService
+	StatsService          yeahapi.StatsService
+	HitRecorder           yeahapi.HitRecorder
+	ExpiryService         yeahapi.ExpiryService
+	PromotionService      yeahapi.PromotionService
+	ReportService         yeahapi.ReportService
+	RecommendationService yeahapi.RecommendationService
 }
 
 type errorResponse struct {
-	StatusCode int    `json:"status_code"`
-	Message    string `json:"message"`
+	StatusCode int            `json:"status_code"`
+	Message    string         `json:"message"`
+	Fields     yeahapi.Fields `json:"fields,omitempty"`
 }
 
 func NewServer() *Server {
@@ -50,6 +69,19 @@ func NewServer() *Server {
 	s.registerCredentialRoutes()
 	s.registerCategoryRoutes()
 	s.registerListingRoutes()
+	s.registerModerationRoutes()
+	s.registerNotificationRoutes()
+	s.registerSearchRoutes()
+	s.registerPriceRoutes()
+	s.registerCurrencyRoutes()
+	s.registerInventoryRoutes()
+	s.registerMediaRoutes()
+	s.registerLocationRoutes()
+	s.registerFavoriteRoutes()
+	s.registerSavedSearchRoutes()
+	s.registerStatsRoutes()
+	s.registerPromotionRoutes()
+	s.registerReportRoutes()
 	return s
 }
 
@@ -90,6 +122,7 @@ func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 		if e, ok := err.(*yeahapi.Error); ok {
 			resp.Message = yeahapi.ErrorMessage(e)
+			resp.Fields = yeahapi.ErrorFields(e)
 			resp.StatusCode = errStatusCode(yeahapi.ErrorKind(e))
 			JSON(w, r, errStatusCode(e.Kind), resp)
 			return
@@ -174,6 +207,40 @@ func (s *Server) userOnly(next Handler) Handler {
 	}
 }
 
+func (s *Server) moderatorOnly(next Handler) Handler {
+	const op yeahapi.Op = "http/server.moderatorOnly"
+	return s.userOnly(func(w http.ResponseWriter, r *http.Request) error {
+		session := yeahapi.SessionFromContext(r.Context())
+		ok, err := s.ModerationService.IsModerator(r.Context(), session.UserID)
+		if err != nil {
+			return yeahapi.E(op, err, "Something went wrong on our end. Please, try again later")
+		}
+
+		if !ok {
+			return yeahapi.E(op, yeahapi.EPermission, "Only moderators can do this")
+		}
+
+		return next(w, r)
+	})
+}
+
+func (s *Server) adminOnly(next Handler) Handler {
+	const op yeahapi.Op = "http/server.adminOnly"
+	return s.userOnly(func(w http.ResponseWriter, r *http.Request) error {
+		session := yeahapi.SessionFromContext(r.Context())
+		ok, err := s.UserService.IsAdmin(r.Context(), session.UserID)
+		if err != nil {
+			return yeahapi.E(op, err, "Something went wrong on our end. Please, try again later")
+		}
+
+		if !ok {
+			return yeahapi.E(op, yeahapi.EPermission, "Only admins can do this")
+		}
+
+		return next(w, r)
+	})
+}
+
 var statusCodes = map[yeahapi.Kind]int{
 	yeahapi.EInternal:         http.StatusInternalServerError,
 	yeahapi.EInvalid:          http.StatusBadRequest,
@@ -183,6 +250,8 @@ var statusCodes = map[yeahapi.Kind]int{
 	yeahapi.EFound:            http.StatusConflict,
 	yeahapi.ENotImplemented:   http.StatusNotImplemented,
 	yeahapi.EMethodNotAllowed: http.StatusMethodNotAllowed,
+	yeahapi.EConflict:         http.StatusConflict,
+	yeahapi.ETooLarge:         http.StatusRequestEntityTooLarge,
 	yeahapi.EOther:            http.StatusInternalServerError,
 }
 
@@ -228,3 +297,13 @@ func getIP(r *http.Request) string {
 
 	return host
 }
+
+var supportedLangs = language.NewMatcher([]language.Tag{language.English, language.Russian, language.Uzbek})
+
+// lang picks the best supported language from the Accept-Language header,
+// falling back to English.
+func lang(r *http.Request) string {
+	tags, _, _ := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	_, i, _ := supportedLangs.Match(tags...)
+	return [...]string{"en", "ru", "uz"}[i]
+}
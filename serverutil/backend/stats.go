@@ -0,0 +1,133 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+func (s *Server) registerStatsRoutes() {
+	s.mux.Handle("/listings.getStats", post(s.userOnly(s.handleGetListingStats())))
+	s.mux.Handle("/listings.revealContact", post(s.userOnly(s.handleRevealContact())))
+}
+
+// visitor identifies who made a request for counting hits: the user when
+// there is a session, a hash of the address and user agent otherwise.
+func visitor(r *http.Request) string {
+	if session := yeahapi.SessionFromContext(r.Context()); session != nil {
+		return session.UserID.String()
+	}
+	sum := sha256.Sum256([]byte(getIP(r) + "\n" + r.UserAgent()))
+	return hex.EncodeToString(sum[:16])
+}
+
+// recordHits counts hits of listings that don't belong to the visitor.
+func (s *Server) recordHits(r *http.Request, kind yeahapi.HitKind, listings ...yeahapi.Listing) {
+	if s.HitRecorder == nil {
+		return
+	}
+
+	var userID yeahapi.UserID
+	if session := yeahapi.SessionFromContext(r.Context()); session != nil {
+		userID = session.UserID
+	}
+
+	id, now := visitor(r), time.Now()
+	for _, listing := range listings {
+		if listing.OwnerID != userID {
+			s.HitRecorder.RecordHit(yeahapi.NewListingHit(listing.ID, kind, id, now))
+		}
+	}
+}
+
+func (s *Server) handleGetListingStats() Handler {
+	const op yeahapi.Op = "http/stats.handleGetListingStats"
+	type request struct {
+		ListingID uuid.UUID `json:"listing_id"`
+		Days      int       `json:"days"`
+	}
+	type response struct {
+		T     string                 `json:"_"`
+		Stats []yeahapi.ListingStats `json:"stats"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		if req.Days == 0 {
+			req.Days = 30
+		} else if req.Days < 0 || req.Days > yeahapi.MaxStatsDays {
+			return yeahapi.E(op, yeahapi.EInvalid, fmt.Sprintf("Days must be between 1 and %d", yeahapi.MaxStatsDays))
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		if _, err := s.ownListing(ctx, req.ListingID); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		to := time.Now().UTC()
+		stats, err := s.StatsService.ListingStats(ctx, yeahapi.StatsFilter{
+			ListingID: req.ListingID,
+			From:      to.AddDate(0, 0, 1-req.Days),
+			To:        to,
+		})
+
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		return JSON(w, r, http.StatusOK, response{"listings.stats", stats})
+	}
+}
+
+func (s *Server) handleRevealContact() Handler {
+	const op yeahapi.Op = "http/stats.handleRevealContact"
+	type request struct {
+		ListingID uuid.UUID `json:"listing_id"`
+	}
+	type response struct {
+		T           string `json:"_"`
+		FirstName   string `json:"first_name"`
+		LastName    string `json:"last_name"`
+		PhoneNumber string `json:"phone_number"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		listing, err := s.ListingService.Listing(ctx, req.ListingID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		if listing.Status != yeahapi.ListingStatusActive {
+			return yeahapi.E(op, yeahapi.ENotFound, fmt.Sprintf("Listing with id %s not found", req.ListingID))
+		}
+
+		owner, err := s.UserService.User(ctx, listing.OwnerID)
+		if err != nil {
+			return yeahapi.E(op, err)
+		}
+
+		s.recordHits(r, yeahapi.HitContactReveal, *listing)
+		return JSON(w, r, http.StatusOK, response{"listings.contact", owner.FirstName, owner.LastName, owner.PhoneNumber})
+	}
+}
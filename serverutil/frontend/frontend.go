@@ -17,6 +17,7 @@ import (
 	"github.com/pelletier/go-toml/v2"
 	yeahapi "github.com/yeahuz/yeah-api"
 	"github.com/yeahuz/yeah-api/aws"
+	"github.com/yeahuz/yeah-api/disk"
 	"github.com/yeahuz/yeah-api/inmem"
 	"github.com/yeahuz/yeah-api/nats"
 	"github.com/yeahuz/yeah-api/postgres"
@@ -40,6 +41,7 @@ type Config struct {
 
 	HTTP struct {
 		Addr string `toml:"addr"`
+		URL  string `toml:"url"`
 	} `toml:"http"`
 
 	AWS struct {
@@ -70,6 +72,15 @@ type Config struct {
 	Signing struct {
 		Key64 string `toml:"key64"`
 	} `toml:"signing"`
+
+	// Media is the same as in the api config, photos are only linked to.
+	Media struct {
+		Store    string `toml:"store"`
+		Dir      string `toml:"dir"`
+		Bucket   string `toml:"bucket"`
+		Endpoint string `toml:"endpoint"`
+		BaseURL  string `toml:"base-url"`
+	} `toml:"media"`
 }
 
 func Run() error {
@@ -125,6 +136,10 @@ func (m *Main) Run(ctx context.Context) (err error) {
 	authService := postgres.NewAuthService(m.Pool, argonHasher, highwayHasher, m.Config.Signing.Key64)
 	userService := postgres.NewUserService(m.Pool)
 	listingService := postgres.NewListingService(m.Pool)
+	categoryService := inmem.NewCategoryCache(postgres.NewCategoryService(m.Pool))
+	mediaService := postgres.NewMediaService(m.Pool)
+	currencyService := postgres.NewCurrencyService(m.Pool)
+	sitemapService := postgres.NewSitemapService(m.Pool)
 	cookieService := NewCookieService(m.Config.Cookie.Secret)
 	cqrsService, err := nats.NewCQRSService(ctx, yeahapi.CQRSConfig{
 		NatsURL:       m.Config.Nats.URL,
@@ -145,10 +160,21 @@ func (m *Main) Run(ctx context.Context) (err error) {
 		return err
 	}
 
+	var blobStore yeahapi.BlobStore
+	switch m.Config.Media.Store {
+	case "s3":
+		blobStore = aws.NewBlobStore(awsconfig, m.Config.Media.Endpoint, m.Config.Media.Bucket, m.Config.Media.BaseURL)
+	case "disk", "":
+		blobStore = disk.NewBlobStore(m.Config.Media.Dir, m.Config.Media.BaseURL)
+	default:
+		return fmt.Errorf("unknown media store: %s", m.Config.Media.Store)
+	}
+
 	emailService := aws.NewEmailService(awsconfig, cqrsService)
 	cqrsService.Handle("auth.sendEmailCode", emailService.SendEmailCode)
 
 	m.Server.Addr = m.Config.HTTP.Addr
+	m.Server.URL = m.Config.HTTP.URL
 	m.Server.ClientID = yeahapi.ClientID{m.Config.Client.ID}
 
 	m.Server.AuthService = authService
@@ -156,6 +182,11 @@ func (m *Main) Run(ctx context.Context) (err error) {
 	m.Server.ListingService = listingService
 	m.Server.CQRSService = cqrsService
 	m.Server.CookieService = cookieService
+	m.Server.CategoryService = categoryService
+	m.Server.MediaService = mediaService
+	m.Server.BlobStore = blobStore
+	m.Server.CurrencyService = currencyService
+	m.Server.SitemapService = sitemapService
 
 	return m.Server.Open()
 }
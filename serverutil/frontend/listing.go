@@ -0,0 +1,411 @@
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/serverutil/frontend/templ/layout"
+	"github.com/yeahuz/yeah-api/serverutil/frontend/templ/listing"
+)
+
+// categoryPageSize is how many listings a category page shows.
+const categoryPageSize = 24
+
+func (s *Server) registerListingRoutes() {
+	s.mux.Handle("/l/", get(s.handleGetListing()))
+	s.mux.Handle("/c/", get(s.handleGetCategory()))
+}
+
+type product struct {
+	Context       string        `json:"@context"`
+	Type          string        `json:"@type"`
+	Name          string        `json:"name"`
+	Description   string        `json:"description,omitempty"`
+	URL           string        `json:"url"`
+	Image         []string      `json:"image,omitempty"`
+	Brand         *productBrand `json:"brand,omitempty"`
+	Category      string        `json:"category,omitempty"`
+	ItemCondition string        `json:"itemCondition,omitempty"`
+	Offers        []offer       `json:"offers,omitempty"`
+}
+
+type productBrand struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type offer struct {
+	Type          string `json:"@type"`
+	SKU           string `json:"sku"`
+	Price         string `json:"price"`
+	PriceCurrency string `json:"priceCurrency"`
+	Availability  string `json:"availability"`
+	URL           string `json:"url"`
+}
+
+var itemConditions = map[yeahapi.ListingCondition]string{
+	yeahapi.ListingConditionNew:         "https://schema.org/NewCondition",
+	yeahapi.ListingConditionUsed:        "https://schema.org/UsedCondition",
+	yeahapi.ListingConditionRefurbished: "https://schema.org/RefurbishedCondition",
+}
+
+func (s *Server) handleGetListing() Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := parseShortID(pathID(r.URL.Path, "/l/"))
+		if err != nil {
+			http.NotFound(w, r)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		l, err := s.ListingService.Listing(ctx, id)
+		if yeahapi.EIs(yeahapi.ENotFound, err) || (err == nil && l.Status != yeahapi.ListingStatusActive) {
+			http.NotFound(w, r)
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		translations, err := s.ListingService.Translations(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		lang := pageLang(r)
+		paths := make(map[string]string, len(pageLangs))
+		for _, pl := range pageLangs {
+			paths[pl] = listingPath(id, localize(*l, translations, pl).Title)
+		}
+
+		if redirectCanonical(w, r, paths[lang]) {
+			return nil
+		}
+
+		localized := localize(*l, translations, lang)
+		skus, err := s.ListingService.Skus(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		photos, err := s.MediaService.ListingMedia(ctx, id)
+		if err != nil {
+			return err
+		}
+		photos = s.mediaURLs(photos)
+
+		categories, err := s.CategoryService.Categories(ctx, lang)
+		if err != nil {
+			return err
+		}
+
+		currencies, err := s.currencies(ctx)
+		if err != nil {
+			return err
+		}
+
+		canonical := s.pageURL(paths[lang], lang, nil)
+		props := listing.Props{
+			Listing:     localized,
+			Breadcrumbs: s.breadcrumbs(categories, l.CategoryID, lang),
+			Photos:      photos,
+			Skus:        make([]listing.Sku, 0, len(skus)),
+		}
+		props.Breadcrumbs = append(props.Breadcrumbs, listing.Link{Title: localized.Title, URL: canonical})
+
+		ld := product{
+			Context:       "https://schema.org",
+			Type:          "Product",
+			Name:          localized.Title,
+			Description:   yeahapi.DescriptionText(localized.Description),
+			URL:           canonical,
+			ItemCondition: itemConditions[localized.Condition],
+			Offers:        make([]offer, 0, len(skus)),
+		}
+		if localized.Brand != "" {
+			ld.Brand = &productBrand{Type: "Brand", Name: localized.Brand}
+		}
+		if c := findCategory(categories, l.CategoryID); c != nil {
+			ld.Category = c.Title
+		}
+		for _, p := range photos {
+			ld.Image = append(ld.Image, p.URL)
+		}
+
+		var cheapest *yeahapi.ListingSku
+		for i, sku := range skus {
+			availability := "https://schema.org/OutOfStock"
+			if sku.Quantity > 0 {
+				availability = "https://schema.org/InStock"
+			}
+			ld.Offers = append(ld.Offers, offer{
+				Type:          "Offer",
+				SKU:           fallbackStr(sku.CustomSku, sku.ID.String()),
+				Price:         decimalAmount(sku.Price, currencies[sku.PriceCurrency]),
+				PriceCurrency: string(sku.PriceCurrency),
+				Availability:  availability,
+				URL:           canonical,
+			})
+
+			props.Skus = append(props.Skus, listing.Sku{
+				Attrs:   attrList(sku.Attrs),
+				Price:   formatPrice(sku.Price, sku.PriceCurrency, currencies),
+				InStock: sku.Quantity > 0,
+			})
+
+			// Skus may be priced in different currencies, the cheapest is
+			// only picked among the ones in the currency of the first.
+			if sku.PriceCurrency == skus[0].PriceCurrency && (cheapest == nil || sku.Price < cheapest.Price) {
+				cheapest = &skus[i]
+			}
+		}
+
+		if cheapest != nil {
+			props.Price = formatPrice(cheapest.Price, cheapest.PriceCurrency, currencies)
+		}
+
+		props.Meta = layout.Meta{
+			Title:       localized.Title,
+			Description: summary(ld.Description, 160),
+			Lang:        lang,
+			Canonical:   canonical,
+			Alternates:  s.alternates(paths, nil),
+			Type:        "product",
+			JSONLD:      ld,
+		}
+		if len(photos) > 0 {
+			props.Meta.Image = photos[0].URL
+		}
+
+		return listing.Listing(props).Render(ctx, w)
+	}
+}
+
+func (s *Server) handleGetCategory() Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(pathID(r.URL.Path, "/c/"))
+		if err != nil {
+			http.NotFound(w, r)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		// Every language has its own slug, so the category is needed in all
+		// of them for the alternate links.
+		byLang := make(map[string][]yeahapi.Category, len(pageLangs))
+		paths := make(map[string]string, len(pageLangs))
+		for _, pl := range pageLangs {
+			if byLang[pl], err = s.CategoryService.Categories(ctx, pl); err != nil {
+				return err
+			}
+			c := findCategory(byLang[pl], id)
+			if c == nil {
+				http.NotFound(w, r)
+				return nil
+			}
+			paths[pl] = categoryPath(id, c.Title)
+		}
+
+		lang := pageLang(r)
+		if redirectCanonical(w, r, paths[lang]) {
+			return nil
+		}
+
+		var after uuid.UUID
+		query := url.Values{}
+		if a := r.URL.Query().Get("after"); a != "" {
+			if after, err = parseShortID(a); err != nil {
+				http.NotFound(w, r)
+				return nil
+			}
+			query.Set("after", a)
+		}
+
+		page, err := s.ListingService.Listings(ctx, yeahapi.ListingFilter{
+			Statuses:   []yeahapi.ListingStatus{yeahapi.ListingStatusActive},
+			CategoryID: id,
+			Lang:       lang,
+			After:      after,
+			Limit:      categoryPageSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		currencies, err := s.currencies(ctx)
+		if err != nil {
+			return err
+		}
+
+		categories := byLang[lang]
+		category := findCategory(categories, id)
+		props := listing.CategoryProps{
+			Category:    *category,
+			Breadcrumbs: s.breadcrumbs(categories, id, lang),
+			Children:    make([]listing.Link, 0),
+			Listings:    make([]listing.Card, 0, len(page.Listings)),
+			Total:       page.TotalCount,
+			Meta: layout.Meta{
+				Title:       category.Title,
+				Description: summary(category.Description, 160),
+				Lang:        lang,
+				Canonical:   s.pageURL(paths[lang], lang, query),
+				Alternates:  s.alternates(paths, query),
+			},
+		}
+
+		for _, c := range categories {
+			if c.ParentID != nil && *c.ParentID == id {
+				props.Children = append(props.Children, listing.Link{Title: c.Title, URL: s.pageURL(categoryPath(c.ID, c.Title), lang, nil)})
+			}
+		}
+
+		for _, l := range page.Listings {
+			card := listing.Card{
+				Title:   l.Title,
+				URL:     s.pageURL(listingPath(l.ID, l.Title), lang, nil),
+				SoldOut: l.SoldOut,
+			}
+			if l.MinPrice != nil {
+				card.Price = formatPrice(l.MinPrice.Amount, l.MinPrice.Currency, currencies)
+			}
+			props.Listings = append(props.Listings, card)
+		}
+
+		if page.NextCursor != nil {
+			next := url.Values{"after": {shortID(*page.NextCursor)}}
+			props.Next = s.pageURL(paths[lang], lang, next)
+		}
+
+		return listing.Category(props).Render(ctx, w)
+	}
+}
+
+// localize puts the translation of a listing into lang in place of its title
+// and description, the same way ListingService.LocalizedListing does.
+func localize(l yeahapi.Listing, translations []yeahapi.ListingTranslation, lang string) yeahapi.Listing {
+	for _, tr := range translations {
+		if tr.Lang == lang {
+			l.Title, l.Description, l.Lang = tr.Title, tr.Description, tr.Lang
+		}
+	}
+	return l
+}
+
+func findCategory(categories []yeahapi.Category, id int) *yeahapi.Category {
+	for i := range categories {
+		if categories[i].ID == id {
+			return &categories[i]
+		}
+	}
+	return nil
+}
+
+// breadcrumbs links the home page and the category with its ancestors, the
+// root first.
+// mediaURLs fills in where photos and their variants are downloaded from.
+func (s *Server) mediaURLs(media []yeahapi.Media) []yeahapi.Media {
+	for i := range media {
+		media[i].URL = s.BlobStore.URL(media[i].Key)
+		for j, variant := range media[i].Variants {
+			media[i].Variants[j].URL = s.BlobStore.URL(yeahapi.VariantKey(&media[i], variant.Name))
+		}
+	}
+	return media
+}
+
+func (s *Server) breadcrumbs(categories []yeahapi.Category, id int, lang string) []listing.Link {
+	var chain []listing.Link
+	// The depth is bounded so that a cycle can't hang the request.
+	for c := findCategory(categories, id); c != nil && len(chain) < 10; {
+		chain = append(chain, listing.Link{Title: c.Title, URL: s.pageURL(categoryPath(c.ID, c.Title), lang, nil)})
+		if c.ParentID == nil || *c.ParentID == 0 {
+			break
+		}
+		c = findCategory(categories, *c.ParentID)
+	}
+
+	links := []listing.Link{listing.HomeLink(lang, s.pageURL("/", lang, nil))}
+	for i := len(chain) - 1; i >= 0; i-- {
+		links = append(links, chain[i])
+	}
+	return links
+}
+
+func (s *Server) currencies(ctx context.Context) (map[yeahapi.Currency]yeahapi.CurrencyInfo, error) {
+	list, err := s.CurrencyService.Currencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	currencies := make(map[yeahapi.Currency]yeahapi.CurrencyInfo, len(list))
+	for _, c := range list {
+		currencies[c.Code] = c
+	}
+	return currencies, nil
+}
+
+// decimalAmount writes an amount in minor units as a decimal number, the way
+// schema.org prices are written.
+func decimalAmount(amount int, currency yeahapi.CurrencyInfo) string {
+	s := strconv.Itoa(amount)
+	if currency.MinorUnits == 0 {
+		return s
+	}
+
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	if pad := currency.MinorUnits + 1 - len(s); pad > 0 {
+		s = strings.Repeat("0", pad) + s
+	}
+
+	s = s[:len(s)-currency.MinorUnits] + "." + s[len(s)-currency.MinorUnits:]
+	if neg {
+		return "-" + s
+	}
+	return s
+}
+
+// formatPrice groups the whole units of a price by thousands and leaves out
+// a zero fraction, "1 500 000 UZS" or "12.50 $".
+func formatPrice(amount int, code yeahapi.Currency, currencies map[yeahapi.Currency]yeahapi.CurrencyInfo) string {
+	currency := currencies[code]
+	whole, fraction, _ := strings.Cut(decimalAmount(amount, currency), ".")
+	if strings.Trim(fraction, "0") == "" {
+		fraction = ""
+	}
+
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 && whole[i-1] != '-' {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+	if fraction != "" {
+		b.WriteString("." + fraction)
+	}
+
+	return b.String() + " " + fallbackStr(currency.Symbol, string(code))
+}
+
+// attrList writes sku attrs as "key: value", sorted by key.
+func attrList(attrs yeahapi.ListingAttrs) []string {
+	list := make([]string, 0, len(attrs))
+	for key, value := range attrs {
+		list = append(list, fmt.Sprintf("%s: %v", key, value))
+	}
+	sort.Strings(list)
+	return list
+}
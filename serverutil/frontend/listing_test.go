@@ -0,0 +1,94 @@
+package frontend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/disk"
+)
+
+type listingService struct {
+	yeahapi.ListingService
+	listing *yeahapi.Listing
+}
+
+func (s *listingService) Listing(ctx context.Context, id uuid.UUID) (*yeahapi.Listing, error) {
+	l := *s.listing
+	return &l, nil
+}
+
+func (s *listingService) Translations(ctx context.Context, listingID uuid.UUID) ([]yeahapi.ListingTranslation, error) {
+	return nil, nil
+}
+
+func (s *listingService) Skus(ctx context.Context, listingID uuid.UUID) ([]yeahapi.ListingSku, error) {
+	return nil, nil
+}
+
+type mediaService struct {
+	yeahapi.MediaService
+	media []yeahapi.Media
+}
+
+func (s *mediaService) ListingMedia(ctx context.Context, listingID uuid.UUID) ([]yeahapi.Media, error) {
+	return s.media, nil
+}
+
+type categoryService struct {
+	yeahapi.CategoryService
+}
+
+func (s *categoryService) Categories(ctx context.Context, lang string) ([]yeahapi.Category, error) {
+	return nil, nil
+}
+
+type currencyService struct {
+	yeahapi.CurrencyService
+}
+
+func (s *currencyService) Currencies(ctx context.Context) ([]yeahapi.CurrencyInfo, error) {
+	return nil, nil
+}
+
+func TestServer_handleGetListing(t *testing.T) {
+	t.Run("PhotoURLs", func(t *testing.T) {
+		listing := &yeahapi.Listing{ID: uuid.Must(uuid.NewV7()), Title: "Bicycle", Status: yeahapi.ListingStatusActive}
+		media := []yeahapi.Media{{
+			Key:         "listings/bicycle.jpg",
+			ContentType: "image/jpeg",
+			Variants:    []yeahapi.MediaVariant{{Name: "card", Width: 320}},
+		}}
+
+		s := &Server{
+			URL:             "https://needs.uz",
+			ListingService:  &listingService{listing: listing},
+			MediaService:    &mediaService{media: media},
+			CategoryService: &categoryService{},
+			CurrencyService: &currencyService{},
+			BlobStore:       disk.NewBlobStore(t.TempDir(), "https://api.needs.uz/blobs"),
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, listingPath(listing.ID, listing.Title), nil)
+		if err := s.handleGetListing()(w, r); err != nil {
+			t.Fatal(err)
+		}
+
+		body := w.Body.String()
+		for _, want := range []string{
+			`"image":["https://api.needs.uz/blobs/listings/bicycle.jpg"]`,
+			`<meta property="og:image" content="https://api.needs.uz/blobs/listings/bicycle.jpg">`,
+			`src="https://api.needs.uz/blobs/listings/bicycle_card.jpg"`,
+			`srcset="https://api.needs.uz/blobs/listings/bicycle_card.jpg 320w"`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("page is missing %s", want)
+			}
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package frontend
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+	"github.com/yeahuz/yeah-api/serverutil/frontend/templ/layout"
+)
+
+// defaultLang is served when the url has no lang parameter.
+const defaultLang = "en"
+
+var pageLangs = []string{"en", "ru", "uz"}
+
+// maxSlugLen keeps urls of long titles short, slugs are cut at a word.
+const maxSlugLen = 60
+
+const base62 = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+var errShortID = errors.New("invalid short id")
+
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya", 'ў': "o", 'қ': "q", 'ғ': "g", 'ҳ': "h",
+	// Uzbek o' and g' stay whole words.
+	'\'': "", 'ʻ': "", 'ʼ': "", '’': "",
+}
+
+// pageLang is the lang url parameter if it is served, the default language
+// otherwise.
+func pageLang(r *http.Request) string {
+	lang := r.URL.Query().Get("lang")
+	for _, l := range pageLangs {
+		if l == lang {
+			return lang
+		}
+	}
+	return defaultLang
+}
+
+// pageURL is the absolute url of path in lang. Query parameters other than
+// lang are kept in the order of their keys.
+func (s *Server) pageURL(path, lang string, query url.Values) string {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Del("lang")
+	if lang != defaultLang {
+		q.Set("lang", lang)
+	}
+
+	u := strings.TrimSuffix(s.URL, "/") + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	return u
+}
+
+// alternates lists the page in every served language, paths maps a language
+// to the path of the page in it.
+func (s *Server) alternates(paths map[string]string, query url.Values) []layout.Alternate {
+	alts := make([]layout.Alternate, 0, len(pageLangs)+1)
+	for _, lang := range pageLangs {
+		alts = append(alts, layout.Alternate{Lang: lang, URL: s.pageURL(paths[lang], lang, query)})
+	}
+	return append(alts, layout.Alternate{Lang: "x-default", URL: s.pageURL(paths[defaultLang], defaultLang, query)})
+}
+
+// slugify makes a readable url segment of a title. Cyrillic letters are
+// transliterated and anything but latin letters and digits separates words.
+func slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		var s string
+		if t, ok := translit[r]; ok {
+			s = t
+		} else if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			s = string(r)
+		} else {
+			dash = b.Len() > 0
+			continue
+		}
+
+		if s == "" {
+			continue
+		}
+		if dash {
+			b.WriteByte('-')
+			dash = false
+		}
+		b.WriteString(s)
+	}
+
+	slug := b.String()
+	if len(slug) > maxSlugLen {
+		slug = slug[:maxSlugLen]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// shortID encodes a listing id in base62, which keeps it whole in 22
+// characters.
+func shortID(id uuid.UUID) string {
+	return new(big.Int).SetBytes(id.Bytes()).Text(62)
+}
+
+func parseShortID(s string) (uuid.UUID, error) {
+	if s == "" || len(s) > 22 || strings.Trim(s, base62) != "" {
+		return uuid.Nil, errShortID
+	}
+
+	n, ok := new(big.Int).SetString(s, 62)
+	if !ok || n.BitLen() > 128 {
+		return uuid.Nil, errShortID
+	}
+
+	var b [16]byte
+	return uuid.FromBytes(n.FillBytes(b[:]))
+}
+
+func listingPath(id uuid.UUID, title string) string {
+	return "/l/" + slugPrefix(title) + shortID(id)
+}
+
+func categoryPath(id int, title string) string {
+	return "/c/" + slugPrefix(title) + strconv.Itoa(id)
+}
+
+func slugPrefix(title string) string {
+	if slug := slugify(title); slug != "" {
+		return slug + "-"
+	}
+	return ""
+}
+
+// pathID is the id at the end of a slug url, after the last dash.
+func pathID(path, prefix string) string {
+	path = strings.TrimPrefix(path, prefix)
+	return path[strings.LastIndexByte(path, '-')+1:]
+}
+
+// redirectCanonical sends visitors of an outdated slug to the current one.
+func redirectCanonical(w http.ResponseWriter, r *http.Request, path string) bool {
+	if r.URL.Path == path {
+		return false
+	}
+
+	target := path
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+	return true
+}
+
+// summary cuts text to a meta description at a word.
+func summary(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+
+	runes := []rune(text)[:max]
+	if i := strings.LastIndexByte(string(runes), ' '); i > 0 {
+		return string(runes)[:i] + "…"
+	}
+	return string(runes) + "…"
+}
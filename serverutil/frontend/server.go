@@ -24,13 +24,21 @@ type Server struct {
 	server *http.Server
 	ln     net.Listener
 	Addr   string
-
-	ClientID       yeahapi.ClientID
-	AuthService    yeahapi.AuthService
-	ListingService yeahapi.ListingService
-	UserService    yeahapi.UserService
-	CQRSService    yeahapi.CQRSService
-	CookieService  CookieService
+	// URL is where the site is served from, pages link to each other and
+	// to themselves with absolute urls starting with it.
+	URL string
+
+	ClientID        yeahapi.ClientID
+	AuthService     yeahapi.AuthService
+	ListingService  yeahapi.ListingService
+	UserService     yeahapi.UserService
+	CQRSService     yeahapi.CQRSService
+	CookieService   CookieService
+	CategoryService yeahapi.CategoryService
+	MediaService    yeahapi.MediaService
+	BlobStore       yeahapi.BlobStore
+	CurrencyService yeahapi.CurrencyService
+	SitemapService  yeahapi.SitemapService
 }
 
 func NewServer() *Server {
@@ -44,6 +52,8 @@ func NewServer() *Server {
 
 	gob.Register(&yeahapi.Flash{})
 	s.registerAuthRoutes()
+	s.registerListingRoutes()
+	s.registerSitemapRoutes()
 
 	return s
 }
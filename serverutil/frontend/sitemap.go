@@ -0,0 +1,190 @@
+package frontend
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+const (
+	sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	xhtmlNS   = "http://www.w3.org/1999/xhtml"
+)
+
+func (s *Server) registerSitemapRoutes() {
+	s.mux.Handle("/robots.txt", get(s.handleGetRobots()))
+	s.mux.Handle("/sitemap.xml", get(s.handleGetSitemapIndex()))
+	s.mux.Handle("/sitemaps/categories.xml", get(s.handleGetCategorySitemap()))
+	s.mux.Handle("/sitemaps/", get(s.handleGetListingSitemap()))
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	NS       string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlset struct {
+	XMLName xml.Name     `xml:"urlset"`
+	NS      string       `xml:"xmlns,attr"`
+	XHTMLNS string       `xml:"xmlns:xhtml,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string             `xml:"loc"`
+	LastMod    string             `xml:"lastmod,omitempty"`
+	Alternates []sitemapAlternate `xml:"xhtml:link"`
+}
+
+type sitemapAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+func (s *Server) handleGetRobots() Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, err := fmt.Fprintf(w, "User-agent: *\nDisallow: /auth/\n\nSitemap: %s\n", s.pageURL("/sitemap.xml", defaultLang, nil))
+		return err
+	}
+}
+
+// handleGetSitemapIndex lists the category sitemap and a sitemap per page
+// of active listings.
+func (s *Server) handleGetSitemapIndex() Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		pages, err := s.SitemapService.ListingPages(ctx, yeahapi.SitemapPageSize)
+		if err != nil {
+			return err
+		}
+
+		index := sitemapIndex{NS: sitemapNS, Sitemaps: make([]sitemapLoc, 0, len(pages)+1)}
+		index.Sitemaps = append(index.Sitemaps, sitemapLoc{Loc: s.pageURL("/sitemaps/categories.xml", defaultLang, nil)})
+		for _, p := range pages {
+			index.Sitemaps = append(index.Sitemaps, sitemapLoc{
+				Loc:     s.pageURL(fmt.Sprintf("/sitemaps/listings-%s.xml", p.First), defaultLang, nil),
+				LastMod: p.LastModified.UTC().Format(time.RFC3339),
+			})
+		}
+
+		return writeXML(w, index)
+	}
+}
+
+func (s *Server) handleGetCategorySitemap() Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+
+		paths := make(map[int]map[string]string)
+		var ids []int
+		for _, lang := range pageLangs {
+			categories, err := s.CategoryService.Categories(ctx, lang)
+			if err != nil {
+				return err
+			}
+			for _, c := range categories {
+				if paths[c.ID] == nil {
+					paths[c.ID] = make(map[string]string, len(pageLangs))
+					ids = append(ids, c.ID)
+				}
+				paths[c.ID][lang] = categoryPath(c.ID, c.Title)
+			}
+		}
+
+		set := urlset{NS: sitemapNS, XHTMLNS: xhtmlNS, URLs: make([]sitemapURL, 0, len(ids))}
+		for _, id := range ids {
+			set.URLs = append(set.URLs, s.sitemapURL(paths[id], ""))
+		}
+
+		return writeXML(w, set)
+	}
+}
+
+// handleGetListingSitemap serves /sitemaps/listings-{first}.xml, the page
+// starting at the listing first.
+func (s *Server) handleGetListingSitemap() Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		name := strings.TrimPrefix(r.URL.Path, "/sitemaps/")
+		first, err := uuid.FromString(strings.TrimSuffix(strings.TrimPrefix(name, "listings-"), ".xml"))
+		if err != nil || name != fmt.Sprintf("listings-%s.xml", first) {
+			http.NotFound(w, r)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*10)
+		defer cancel()
+
+		var entries []yeahapi.SitemapListing
+		paths := make(map[uuid.UUID]map[string]string)
+		for _, lang := range pageLangs {
+			listings, err := s.SitemapService.Listings(ctx, lang, first, yeahapi.SitemapPageSize)
+			if err != nil {
+				return err
+			}
+			if lang == defaultLang {
+				entries = listings
+			}
+			for _, l := range listings {
+				if paths[l.ID] == nil {
+					paths[l.ID] = make(map[string]string, len(pageLangs))
+				}
+				paths[l.ID][lang] = listingPath(l.ID, l.Title)
+			}
+		}
+
+		if len(entries) == 0 {
+			http.NotFound(w, r)
+			return nil
+		}
+
+		set := urlset{NS: sitemapNS, XHTMLNS: xhtmlNS, URLs: make([]sitemapURL, 0, len(entries))}
+		for _, l := range entries {
+			set.URLs = append(set.URLs, s.sitemapURL(paths[l.ID], l.UpdatedAt.UTC().Format(time.RFC3339)))
+		}
+
+		return writeXML(w, set)
+	}
+}
+
+// sitemapURL is the page in the default language with its alternates. A
+// language the page is missing in, because a listing came or went between
+// queries, falls back to the default one.
+func (s *Server) sitemapURL(paths map[string]string, lastMod string) sitemapURL {
+	for _, lang := range pageLangs {
+		if paths[lang] == "" {
+			paths[lang] = paths[defaultLang]
+		}
+	}
+
+	u := sitemapURL{Loc: s.pageURL(paths[defaultLang], defaultLang, nil), LastMod: lastMod}
+	for _, alt := range s.alternates(paths, nil) {
+		u.Alternates = append(u.Alternates, sitemapAlternate{Rel: "alternate", Hreflang: alt.Lang, Href: alt.URL})
+	}
+	return u
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) error {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,50 @@
+package photo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/a-h/templ"
+	yeahapi "github.com/yeahuz/yeah-api"
+)
+
+type Props struct {
+	Media yeahapi.Media
+	Alt   string
+	Sizes string
+	Class string
+}
+
+// src is the card variant of the photo, or the photo itself until its
+// variants are made.
+func src(media yeahapi.Media) string {
+	for _, variant := range media.Variants {
+		if variant.Name == "card" {
+			return variant.URL
+		}
+	}
+	return media.URL
+}
+
+func srcset(media yeahapi.Media) string {
+	set := make([]string, 0, len(media.Variants))
+	for _, variant := range media.Variants {
+		set = append(set, fmt.Sprintf("%s %dw", variant.URL, variant.Width))
+	}
+	return strings.Join(set, ", ")
+}
+
+// placeholder colors the box of the photo until it loads.
+func placeholder(media yeahapi.Media) templ.CSSClass {
+	color := media.Placeholder
+	if color == "" {
+		color = "#f2f4f7"
+	}
+
+	css := string(templ.SanitizeCSS("background-color", color))
+	id := templ.CSSID("photo", css)
+	return templ.ComponentCSSClass{
+		ID:    id,
+		Class: templ.SafeCSS("." + id + "{" + css + "}"),
+	}
+}
@@ -0,0 +1,79 @@
+package layout
+
+import (
+	"encoding/json"
+
+	"github.com/a-h/templ"
+)
+
+const siteName = "Needs"
+
+// Alternate is the page in another language. Lang "x-default" is the page
+// for visitors whose language isn't served.
+type Alternate struct {
+	Lang string
+	URL  string
+}
+
+// Meta describes a page to search engines and link previews. URLs are
+// absolute.
+type Meta struct {
+	Title       string
+	Description string
+	Lang        string
+	Canonical   string
+	Alternates  []Alternate
+	Image       string
+	// Type is the OpenGraph type of the page, website when empty.
+	Type string
+	// JSONLD is structured data about the page, marshaled as is.
+	JSONLD interface{}
+}
+
+func title(meta Meta) string {
+	if meta.Title == "" {
+		return siteName
+	}
+	return meta.Title + " | " + siteName
+}
+
+func lang(meta Meta) string {
+	if meta.Lang == "" {
+		return "en"
+	}
+	return meta.Lang
+}
+
+func ogType(meta Meta) string {
+	if meta.Type == "" {
+		return "website"
+	}
+	return meta.Type
+}
+
+func ogLocale(meta Meta) string {
+	switch lang(meta) {
+	case "ru":
+		return "ru_RU"
+	case "uz":
+		return "uz_UZ"
+	}
+	return "en_US"
+}
+
+func twitterCard(meta Meta) string {
+	if meta.Image == "" {
+		return "summary"
+	}
+	return "summary_large_image"
+}
+
+// jsonLD renders structured data in a script tag. json.Marshal escapes <, >
+// and &, so the data can't close the tag early.
+func jsonLD(data interface{}) templ.Component {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return templ.Raw("", err)
+	}
+	return templ.Raw(`<script type="application/ld+json">` + string(b) + `</script>`)
+}
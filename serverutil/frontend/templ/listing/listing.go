@@ -0,0 +1,128 @@
+package listing
+
+import (
+	"strconv"
+
+	yeahapi "github.com/yeahuz/yeah-api"
+	"github.com/yeahuz/yeah-api/serverutil/frontend/templ/layout"
+)
+
+type Link struct {
+	Title string
+	URL   string
+}
+
+// Sku is a variant of a listing ready to be shown, Attrs are its attribute
+// values as "key: value".
+type Sku struct {
+	Attrs   []string
+	Price   string
+	InStock bool
+}
+
+type Props struct {
+	Meta        layout.Meta
+	Listing     yeahapi.Listing
+	Breadcrumbs []Link
+	Photos      []yeahapi.Media
+	// Price is the lowest price of the listing, empty when it has no skus.
+	Price string
+	Skus  []Sku
+}
+
+// Card is a listing on a category page.
+type Card struct {
+	Title   string
+	URL     string
+	Price   string
+	SoldOut bool
+}
+
+type CategoryProps struct {
+	Meta        layout.Meta
+	Category    yeahapi.Category
+	Breadcrumbs []Link
+	Children    []Link
+	Listings    []Card
+	Total       int
+	// Next is the url of the next page of listings, if any.
+	Next string
+}
+
+var labels = map[string]map[string]string{
+	"en": {
+		"home":        "Home",
+		"brand":       "Brand",
+		"condition":   "Condition",
+		"NEW":         "New",
+		"USED":        "Used",
+		"REFURBISHED": "Refurbished",
+		"from":        "from",
+		"in_stock":    "In stock",
+		"sold_out":    "Sold out",
+		"listings":    "listings",
+		"next":        "Next page",
+		"empty":       "There are no listings here yet",
+	},
+	"ru": {
+		"home":        "Главная",
+		"brand":       "Бренд",
+		"condition":   "Состояние",
+		"NEW":         "Новое",
+		"USED":        "Б/у",
+		"REFURBISHED": "Восстановленное",
+		"from":        "от",
+		"in_stock":    "В наличии",
+		"sold_out":    "Нет в наличии",
+		"listings":    "объявлений",
+		"next":        "Следующая страница",
+		"empty":       "Здесь пока нет объявлений",
+	},
+	"uz": {
+		"home":        "Bosh sahifa",
+		"brand":       "Brend",
+		"condition":   "Holati",
+		"NEW":         "Yangi",
+		"USED":        "Ishlatilgan",
+		"REFURBISHED": "Qayta tiklangan",
+		"from":        "dan",
+		"in_stock":    "Sotuvda bor",
+		"sold_out":    "Sotuvda yo'q",
+		"listings":    "e'lon",
+		"next":        "Keyingi sahifa",
+		"empty":       "Bu yerda hali e'lonlar yo'q",
+	},
+}
+
+// t is the label in the language of the page.
+func t(meta layout.Meta, key string) string {
+	if l, ok := labels[meta.Lang][key]; ok {
+		return l
+	}
+	return labels["en"][key]
+}
+
+// HomeLink starts the breadcrumbs of a page in lang.
+func HomeLink(lang, url string) Link {
+	return Link{Title: t(layout.Meta{Lang: lang}, "home"), URL: url}
+}
+
+// fromPrice puts the word "from" where the language of the page wants it.
+func fromPrice(meta layout.Meta, price string) string {
+	if meta.Lang == "uz" {
+		return price + " " + t(meta, "from")
+	}
+	return t(meta, "from") + " " + price
+}
+
+func countLabel(meta layout.Meta, count int) string {
+	return strconv.Itoa(count) + " " + t(meta, "listings")
+}
+
+// photoClass makes the first photo of a listing span the whole row.
+func photoClass(i int) string {
+	if i == 0 {
+		return "col-span-2 w-full aspect-square rounded-lg"
+	}
+	return "w-full aspect-square rounded-lg"
+}
@@ -0,0 +1,49 @@
+package yeahapi
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+)
+
+// MaxSimilarListings is how many similar listings can be asked for at once.
+const MaxSimilarListings = 50
+
+// MaxCategoryDistance is how many steps apart in the category tree a listing
+// may be from the one it is similar to, unless visitors viewed both. Siblings
+// are two steps apart.
+const MaxCategoryDistance = 2
+
+// SimilarWeights weigh the signals listings are found similar by, each of
+// which scores from 0 to 1. A zero weight leaves the signal out.
+type SimilarWeights struct {
+	// Category scores listings closer in the category tree higher.
+	Category float64
+	// Attrs is the share of the attribute values of the listing's skus that
+	// the other listing's skus have too.
+	Attrs float64
+	// Price scores listings priced alike higher, down to zero at half or
+	// double the price.
+	Price float64
+	// Title is the trigram similarity of the titles.
+	Title float64
+	// CoViews scores listings by how many visitors viewed both. Views are
+	// only remembered for a couple of days.
+	CoViews float64
+}
+
+// DefaultSimilarWeights leave co-views out, they are only worth it once
+// enough visitors come by.
+var DefaultSimilarWeights = SimilarWeights{Category: 1, Attrs: 1, Price: 1, Title: 2}
+
+type SimilarListing struct {
+	Listing
+	Score float64 `json:"score"`
+}
+
+type RecommendationService interface {
+	// SimilarListings returns active listings similar to a listing, the most
+	// similar first, translated into lang where the seller provided a
+	// translation.
+	SimilarListings(ctx context.Context, listingID uuid.UUID, lang string, limit int) ([]SimilarListing, error)
+}
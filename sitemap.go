@@ -0,0 +1,36 @@
+package yeahapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// SitemapPageSize is how many listings a sitemap holds, well under the 50000
+// urls the sitemap protocol allows.
+const SitemapPageSize = 10000
+
+// SitemapPage is a page of the listing sitemaps, starting at the listing
+// First. Pages are keyed by their first listing instead of numbered, so that
+// they're found without counting the listings before them.
+type SitemapPage struct {
+	First        uuid.UUID
+	LastModified time.Time
+}
+
+// SitemapListing is an active listing, titled in the language asked for
+// where the seller translated it.
+type SitemapListing struct {
+	ID        uuid.UUID
+	Title     string
+	UpdatedAt time.Time
+}
+
+type SitemapService interface {
+	// ListingPages splits active listings, oldest first, into pages of size
+	// listings.
+	ListingPages(ctx context.Context, size int) ([]SitemapPage, error)
+	// Listings returns up to size listings starting at first.
+	Listings(ctx context.Context, lang string, first uuid.UUID, size int) ([]SitemapListing, error)
+}
@@ -0,0 +1,84 @@
+package yeahapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// MaxStatsDays is how many days of listing stats can be asked for at once.
+const MaxStatsDays = 90
+
+type HitKind string
+
+const (
+	// HitImpression is a listing shown in search results or lists.
+	HitImpression HitKind = "IMPRESSION"
+	// HitView is a listing opened on its own.
+	HitView HitKind = "VIEW"
+	// HitContactReveal is the contact of a seller shown for a listing.
+	HitContactReveal HitKind = "CONTACT_REVEAL"
+)
+
+// ListingHit is a visitor seeing a listing. Hits of the same visitor, listing
+// and kind are counted once per day, in UTC.
+type ListingHit struct {
+	ListingID uuid.UUID
+	Kind      HitKind
+	VisitorID string
+	Day       time.Time
+}
+
+func NewListingHit(listingID uuid.UUID, kind HitKind, visitorID string, at time.Time) ListingHit {
+	return ListingHit{
+		ListingID: listingID,
+		Kind:      kind,
+		VisitorID: visitorID,
+		Day:       at.UTC().Truncate(24 * time.Hour),
+	}
+}
+
+// ListingStats are the counts of a listing for a day. Favorites are the
+// favorites added that day and still kept.
+type ListingStats struct {
+	Day            time.Time `json:"day"`
+	Impressions    int       `json:"impressions"`
+	Views          int       `json:"views"`
+	Favorites      int       `json:"favorites"`
+	ContactReveals int       `json:"contact_reveals"`
+}
+
+// StatsFilter asks for the stats of a listing on every day from From to To,
+// both included.
+type StatsFilter struct {
+	ListingID uuid.UUID
+	From      time.Time
+	To        time.Time
+}
+
+func (f StatsFilter) Ok() error {
+	if f.ListingID.IsNil() {
+		return E(EInvalid, "Listing id is required")
+	} else if f.To.Before(f.From) {
+		return E(EInvalid, "Stats can't end before they start")
+	} else if f.To.Sub(f.From) >= MaxStatsDays*24*time.Hour {
+		return E(EInvalid, fmt.Sprintf("Stats can span up to %d days", MaxStatsDays))
+	}
+	return nil
+}
+
+type StatsService interface {
+	// RecordHits counts the hits that weren't counted for the same visitor,
+	// listing, kind and day before.
+	RecordHits(ctx context.Context, hits []ListingHit) error
+	// ListingStats returns stats for every day of the filter, days without
+	// any counts included.
+	ListingStats(ctx context.Context, filter StatsFilter) ([]ListingStats, error)
+}
+
+// HitRecorder takes hits without waiting for them to be stored.
+type HitRecorder interface {
+	RecordHit(hit ListingHit)
+}
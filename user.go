@@ -40,6 +40,7 @@ type UserService interface {
 	User(ctx context.Context, id UserID) (*User, error)
 	Account(ctx context.Context, id uuid.UUID) (*Account, error)
 	LinkAccount(ctx context.Context, account *Account) error
+	IsAdmin(ctx context.Context, id UserID) (bool, error)
 }
 
 func (a *Account) Ok() error {
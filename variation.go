@@ -0,0 +1,111 @@
+package yeahapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxVariations caps how many skus a single variation matrix may produce.
+const MaxVariations = 100
+
+// Variations builds every combination of the chosen options of variation
+// attributes. Each combination is merged with common attrs and validated
+// against the category attributes, so options may be given by id or value.
+func Variations(schema []*CategoryAttribute, common ListingAttrs, options map[string][]interface{}) ([]ListingAttrs, error) {
+	fields := make(Fields)
+	enabled := make(map[string]bool, len(schema))
+	for _, attr := range schema {
+		enabled[attr.Key] = attr.EnabledForVariations
+	}
+
+	total := 1
+	for key, values := range options {
+		if !enabled[key] {
+			fields["options."+key] = "This attribute can't be used for variations"
+		} else if len(values) == 0 {
+			fields["options."+key] = "Choose at least one option"
+		} else if _, ok := common[key]; ok {
+			fields["options."+key] = "This attribute is already set for every variation"
+		}
+		total *= len(values)
+	}
+
+	if len(fields) > 0 {
+		return nil, E(EInvalid, fields, "Some options are invalid")
+	}
+
+	if len(options) == 0 {
+		return nil, E(EInvalid, "Choose options to generate variations from")
+	} else if total > MaxVariations {
+		return nil, E(EInvalid, fmt.Sprintf("Too many variations. At most %d can be generated at once", MaxVariations))
+	}
+
+	keys := VariationKeys(options)
+	combinations := []ListingAttrs{{}}
+	for _, key := range keys {
+		next := make([]ListingAttrs, 0, len(combinations)*len(options[key]))
+		for _, combination := range combinations {
+			for _, value := range options[key] {
+				attrs := make(ListingAttrs, len(combination)+1)
+				for k, v := range combination {
+					attrs[k] = v
+				}
+				attrs[key] = value
+				next = append(next, attrs)
+			}
+		}
+		combinations = next
+	}
+
+	seen := make(map[string]bool, len(combinations))
+	variations := make([]ListingAttrs, 0, len(combinations))
+	for _, combination := range combinations {
+		for k, v := range common {
+			combination[k] = v
+		}
+
+		attrs, err := ValidateAttrs(combination, schema)
+		if err != nil {
+			return nil, err
+		}
+
+		// The same option may have been chosen both by id and by value.
+		if key := VariationKey(attrs, keys); !seen[key] {
+			seen[key] = true
+			variations = append(variations, attrs)
+		}
+	}
+
+	return variations, nil
+}
+
+// VariationKeys returns the attribute keys of options in a stable order.
+func VariationKeys(options map[string][]interface{}) []string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// VariationKey identifies a variation by its values of the variation
+// attributes. Skus with equal keys are the same variation.
+func VariationKey(attrs ListingAttrs, keys []string) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s=%v", key, attrs[key])
+	}
+	return strings.Join(parts, "&")
+}
+
+// VariationSku makes a default custom sku out of the variation values, e.g.
+// BLACK-128 for a black phone with 128 GB of storage.
+func VariationSku(attrs ListingAttrs, keys []string) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = strings.ToUpper(strings.Join(strings.Fields(fmt.Sprint(attrs[key])), ""))
+	}
+	return strings.Join(parts, "-")
+}